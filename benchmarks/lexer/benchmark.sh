@@ -0,0 +1,9 @@
+#!/usr/bin/env bash
+
+set -e
+
+cd ../../tests/
+
+# the regex lexer takes minutes on the synthetic 100k line file, so it only runs once
+go test -run '^$' -bench 'LexerCorpus' -benchtime 20x -benchmem | tee ../benchmarks/lexer/results.txt
+go test -run '^$' -bench 'LexerSynthetic' -benchtime 1x -benchmem | tee -a ../benchmarks/lexer/results.txt
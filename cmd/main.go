@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/sanity-io/litter"
 
-	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	kolon "github.com/KhushPatibandha/Kolon"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/format"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
 	"github.com/KhushPatibandha/Kolon/src/parser"
+	"github.com/KhushPatibandha/Kolon/src/repl"
 )
 
 func main() {
@@ -23,7 +32,9 @@ func main() {
 		fmt.Println()
 
 		fmt.Println(`Available Commands:
-    'run: <file.kol>'                             Run a kolon file
+    'run: <file.kol> [--vm] [limits]'             Run a kolon file
+    'fmt: <file.kol | dir> [--check | --write]'   Format kolon files, printing them unless a flag is given
+    'repl'                                        Start an interactive session
     'debug: <file.kol> [--tokens | --ast]'        Debug a kolon file`)
 
 		fmt.Println()
@@ -31,13 +42,46 @@ func main() {
 		fmt.Println(`Flags:
     -h, --help        help for kolon
     -v, --version     show version information
+    --vm              compile the file to bytecode and run it on the vm [Command: 'run:']
+    --timeout <d>     stop the program after the duration d, eg: 500ms or 2s [Command: 'run:']
+    --max-steps <n>   stop the program after n steps [Command: 'run:']
+    --max-depth <n>   stop the program when n function calls are running (default 10000) [Command: 'run:']
+    --max-alloc <n>   stop the program when an array, hashmap or string gets more than n elements [Command: 'run:']
+    --check           list the files that aren't formatted and exit with 1 if there are any [Command: 'fmt:']
+    --write           write the formatted files back [Command: 'fmt:']
     --tokens          print tokens of the file [Command: 'debug:']
     --ast             print ast of the file [Command: 'debug:']`)
 
+		fmt.Println()
+
+		fmt.Println(`Environment Variables:
+    KOLON_PATH        directories to look for imported files in, after the directory of the importing file`)
+
 		return
-	} else if len(os.Args) == 3 && os.Args[1] == "run:" {
-		filePath := os.Args[2]
-		if filePath[len(filePath)-4:] != ".kol" {
+	} else if len(os.Args) == 2 && (os.Args[1] == "repl" || os.Args[1] == "repl:") {
+		fmt.Println("Kolon v1.2.0, press Ctrl+D to exit")
+		repl.Start(os.Stdin, os.Stdout)
+		return
+	} else if len(os.Args) >= 3 && os.Args[1] == "run:" {
+		flags := flag.NewFlagSet("run:", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		useVM := flags.Bool("vm", false, "")
+		timeout := flags.Duration("timeout", 0, "")
+		var limits kolon.Limits
+		flags.Int64Var(&limits.MaxSteps, "max-steps", 0, "")
+		flags.IntVar(&limits.MaxDepth, "max-depth", 0, "")
+		flags.IntVar(&limits.MaxAlloc, "max-alloc", 0, "")
+		// the flags can come before or after the file
+		if err := flags.Parse(os.Args[2:]); err != nil || flags.NArg() == 0 {
+			fmt.Println("Not a valid command, use `--help` or `-h` for more information")
+			return
+		}
+		filePath := flags.Arg(0)
+		if err := flags.Parse(flags.Args()[1:]); err != nil || flags.NArg() != 0 {
+			fmt.Println("Not a valid command, use `--help` or `-h` for more information")
+			return
+		}
+		if !strings.HasSuffix(filePath, ".kol") {
 			fmt.Println("Error: File should have .kol extension")
 			return
 		}
@@ -46,20 +90,81 @@ func main() {
 			fmt.Println("Error reading file:", err)
 			return
 		}
-		tokens := lexer.Tokenizer(string(bytes))
-
-		p := parser.New(tokens, false)
-		program, err := p.ParseProgram()
-		if err != nil {
-			fmt.Println("Error parsing program:", err)
-			return
+		program, diags := kolon.CompileSource(filePath, string(bytes))
+		if diags != nil {
+			for _, d := range diags {
+				fmt.Println("Error parsing program:", d)
+			}
+			os.Exit(1)
 		}
-		e := evaluator.New(false)
-		_, err = e.Evaluate(program)
-		if err != nil {
+		ctx := context.Background()
+		if *timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, *timeout)
+			defer cancel()
+		}
+		_, err = program.Run(ctx, kolon.Options{VM: *useVM, Limits: limits})
+		var compileErr *kolon.CompileError
+		var runtimeErr *kolon.RuntimeError
+		if errors.As(err, &compileErr) {
+			fmt.Println("Error compiling program:", compileErr)
+			os.Exit(1)
+		} else if err != nil {
 			fmt.Println("Error evaluating program:", err)
+			if errors.As(err, &runtimeErr) {
+				fmt.Println(runtimeErr.Traceback())
+			}
+			os.Exit(1)
+		}
+		return
+	} else if len(os.Args) >= 3 && os.Args[1] == "fmt:" {
+		flags := flag.NewFlagSet("fmt:", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		check := flags.Bool("check", false, "")
+		write := flags.Bool("write", false, "")
+		if err := flags.Parse(os.Args[3:]); err != nil || flags.NArg() != 0 || *check && *write {
+			fmt.Println("Not a valid command, use `--help` or `-h` for more information")
 			return
 		}
+		files, err := kolFiles(os.Args[2])
+		if err != nil {
+			fmt.Println("Error reading file:", err)
+			os.Exit(1)
+		}
+		failed := false
+		for _, filePath := range files {
+			bytes, err := os.ReadFile(filePath)
+			if err != nil {
+				fmt.Println("Error reading file:", err)
+				failed = true
+				continue
+			}
+			formatted, err := format.Source(string(bytes))
+			if err != nil {
+				fmt.Println("Error formatting file:", diagnostic.WithFile(err, filePath))
+				failed = true
+				continue
+			}
+			switch {
+			case *check:
+				if formatted != string(bytes) {
+					fmt.Println(filePath)
+					failed = true
+				}
+			case *write:
+				if formatted != string(bytes) {
+					if err := os.WriteFile(filePath, []byte(formatted), 0o644); err != nil {
+						fmt.Println("Error writing file:", err)
+						failed = true
+					}
+				}
+			default:
+				fmt.Print(formatted)
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
 		return
 	} else if len(os.Args) == 4 && os.Args[1] == "debug:" && (os.Args[3] == "--tokens" || os.Args[3] == "--ast") {
 		filePath := os.Args[2]
@@ -72,17 +177,24 @@ func main() {
 			fmt.Println("Error reading file:", err)
 			return
 		}
-		tokens := lexer.Tokenizer(string(bytes))
+		tokens, err := lexer.Tokenizer(string(bytes))
+		if err != nil {
+			fmt.Println("Error parsing program:", diagnostic.WithFile(err, filePath))
+			os.Exit(1)
+		}
 		if os.Args[3] == "--tokens" {
 			for _, token := range tokens {
 				token.Help()
 			}
 		} else {
 			p := parser.New(tokens, false)
+			p.SetFile(filePath)
 			program, err := p.ParseProgram()
 			if err != nil {
-				fmt.Println("Error parsing program:", err)
-				return
+				for _, err := range diagnostic.Errors(diagnostic.WithFile(err, filePath)) {
+					fmt.Println("Error parsing program:", err)
+				}
+				os.Exit(1)
 			}
 			litter.Dump(program)
 		}
@@ -92,3 +204,25 @@ func main() {
 		return
 	}
 }
+
+// kolFiles returns path if it's a file, or the .kol files in it and the directories under it if it's a directory
+func kolFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		if !strings.HasSuffix(path, ".kol") {
+			return nil, errors.New("file should have .kol extension: " + path)
+		}
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(p, ".kol") {
+			files = append(files, p)
+		}
+		return err
+	})
+	return files, err
+}
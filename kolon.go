@@ -0,0 +1,334 @@
+// Package kolon runs Kolon programs from Go, the way `kolon run:` does
+//
+//	program, diags := kolon.Compile(source)
+//	if diags != nil {
+//		...
+//	}
+//	results, err := program.Run(ctx, kolon.Options{Stdout: &out, Entry: "add", Args: []any{1, 2}})
+package kolon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/builtins"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/compiler"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/vm"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+	"github.com/KhushPatibandha/Kolon/src/parser"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Diagnostic: an error in a program and where it is, File is empty for the source given to Compile and Line is 0
+// for an error that isn't at a place in the source
+// ------------------------------------------------------------------------------------------------------------------
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Err     error // the error at the place, a *RuntimeError for an error the program ran into while running
+}
+
+// Error formats d like the kolon command prints it, eg: main.kol:12:9: identifier not found: x
+func (d Diagnostic) Error() string {
+	if d.Line == 0 {
+		return d.Message
+	}
+	pos := lexer.Position{Line: d.Line, Column: d.Column}.String()
+	if d.File == "" {
+		return pos + ": " + d.Message
+	}
+	return d.File + ":" + pos + ": " + d.Message
+}
+
+func (d Diagnostic) Unwrap() error { return d.Err }
+
+// diagnostics splits err into the diagnostics it is made of, naming file as the one the errors without a file
+// came from
+func diagnostics(err error, file string) []Diagnostic {
+	var out []Diagnostic
+	for _, err := range diagnostic.Errors(diagnostic.WithFile(err, file)) {
+		out = append(out, toDiagnostic(err))
+	}
+	return out
+}
+
+func toDiagnostic(err error) Diagnostic {
+	var d *diagnostic.Diagnostic
+	if errors.As(err, &d) {
+		return Diagnostic{File: d.File, Line: d.Pos.Line, Column: d.Pos.Column, Message: d.Msg, Err: d.Err}
+	}
+	return Diagnostic{Message: err.Error()}
+}
+
+// CompileError is returned by Run when the program can't be compiled for the vm, the evaluator can still run it
+type CompileError struct {
+	Diagnostic
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Program: a parsed and type checked program, it can be run any number of times
+// ------------------------------------------------------------------------------------------------------------------
+type Program struct {
+	file     string
+	program  *ast.Program
+	builtins *builtins.Registry
+}
+
+// Compile parses and type checks source, the imports in it are looked up relative to the working directory. funcs
+// are the Go functions it can call besides the builtins
+func Compile(source string, funcs ...Func) (*Program, []Diagnostic) {
+	return CompileSource("", source, funcs...)
+}
+
+// CompileSource compiles source read from file, its imports are looked up next to it and the errors in it name it
+func CompileSource(file string, source string, funcs ...Func) (*Program, []Diagnostic) {
+	reg, err := registry(funcs)
+	if err != nil {
+		return nil, []Diagnostic{{Message: err.Error()}}
+	}
+	tokens, err := lexer.Tokenizer(source)
+	if err != nil {
+		return nil, diagnostics(err, file)
+	}
+	p := parser.New(tokens, false)
+	p.SetBuiltins(reg)
+	if file != "" {
+		p.SetFile(file)
+	}
+	program, err := p.ParseProgram()
+	if err != nil {
+		return nil, diagnostics(err, file)
+	}
+	return &Program{file: file, program: program, builtins: reg}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Func: a Go function programs can call like a builtin, the calls to it are type checked against its types
+// ------------------------------------------------------------------------------------------------------------------
+type Func struct {
+	Name string
+
+	// the Kolon types of the parameters and of the return values, eg: "int", "string[]" or "int[string]".
+	// Variadic is the type of any number of arguments after Params, empty if it takes exactly Params
+	Params   []string
+	Variadic string
+	Returns  []string
+
+	// Fn gets the arguments as the Go values Run gives back and returns a value for each of Returns, which are
+	// converted like the Args of Options
+	Fn func(args []any) ([]any, error)
+}
+
+// registry returns the standard builtins with funcs added to them
+func registry(funcs []Func) (*builtins.Registry, error) {
+	reg := builtins.New()
+	for _, f := range funcs {
+		b, err := f.builtin()
+		if err == nil {
+			err = reg.Register(b)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("func `%s`: %w", f.Name, err)
+		}
+	}
+	return reg, nil
+}
+
+func (f Func) builtin() (*builtins.Builtin, error) {
+	params, err := parseTypes(f.Params)
+	if err != nil {
+		return nil, err
+	}
+	returns, err := parseTypes(f.Returns)
+	if err != nil {
+		return nil, err
+	}
+	sig := builtins.Sig(params, returns...)
+	if f.Variadic != "" {
+		variadic, err := parseTypes([]string{f.Variadic})
+		if err != nil {
+			return nil, err
+		}
+		sig = builtins.VariadicSig(params, variadic[0], returns...)
+	}
+	if f.Fn == nil {
+		return nil, errors.New("Fn is nil")
+	}
+
+	call := func(_ *builtins.IO, args []object.Object) (object.Object, error) {
+		goArgs, err := toGoList(args)
+		if err != nil {
+			return nil, err
+		}
+		out, err := f.Fn(goArgs)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) != len(returns) {
+			return nil, fmt.Errorf("`%s` returned %d value(s), want: %d", f.Name, len(out), len(returns))
+		}
+		objs := make([]object.Object, len(out))
+		for i, v := range out {
+			if objs[i], err = toObject(reflect.ValueOf(v), returns[i]); err != nil {
+				return nil, fmt.Errorf("return value %d of `%s`: %w", i+1, f.Name, err)
+			}
+		}
+		switch len(objs) {
+		case 0:
+			return nil, nil
+		case 1:
+			return objs[0], nil
+		}
+		return &object.Array{Elements: objs}, nil
+	}
+	return &builtins.Builtin{Name: f.Name, Signatures: []*builtins.Signature{sig}, Fn: call}, nil
+}
+
+func parseTypes(types []string) ([]*ktype.Type, error) {
+	out := make([]*ktype.Type, len(types))
+	for i, src := range types {
+		t, err := parser.ParseType(src)
+		if err != nil {
+			return nil, fmt.Errorf("type `%s`: %s", src, toDiagnostic(err).Message)
+		}
+		out[i] = t
+	}
+	return out, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Options: how a program is run
+// ------------------------------------------------------------------------------------------------------------------
+type Options struct {
+	Stdout io.Writer // where `print` writes to, the standard output if nil
+	Stdin  io.Reader // where `scan` reads from, the standard input if nil
+
+	// Entry is the function to run instead of `main`, with Args as its arguments. they're converted to the types
+	// of its parameters, eg: an int or an int64 for an `int`, a slice for an array and a map for a hashmap
+	Entry string
+	Args  []any
+
+	VM bool // compile the program to bytecode and run it on the vm, instead of the evaluator
+
+	// Limits stop a program that runs for too long, recurses too deep or makes values too big, the context given
+	// to Run stops it too. both give back a *LimitError
+	Limits Limits
+}
+
+// Limits is how far a program can go, a MaxSteps or MaxAlloc of 0 is no limit and a MaxDepth of 0 is
+// evaluator.DefaultMaxDepth
+type Limits = evaluator.Limits
+
+// LimitError is the error of a program stopped by its limits or its context
+type LimitError = evaluator.LimitError
+
+// RuntimeError is an error a program ran into while running, with the functions that were running. Traceback
+// formats them like `kolon run:` prints them
+type RuntimeError = evaluator.RuntimeError
+
+// Frame is a function that was running when a program ran into an error
+type Frame = evaluator.Frame
+
+// Run runs p and gives back what the entry function returned, one Go value for each of its return types: int64,
+// float64, bool, string and rune for the base types, []any for an array, map[any]any for a hashmap,
+// map[string]any for a struct and a Variant for an enum. running `main` gives nothing back
+func (p *Program) Run(ctx context.Context, opts Options) ([]any, error) {
+	// a program whose context is already done is stopped before it starts
+	if err := ctx.Err(); err != nil {
+		return nil, &LimitError{Kind: evaluator.Stopped, Err: err}
+	}
+	out, in := opts.Stdout, opts.Stdin
+	if out == nil {
+		out = os.Stdout
+	}
+	if in == nil {
+		in = os.Stdin
+	}
+
+	var entry *ast.Function
+	var args []object.Object
+	if opts.Entry != "" {
+		entry = p.function(opts.Entry)
+		if entry == nil {
+			return nil, fmt.Errorf("function `%s` not found", opts.Entry)
+		}
+		var err error
+		if args, err = toArgs(entry, opts.Args); err != nil {
+			return nil, err
+		}
+	}
+
+	var r object.Object
+	var err error
+	if opts.VM {
+		bytecode, cErr := compiler.New().Compile(p.program)
+		if cErr != nil {
+			return nil, &CompileError{toDiagnostic(diagnostic.WithFile(cErr, p.file))}
+		}
+		m := vm.New(bytecode)
+		m.SetIO(out, in)
+		m.SetBuiltins(p.builtins)
+		m.SetLimits(ctx, opts.Limits)
+		if entry == nil {
+			err = m.Run()
+		} else {
+			r, err = m.Call(opts.Entry, args)
+		}
+	} else {
+		e := evaluator.New(false)
+		e.SetIO(out, in)
+		e.SetBuiltins(p.builtins)
+		e.SetLimits(ctx, opts.Limits)
+		if entry != nil {
+			e.SkipMain()
+		}
+		_, err = e.Evaluate(p.program)
+		if err == nil && entry != nil {
+			r, err = e.Call(opts.Entry, args)
+		}
+	}
+	if err != nil {
+		return nil, p.runtimeError(err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	return results(r, len(entry.ReturnTypes))
+}
+
+// function returns the function name declared with a body at the top of p, nil if there is none
+func (p *Program) function(name string) *ast.Function {
+	for _, stmt := range p.program.Statements {
+		if fn, ok := stmt.(*ast.Function); ok && fn.Name.Value == name && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// runtimeError turns err, which the program ran into, into a Diagnostic that keeps the *RuntimeError, the frames
+// of the functions declared in p are given its file
+func (p *Program) runtimeError(err error) error {
+	d := toDiagnostic(diagnostic.WithFile(err, p.file))
+	var r *RuntimeError
+	if errors.As(err, &r) {
+		for i := range r.Stack {
+			if r.Stack[i].File == "" {
+				r.Stack[i].File = p.file
+			}
+		}
+		d.Err = r
+	}
+	return d
+}
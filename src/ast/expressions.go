@@ -16,10 +16,26 @@ import (
 // ------------------------------------------------------------------------------------------------------------------
 // Identifier
 // ------------------------------------------------------------------------------------------------------------------
+// Identifier is a name, for a variable the resolver sets Depth to how many scopes out of where it's used the
+// variable was declared and Index to its slot in that scope. Depth is Global for a global or a function, those are
+// looked up by name. the parser creates every identifier with Index set to Unresolved
 type Identifier struct {
 	Token lexer.Token
 	Value string
 	Type  *ktype.Type
+	Depth int
+	Index int
+}
+
+const (
+	Global     = -2 // Depth of a global or a function
+	Unresolved = -1 // Index of an identifier the resolver hasn't annotated
+)
+
+// UnresolvedError is the error for an identifier still at Unresolved when it's run, a program with one wasn't
+// checked and its Depth and Index would read another variable
+func UnresolvedError(i *Identifier) error {
+	return fmt.Errorf("internal error: identifier `%s` was never resolved", i.Value)
 }
 
 func (i *Identifier) expressionNode() {}
@@ -29,8 +45,9 @@ func (i *Identifier) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (i *Identifier) TokenValue() string { return i.Token.Value }
-func (i *Identifier) String() string     { return i.TokenValue() }
+func (i *Identifier) TokenValue() string  { return i.Token.Value }
+func (i *Identifier) Pos() lexer.Position { return i.Token.Start }
+func (i *Identifier) String() string      { return i.TokenValue() }
 func (i *Identifier) Equals(other *Identifier) bool {
 	return i.Value == other.Value
 }
@@ -51,9 +68,10 @@ func (i *Integer) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (i *Integer) baseTypeNode()      {}
-func (i *Integer) TokenValue() string { return i.Token.Value }
-func (i *Integer) String() string     { return i.TokenValue() }
+func (i *Integer) baseTypeNode()       {}
+func (i *Integer) TokenValue() string  { return i.Token.Value }
+func (i *Integer) Pos() lexer.Position { return i.Token.Start }
+func (i *Integer) String() string      { return i.TokenValue() }
 
 // ------------------------------------------------------------------------------------------------------------------
 // Float
@@ -71,9 +89,10 @@ func (f *Float) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (f *Float) baseTypeNode()      {}
-func (f *Float) TokenValue() string { return fmt.Sprintf("%g", f.Value) }
-func (f *Float) String() string     { return f.Token.Value }
+func (f *Float) baseTypeNode()       {}
+func (f *Float) TokenValue() string  { return fmt.Sprintf("%g", f.Value) }
+func (f *Float) Pos() lexer.Position { return f.Token.Start }
+func (f *Float) String() string      { return f.Token.Value }
 
 // ------------------------------------------------------------------------------------------------------------------
 // Bool
@@ -91,9 +110,10 @@ func (b *Bool) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (b *Bool) baseTypeNode()      {}
-func (b *Bool) TokenValue() string { return b.Token.Value }
-func (b *Bool) String() string     { return b.TokenValue() }
+func (b *Bool) baseTypeNode()       {}
+func (b *Bool) TokenValue() string  { return b.Token.Value }
+func (b *Bool) Pos() lexer.Position { return b.Token.Start }
+func (b *Bool) String() string      { return b.TokenValue() }
 
 // ------------------------------------------------------------------------------------------------------------------
 // String
@@ -111,9 +131,44 @@ func (s *String) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (s *String) baseTypeNode()      {}
-func (s *String) TokenValue() string { return s.Token.Value }
-func (s *String) String() string     { return s.TokenValue() }
+func (s *String) baseTypeNode()       {}
+func (s *String) TokenValue() string  { return s.Token.Value }
+func (s *String) Pos() lexer.Position { return s.Token.Start }
+func (s *String) String() string      { return s.TokenValue() }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Interpolated String: A string with expressions embedded in it, eg: "hello ${name}!"
+// Parts are the (decoded) pieces of text around the expressions, there is always one more part than expressions
+// ------------------------------------------------------------------------------------------------------------------
+type InterpolatedString struct {
+	Token       lexer.Token
+	Parts       []string
+	Expressions []Expression
+	Type        *ktype.Type
+}
+
+func (is *InterpolatedString) expressionNode() {}
+func (is *InterpolatedString) GetType() *ktype.TypeCheckResult {
+	return &ktype.TypeCheckResult{
+		Types:   []*ktype.Type{ktype.InternType(is.Type)},
+		TypeLen: 1,
+	}
+}
+func (is *InterpolatedString) TokenValue() string  { return is.Token.Value }
+func (is *InterpolatedString) Pos() lexer.Position { return is.Token.Start }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+	out.WriteString("\"")
+	for i, part := range is.Parts {
+		quoted := lexer.Quote(part, '"')
+		out.WriteString(quoted[1 : len(quoted)-1])
+		if i < len(is.Expressions) {
+			out.WriteString("${" + is.Expressions[i].String() + "}")
+		}
+	}
+	out.WriteString("\"")
+	return out.String()
+}
 
 // ------------------------------------------------------------------------------------------------------------------
 // Char
@@ -131,9 +186,10 @@ func (c *Char) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (c *Char) baseTypeNode()      {}
-func (c *Char) TokenValue() string { return c.Token.Value }
-func (c *Char) String() string     { return c.TokenValue() }
+func (c *Char) baseTypeNode()       {}
+func (c *Char) TokenValue() string  { return c.Token.Value }
+func (c *Char) Pos() lexer.Position { return c.Token.Start }
+func (c *Char) String() string      { return c.TokenValue() }
 
 // ------------------------------------------------------------------------------------------------------------------
 // HashMap
@@ -142,7 +198,12 @@ type HashMap struct {
 	Token     lexer.Token
 	KeyType   *ktype.Type
 	ValueType *ktype.Type
-	Pairs     map[BaseType]Expression
+	Pairs     []*HashMapPair // in the order they are written
+}
+
+type HashMapPair struct {
+	Key   BaseType
+	Value Expression
 }
 
 func (hm *HashMap) expressionNode() {}
@@ -158,12 +219,13 @@ func (hm *HashMap) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (hm *HashMap) TokenValue() string { return hm.Token.Value }
+func (hm *HashMap) TokenValue() string  { return hm.Token.Value }
+func (hm *HashMap) Pos() lexer.Position { return hm.Token.Start }
 func (hm *HashMap) String() string {
 	var out bytes.Buffer
 	pair := []string{}
-	for key, val := range hm.Pairs {
-		pair = append(pair, key.String()+": "+val.String())
+	for _, p := range hm.Pairs {
+		pair = append(pair, p.Key.String()+": "+p.Value.String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pair, ", "))
@@ -192,7 +254,8 @@ func (a *Array) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (a *Array) TokenValue() string { return a.Token.Value }
+func (a *Array) TokenValue() string  { return a.Token.Value }
+func (a *Array) Pos() lexer.Position { return a.Token.Start }
 func (a *Array) String() string {
 	var out bytes.Buffer
 	elements := []string{}
@@ -205,6 +268,116 @@ func (a *Array) String() string {
 	return out.String()
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Struct Literal: eg: `Point{x: 1, y: 2}`
+// Values are in the order the fields are declared in the struct, fields left out hold their default value
+// ------------------------------------------------------------------------------------------------------------------
+type StructLiteral struct {
+	Token  lexer.Token
+	Name   *Identifier
+	Fields []string
+	Values []Expression
+	Type   *ktype.Type
+}
+
+func (sl *StructLiteral) expressionNode() {}
+func (sl *StructLiteral) GetType() *ktype.TypeCheckResult {
+	return &ktype.TypeCheckResult{
+		Types:   []*ktype.Type{ktype.InternType(sl.Type)},
+		TypeLen: 1,
+	}
+}
+func (sl *StructLiteral) TokenValue() string  { return sl.Token.Value }
+func (sl *StructLiteral) Pos() lexer.Position { return sl.Token.Start }
+func (sl *StructLiteral) String() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, val := range sl.Values {
+		fields = append(fields, sl.Fields[i]+": "+val.String())
+	}
+	out.WriteString(sl.Name.String() + "{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum Value: eg: `Shape.Circle(1.5)` or `Shape.Empty`, Index is the position of the variant in the enum declaration
+// ------------------------------------------------------------------------------------------------------------------
+type EnumValue struct {
+	Token   lexer.Token
+	Enum    *Identifier
+	Variant *Identifier
+	Index   int
+	Args    []Expression
+	Type    *ktype.Type
+}
+
+func (ev *EnumValue) expressionNode() {}
+func (ev *EnumValue) GetType() *ktype.TypeCheckResult {
+	return &ktype.TypeCheckResult{
+		Types:   []*ktype.Type{ktype.InternType(ev.Type)},
+		TypeLen: 1,
+	}
+}
+func (ev *EnumValue) TokenValue() string  { return ev.Token.Value }
+func (ev *EnumValue) Pos() lexer.Position { return ev.Token.Start }
+func (ev *EnumValue) String() string {
+	var out bytes.Buffer
+	out.WriteString(ev.Enum.String() + "." + ev.Variant.String())
+	if ev.Args != nil {
+		args := []string{}
+		for _, arg := range ev.Args {
+			args = append(args, arg.String())
+		}
+		out.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	return out.String()
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Field Access: eg: `point.x`, Index is the position of the field in the struct declaration
+// ------------------------------------------------------------------------------------------------------------------
+type FieldAccess struct {
+	Token lexer.Token
+	Left  Expression
+	Field *Identifier
+	Index int
+	Type  *ktype.Type
+}
+
+func (fa *FieldAccess) expressionNode() {}
+func (fa *FieldAccess) GetType() *ktype.TypeCheckResult {
+	return &ktype.TypeCheckResult{
+		Types:   []*ktype.Type{ktype.InternType(fa.Type)},
+		TypeLen: 1,
+	}
+}
+func (fa *FieldAccess) TokenValue() string  { return fa.Token.Value }
+func (fa *FieldAccess) Pos() lexer.Position { return fa.Left.Pos() }
+func (fa *FieldAccess) String() string      { return fa.Left.String() + "." + fa.Field.String() }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Function Literal: An anonymous function, eg: `fun(x: int): (int) { return: x * 2; }`
+// Function is a function declaration without a name
+// ------------------------------------------------------------------------------------------------------------------
+type FunctionLiteral struct {
+	Token    lexer.Token
+	Function *Function
+	Type     *ktype.Type
+}
+
+func (fl *FunctionLiteral) expressionNode() {}
+func (fl *FunctionLiteral) GetType() *ktype.TypeCheckResult {
+	return &ktype.TypeCheckResult{
+		Types:   []*ktype.Type{ktype.InternType(fl.Type)},
+		TypeLen: 1,
+	}
+}
+func (fl *FunctionLiteral) TokenValue() string  { return fl.Token.Value }
+func (fl *FunctionLiteral) Pos() lexer.Position { return fl.Token.Start }
+func (fl *FunctionLiteral) String() string      { return fl.Function.String() }
+
 // ------------------------------------------------------------------------------------------------------------------
 // Prefix
 // ------------------------------------------------------------------------------------------------------------------
@@ -222,7 +395,8 @@ func (p *Prefix) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (p *Prefix) TokenValue() string { return p.Token.Value }
+func (p *Prefix) TokenValue() string  { return p.Token.Value }
+func (p *Prefix) Pos() lexer.Position { return p.Token.Start }
 func (p *Prefix) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -250,7 +424,8 @@ func (i *Infix) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (i *Infix) TokenValue() string { return i.Token.Value }
+func (i *Infix) TokenValue() string  { return i.Token.Value }
+func (i *Infix) Pos() lexer.Position { return i.Left.Pos() }
 func (i *Infix) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -279,7 +454,8 @@ func (p *Postfix) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (p *Postfix) TokenValue() string { return p.Token.Value }
+func (p *Postfix) TokenValue() string  { return p.Token.Value }
+func (p *Postfix) Pos() lexer.Position { return p.Left.Pos() }
 func (p *Postfix) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -294,7 +470,7 @@ func (p *Postfix) String() string {
 // ------------------------------------------------------------------------------------------------------------------
 type Assignment struct {
 	Token    lexer.Token
-	Left     *Identifier
+	Left     Expression
 	Operator string
 	Right    Expression
 	Type     *ktype.Type
@@ -308,7 +484,8 @@ func (a *Assignment) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (a *Assignment) TokenValue() string { return a.Token.Value }
+func (a *Assignment) TokenValue() string  { return a.Token.Value }
+func (a *Assignment) Pos() lexer.Position { return a.Left.Pos() }
 func (a *Assignment) String() string {
 	return a.Left.String() + " " + a.Operator + " " + a.Right.String()
 }
@@ -317,10 +494,11 @@ func (a *Assignment) String() string {
 // CallExpression
 // ------------------------------------------------------------------------------------------------------------------
 type CallExpression struct {
-	Token lexer.Token
-	Name  *Identifier
-	Args  []Expression
-	Type  []*ktype.Type
+	Token  lexer.Token
+	Name   *Identifier // for calls to a declared (or builtin) function
+	Callee Expression  // for calls through a value of function type, Name is nil then
+	Args   []Expression
+	Type   []*ktype.Type
 }
 
 func (ce *CallExpression) canBeStatement() {}
@@ -332,9 +510,19 @@ func (ce *CallExpression) GetType() *ktype.TypeCheckResult {
 	}
 }
 func (ce *CallExpression) TokenValue() string { return ce.Token.Value }
+func (ce *CallExpression) Pos() lexer.Position {
+	if ce.Name == nil {
+		return ce.Callee.Pos()
+	}
+	return ce.Name.Pos()
+}
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
-	out.WriteString(ce.Name.String())
+	if ce.Name == nil {
+		out.WriteString(ce.Callee.String())
+	} else {
+		out.WriteString(ce.Name.String())
+	}
 	out.WriteString("(")
 
 	if ce.Args != nil {
@@ -366,7 +554,8 @@ func (ie *IndexExpression) GetType() *ktype.TypeCheckResult {
 		TypeLen: 1,
 	}
 }
-func (ie *IndexExpression) TokenValue() string { return ie.Token.Value }
+func (ie *IndexExpression) TokenValue() string  { return ie.Token.Value }
+func (ie *IndexExpression) Pos() lexer.Position { return ie.Left.Pos() }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString(ie.Left.String())
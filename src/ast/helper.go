@@ -2,8 +2,10 @@ package ast
 
 import (
 	"bytes"
+	"strings"
 
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
 )
 
 // ------------------------------------------------------------------------------------------------------------------
@@ -14,7 +16,8 @@ type FunctionParameter struct {
 	ParameterType *ktype.Type
 }
 
-func (fp *FunctionParameter) TokenValue() string { return fp.ParameterName.Token.Value }
+func (fp *FunctionParameter) TokenValue() string  { return fp.ParameterName.Token.Value }
+func (fp *FunctionParameter) Pos() lexer.Position { return fp.ParameterName.Pos() }
 func (fp *FunctionParameter) String() string {
 	var out bytes.Buffer
 	out.WriteString(fp.ParameterName.String())
@@ -29,3 +32,38 @@ func (fp *FunctionParameter) Equals(other *FunctionParameter) bool {
 	}
 	return fp.ParameterName.Equals(other.ParameterName) && fp.ParameterType.Equals(other.ParameterType)
 }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Struct Fields
+// ------------------------------------------------------------------------------------------------------------------
+type StructField struct {
+	FieldName *Identifier
+	FieldType *ktype.Type
+}
+
+func (sf *StructField) TokenValue() string  { return sf.FieldName.Token.Value }
+func (sf *StructField) Pos() lexer.Position { return sf.FieldName.Pos() }
+func (sf *StructField) String() string {
+	return sf.FieldName.String() + ": " + sf.FieldType.String() + ";"
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum Variants: Types are the types of the values the variant carries, nil for a variant without any
+// ------------------------------------------------------------------------------------------------------------------
+type EnumVariant struct {
+	Name  *Identifier
+	Types []*ktype.Type
+}
+
+func (ev *EnumVariant) TokenValue() string  { return ev.Name.Token.Value }
+func (ev *EnumVariant) Pos() lexer.Position { return ev.Name.Pos() }
+func (ev *EnumVariant) String() string {
+	if ev.Types == nil {
+		return ev.Name.String() + ";"
+	}
+	types := []string{}
+	for _, t := range ev.Types {
+		types = append(types, t.String())
+	}
+	return ev.Name.String() + "(" + strings.Join(types, ", ") + ");"
+}
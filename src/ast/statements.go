@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"strings"
 
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
@@ -19,9 +20,23 @@ type ExpressionStatement struct {
 	Expression StatementableExpression
 }
 
-func (es *ExpressionStatement) statementNode()     {}
-func (es *ExpressionStatement) TokenValue() string { return es.Token.Value }
-func (es *ExpressionStatement) String() string     { return es.Expression.String() + ";" }
+func (es *ExpressionStatement) statementNode()      {}
+func (es *ExpressionStatement) TokenValue() string  { return es.Token.Value }
+func (es *ExpressionStatement) Pos() lexer.Position { return es.Token.Start }
+func (es *ExpressionStatement) String() string      { return es.Expression.String() + ";" }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Bare Expression: Any expression entered on its own in the repl, its value is echoed back
+// ------------------------------------------------------------------------------------------------------------------
+type BareExpression struct {
+	Token      lexer.Token
+	Expression Expression
+}
+
+func (be *BareExpression) statementNode()      {}
+func (be *BareExpression) TokenValue() string  { return be.Token.Value }
+func (be *BareExpression) Pos() lexer.Position { return be.Token.Start }
+func (be *BareExpression) String() string      { return be.Expression.String() + ";" }
 
 // ------------------------------------------------------------------------------------------------------------------
 // Body
@@ -31,8 +46,9 @@ type Body struct {
 	Statements []Statement
 }
 
-func (b *Body) statementNode()     {}
-func (b *Body) TokenValue() string { return b.Token.Value }
+func (b *Body) statementNode()      {}
+func (b *Body) TokenValue() string  { return b.Token.Value }
+func (b *Body) Pos() lexer.Position { return b.Token.Start }
 func (b *Body) String() string {
 	var out bytes.Buffer
 	for _, s := range b.Statements {
@@ -47,18 +63,33 @@ func (b *Body) String() string {
 type Function struct {
 	Token       lexer.Token
 	Name        *Identifier
+	TypeParams  []*ktype.Type // nil unless the function is generic, eg: `fun: first<T>(a: T[]): (T)`
 	Parameters  []*FunctionParameter
 	ReturnTypes []*ktype.Type
 	Body        *Body
 }
 
-func (f *Function) statementNode()     {}
-func (f *Function) TokenValue() string { return f.Token.Value }
+func (f *Function) statementNode()      {}
+func (f *Function) TokenValue() string  { return f.Token.Value }
+func (f *Function) Pos() lexer.Position { return f.Token.Start }
 func (f *Function) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(f.TokenValue() + ": ")
-	out.WriteString(f.Name.String() + "(")
+	// a function literal doesn't have a name
+	if f.Name == nil {
+		out.WriteString(f.TokenValue() + "(")
+	} else {
+		out.WriteString(f.TokenValue() + ": ")
+		out.WriteString(f.Name.String())
+		if f.TypeParams != nil {
+			params := []string{}
+			for _, param := range f.TypeParams {
+				params = append(params, param.String())
+			}
+			out.WriteString("<" + strings.Join(params, ", ") + ">")
+		}
+		out.WriteString("(")
+	}
 	if f.Parameters != nil {
 		for i, param := range f.Parameters {
 			out.WriteString(param.String())
@@ -91,6 +122,109 @@ func (f *Function) String() string {
 	return out.String()
 }
 
+// FunctionType returns the type of the function when it's used as a value
+func (f *Function) FunctionType() *ktype.Type {
+	params := []*ktype.Type{}
+	for _, param := range f.Parameters {
+		params = append(params, param.ParameterType)
+	}
+	return ktype.NewFunctionType(params, f.ReturnTypes)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Struct: A user defined type made of named and typed fields, eg: `struct: Point { x: int; y: int; }`
+// ------------------------------------------------------------------------------------------------------------------
+type Struct struct {
+	Token  lexer.Token
+	Name   *Identifier
+	Fields []*StructField
+}
+
+func (s *Struct) statementNode()      {}
+func (s *Struct) TokenValue() string  { return s.Token.Value }
+func (s *Struct) Pos() lexer.Position { return s.Token.Start }
+func (s *Struct) String() string {
+	var out bytes.Buffer
+	out.WriteString(s.TokenValue() + ": ")
+	out.WriteString(s.Name.String() + " {")
+	for _, field := range s.Fields {
+		out.WriteString(field.String())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// FieldIndex returns the position of the field called name, or -1 if the struct doesn't have it
+func (s *Struct) FieldIndex(name string) int {
+	for i, field := range s.Fields {
+		if field.FieldName.Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum: A user defined type whose values are one of its variants, eg: `enum: Shape { Circle(float); Empty; }`
+// ------------------------------------------------------------------------------------------------------------------
+type Enum struct {
+	Token    lexer.Token
+	Name     *Identifier
+	Variants []*EnumVariant
+}
+
+func (en *Enum) statementNode()      {}
+func (en *Enum) TokenValue() string  { return en.Token.Value }
+func (en *Enum) Pos() lexer.Position { return en.Token.Start }
+func (en *Enum) String() string {
+	var out bytes.Buffer
+	out.WriteString(en.TokenValue() + ": ")
+	out.WriteString(en.Name.String() + " {")
+	for _, variant := range en.Variants {
+		out.WriteString(variant.String())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// VariantIndex returns the position of the variant called name, or -1 if the enum doesn't have it
+func (en *Enum) VariantIndex(name string) int {
+	for i, variant := range en.Variants {
+		if variant.Name.Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Import: eg: `import: "lib/util.kol";`, makes the names of the module usable as `util.name`
+// ------------------------------------------------------------------------------------------------------------------
+type Import struct {
+	Token  lexer.Token
+	Path   *String
+	Module *Module
+}
+
+func (i *Import) statementNode()      {}
+func (i *Import) TokenValue() string  { return i.Token.Value }
+func (i *Import) Pos() lexer.Position { return i.Token.Start }
+func (i *Import) String() string      { return i.TokenValue() + ": " + i.Path.String() + ";" }
+
+// Module is a parsed `.kol` file imported by another one, the same Module is shared by every file importing it
+// so it's only evaluated once. Name is the file name without the `.kol` extension, Path is where it was found
+type Module struct {
+	Name    string
+	Path    string
+	Program *Program
+}
+
+// Exported reports if a name declared at the top of a module can be used by the files importing it, names
+// starting with an underscore (`_`) are private. names with a dot come from the imports of the module itself
+func Exported(name string) bool {
+	return !strings.HasPrefix(name, "_") && !strings.Contains(name, ".")
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Var and Const
 // ------------------------------------------------------------------------------------------------------------------
@@ -101,8 +235,9 @@ type VarAndConst struct {
 	Value Expression
 }
 
-func (vac *VarAndConst) statementNode()     {}
-func (vac *VarAndConst) TokenValue() string { return vac.Token.Value }
+func (vac *VarAndConst) statementNode()      {}
+func (vac *VarAndConst) TokenValue() string  { return vac.Token.Value }
+func (vac *VarAndConst) Pos() lexer.Position { return vac.Token.Start }
 func (vac *VarAndConst) String() string {
 	var out bytes.Buffer
 	out.WriteString(vac.TokenValue() + " ")
@@ -126,8 +261,9 @@ type MultiAssignment struct {
 	SingleFunctionCall bool
 }
 
-func (ma *MultiAssignment) statementNode()     {}
-func (ma *MultiAssignment) TokenValue() string { return ma.Token.Value }
+func (ma *MultiAssignment) statementNode()      {}
+func (ma *MultiAssignment) TokenValue() string  { return ma.Token.Value }
+func (ma *MultiAssignment) Pos() lexer.Position { return ma.Objects[0].Pos() }
 func (ma *MultiAssignment) String() string {
 	var out bytes.Buffer
 	for _, obj := range ma.Objects {
@@ -144,8 +280,9 @@ type Return struct {
 	Value []Expression
 }
 
-func (r *Return) statementNode()     {}
-func (r *Return) TokenValue() string { return r.Token.Value }
+func (r *Return) statementNode()      {}
+func (r *Return) TokenValue() string  { return r.Token.Value }
+func (r *Return) Pos() lexer.Position { return r.Token.Start }
 func (r *Return) String() string {
 	var out bytes.Buffer
 
@@ -176,9 +313,10 @@ type Continue struct {
 	Token lexer.Token
 }
 
-func (c *Continue) statementNode()     {}
-func (c *Continue) TokenValue() string { return c.Token.Value }
-func (c *Continue) String() string     { return c.TokenValue() + ";" }
+func (c *Continue) statementNode()      {}
+func (c *Continue) TokenValue() string  { return c.Token.Value }
+func (c *Continue) Pos() lexer.Position { return c.Token.Start }
+func (c *Continue) String() string      { return c.TokenValue() + ";" }
 
 // ------------------------------------------------------------------------------------------------------------------
 // Break
@@ -187,9 +325,10 @@ type Break struct {
 	Token lexer.Token
 }
 
-func (b *Break) statementNode()     {}
-func (b *Break) TokenValue() string { return b.Token.Value }
-func (b *Break) String() string     { return b.TokenValue() + ";" }
+func (b *Break) statementNode()      {}
+func (b *Break) TokenValue() string  { return b.Token.Value }
+func (b *Break) Pos() lexer.Position { return b.Token.Start }
+func (b *Break) String() string      { return b.TokenValue() + ";" }
 
 // ------------------------------------------------------------------------------------------------------------------
 // If
@@ -202,8 +341,9 @@ type If struct {
 	Alternate         *Else
 }
 
-func (i *If) statementNode()     {}
-func (i *If) TokenValue() string { return i.Token.Value }
+func (i *If) statementNode()      {}
+func (i *If) TokenValue() string  { return i.Token.Value }
+func (i *If) Pos() lexer.Position { return i.Token.Start }
 func (i *If) String() string {
 	var out bytes.Buffer
 	out.WriteString(i.TokenValue() + ": (")
@@ -230,8 +370,9 @@ type ElseIf struct {
 	Body      *Body
 }
 
-func (ei *ElseIf) statementNode()     {}
-func (ei *ElseIf) TokenValue() string { return ei.Token.Value }
+func (ei *ElseIf) statementNode()      {}
+func (ei *ElseIf) TokenValue() string  { return ei.Token.Value }
+func (ei *ElseIf) Pos() lexer.Position { return ei.Token.Start }
 func (ei *ElseIf) String() string {
 	var out bytes.Buffer
 	out.WriteString(ei.TokenValue() + ": (")
@@ -248,8 +389,9 @@ type Else struct {
 	Body  *Body
 }
 
-func (e *Else) statementNode()     {}
-func (e *Else) TokenValue() string { return e.Token.Value }
+func (e *Else) statementNode()      {}
+func (e *Else) TokenValue() string  { return e.Token.Value }
+func (e *Else) Pos() lexer.Position { return e.Token.Start }
 func (e *Else) String() string {
 	var out bytes.Buffer
 	out.WriteString(e.TokenValue() + ": {")
@@ -257,6 +399,61 @@ func (e *Else) String() string {
 	return out.String()
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Match: runs the arm of the variant the enum value holds, or Alternate (`else:`) if no arm is for it
+// ------------------------------------------------------------------------------------------------------------------
+type Match struct {
+	Token     lexer.Token
+	Value     Expression
+	Arms      []*MatchArm
+	Alternate *Else
+}
+
+func (m *Match) statementNode()      {}
+func (m *Match) TokenValue() string  { return m.Token.Value }
+func (m *Match) Pos() lexer.Position { return m.Token.Start }
+func (m *Match) String() string {
+	var out bytes.Buffer
+	out.WriteString(m.TokenValue() + ": (")
+	out.WriteString(m.Value.String() + "): {")
+	for _, arm := range m.Arms {
+		out.WriteString(arm.String())
+	}
+	if m.Alternate != nil {
+		out.WriteString(m.Alternate.String())
+	}
+	out.WriteString("}")
+	return out.String()
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// MatchArm: eg: `Circle(r): { ... }`, Bindings are the variables the values of the variant are put in and
+// Index is the position of the variant in the enum declaration
+// ------------------------------------------------------------------------------------------------------------------
+type MatchArm struct {
+	Variant  *Identifier
+	Bindings []*Identifier
+	Index    int
+	Body     *Body
+}
+
+func (ma *MatchArm) statementNode()      {}
+func (ma *MatchArm) TokenValue() string  { return ma.Variant.Token.Value }
+func (ma *MatchArm) Pos() lexer.Position { return ma.Variant.Pos() }
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+	out.WriteString(ma.Variant.String())
+	if ma.Bindings != nil {
+		bindings := []string{}
+		for _, b := range ma.Bindings {
+			bindings = append(bindings, b.String())
+		}
+		out.WriteString("(" + strings.Join(bindings, ", ") + ")")
+	}
+	out.WriteString(": {" + ma.Body.String() + "}")
+	return out.String()
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // ForLoop
 // ------------------------------------------------------------------------------------------------------------------
@@ -268,8 +465,9 @@ type ForLoop struct {
 	Body   *Body
 }
 
-func (f *ForLoop) statementNode()     {}
-func (f *ForLoop) TokenValue() string { return f.Token.Value }
+func (f *ForLoop) statementNode()      {}
+func (f *ForLoop) TokenValue() string  { return f.Token.Value }
+func (f *ForLoop) Pos() lexer.Position { return f.Token.Start }
 func (f *ForLoop) String() string {
 	var out bytes.Buffer
 	out.WriteString(f.TokenValue() + ": (")
@@ -280,6 +478,32 @@ func (f *ForLoop) String() string {
 	return out.String()
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// ForEach: `for: (var x: T in xs): {}` or `for: (var k: K, var v: V in m): {}`, Vars has one or two variables
+// ------------------------------------------------------------------------------------------------------------------
+type ForEach struct {
+	Token    lexer.Token
+	Vars     []*VarAndConst
+	Iterable Expression
+	Body     *Body
+}
+
+func (f *ForEach) statementNode()      {}
+func (f *ForEach) TokenValue() string  { return f.Token.Value }
+func (f *ForEach) Pos() lexer.Position { return f.Token.Start }
+func (f *ForEach) String() string {
+	var out bytes.Buffer
+	vars := []string{}
+	for _, v := range f.Vars {
+		vars = append(vars, v.TokenValue()+" "+v.Name.String()+": "+v.Type.String())
+	}
+	out.WriteString(f.TokenValue() + ": (")
+	out.WriteString(strings.Join(vars, ", ") + " in ")
+	out.WriteString(f.Iterable.String() + "): {")
+	out.WriteString(f.Body.String() + "}")
+	return out.String()
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // WhileLoop
 // ------------------------------------------------------------------------------------------------------------------
@@ -289,8 +513,9 @@ type WhileLoop struct {
 	Body      *Body
 }
 
-func (w *WhileLoop) statementNode()     {}
-func (w *WhileLoop) TokenValue() string { return w.Token.Value }
+func (w *WhileLoop) statementNode()      {}
+func (w *WhileLoop) TokenValue() string  { return w.Token.Value }
+func (w *WhileLoop) Pos() lexer.Position { return w.Token.Start }
 func (w *WhileLoop) String() string {
 	var out bytes.Buffer
 	out.WriteString(w.TokenValue() + ": (")
@@ -4,6 +4,7 @@ import (
 	"bytes"
 
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
 )
 
 type Program struct{ Statements []Statement }
@@ -48,6 +49,7 @@ type StatementableExpression interface {
 // ------------------------------------------------------------------------------------------------------------------
 type Node interface {
 	TokenValue() string
+	Pos() lexer.Position
 	String() string
 }
 
@@ -59,6 +61,13 @@ func (p *Program) TokenValue() string {
 	}
 }
 
+func (p *Program) Pos() lexer.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return lexer.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 	for _, s := range p.Statements {
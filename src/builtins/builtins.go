@@ -0,0 +1,130 @@
+package builtins
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sort"
+
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Builtin: a function written in Go that programs call like their own functions. its signatures are described like
+// generic functions and a call is type checked against the ones that take as many arguments as it passes, eg: `len`
+// of an array, a hashmap or a string
+// ------------------------------------------------------------------------------------------------------------------
+type Builtin struct {
+	Name       string
+	Signatures []*Signature
+
+	// Check is for the rules about the arguments the signatures can't describe, it can be nil
+	Check func(argTypes []*ktype.Type) error
+
+	// Fn runs the builtin, its arguments fit one of the signatures. it gives back nil for a signature that returns
+	// nothing and an array of the values for one that returns more than one
+	Fn func(io *IO, args []object.Object) (object.Object, error)
+}
+
+// Signature is the types a builtin takes and gives back, Variadic is the type of any number of arguments after
+// Params, nil if it takes exactly Params
+type Signature struct {
+	Params   []*ktype.Type
+	Variadic *ktype.Type
+	Returns  []*ktype.Type
+}
+
+func Sig(params []*ktype.Type, returns ...*ktype.Type) *Signature {
+	return &Signature{Params: params, Returns: returns}
+}
+
+// VariadicSig is the signature of a builtin taking params followed by any number of variadic
+func VariadicSig(params []*ktype.Type, variadic *ktype.Type, returns ...*ktype.Type) *Signature {
+	return &Signature{Params: params, Variadic: variadic, Returns: returns}
+}
+
+// Accepts reports if s can be called with n arguments
+func (s *Signature) Accepts(n int) bool {
+	if s.Variadic != nil {
+		return n >= len(s.Params)
+	}
+	return n == len(s.Params)
+}
+
+// ParamsFor returns the types of the n arguments of a call to s
+func (s *Signature) ParamsFor(n int) []*ktype.Type {
+	params := append([]*ktype.Type{}, s.Params...)
+	for len(params) < n {
+		params = append(params, s.Variadic)
+	}
+	return params
+}
+
+// IO is where `print` writes to and `scan` reads from
+type IO struct {
+	Out io.Writer
+	In  *bufio.Reader
+}
+
+// StdIO returns the standard output and input
+func StdIO() *IO {
+	return &IO{Out: os.Stdout, In: bufio.NewReader(os.Stdin)}
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Registry: the builtins a program can call, by name
+// ------------------------------------------------------------------------------------------------------------------
+type Registry struct {
+	builtins map[string]*Builtin
+}
+
+// New returns a registry with the builtins every program has
+func New() *Registry {
+	r := &Registry{builtins: make(map[string]*Builtin, len(standard))}
+	for _, b := range standard {
+		r.builtins[b.Name] = b
+	}
+	return r
+}
+
+// Register adds b, so the programs type checked with r can call it
+func (r *Registry) Register(b *Builtin) error {
+	if !validName(b.Name) {
+		return errors.New("builtin name `" + b.Name + "` must be a valid identifier")
+	}
+	if _, ok := r.builtins[b.Name]; ok {
+		return errors.New("builtin function `" + b.Name + "` already exists")
+	}
+	if len(b.Signatures) == 0 {
+		return errors.New("builtin function `" + b.Name + "` needs at least one signature")
+	}
+	if b.Fn == nil {
+		return errors.New("builtin function `" + b.Name + "` has no implementation")
+	}
+	r.builtins[b.Name] = b
+	return nil
+}
+
+func (r *Registry) Get(name string) (*Builtin, bool) {
+	b, ok := r.builtins[name]
+	return b, ok
+}
+
+// Names returns the names of the builtins in r, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.builtins))
+	for name := range r.builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validName reports if name is an identifier that isn't a keyword, the name of a function has to be one
+func validName(name string) bool {
+	tokens, err := lexer.Tokenizer(name)
+	return err == nil && len(tokens) == 2 && tokens[0].Kind == lexer.IDENTIFIER && tokens[0].Value == name
+}
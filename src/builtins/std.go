@@ -0,0 +1,598 @@
+package builtins
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+var (
+	typeT = ktype.NewTypeParam("T")
+	typeK = ktype.NewTypeParam("K")
+	typeV = ktype.NewTypeParam("V")
+
+	intType    = ktype.NewBaseType("int")
+	floatType  = ktype.NewBaseType("float")
+	boolType   = ktype.NewBaseType("bool")
+	stringType = ktype.NewBaseType("string")
+	charType   = ktype.NewBaseType("char")
+
+	arrayT = ktype.NewArrayType(typeT)
+	mapKV  = ktype.NewHashMapType(typeK, typeV)
+)
+
+func params(types ...*ktype.Type) []*ktype.Type { return types }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Standard: the builtins every program has
+// ------------------------------------------------------------------------------------------------------------------
+var standard = []*Builtin{
+	{Name: "print", Signatures: []*Signature{Sig(params(typeT))}, Fn: print},
+	{Name: "println", Signatures: []*Signature{Sig(params()), Sig(params(typeT))}, Fn: println},
+	{
+		Name: "scan",
+		Signatures: []*Signature{
+			Sig(params(), stringType), Sig(params(stringType), stringType),
+			Sig(params(stringType, boolType), stringType),
+		},
+		Fn: scan,
+	},
+	{
+		Name: "scanln",
+		Signatures: []*Signature{
+			Sig(params(), stringType), Sig(params(stringType), stringType),
+			Sig(params(stringType, boolType), stringType),
+		},
+		Fn: scanln,
+	},
+	{
+		Name: "len",
+		Signatures: []*Signature{
+			Sig(params(arrayT), intType), Sig(params(mapKV), intType), Sig(params(stringType), intType),
+		},
+		Fn: length,
+	},
+	{Name: "toString", Signatures: []*Signature{Sig(params(typeT), stringType)}, Fn: toString},
+	{
+		Name: "toFloat",
+		Signatures: []*Signature{
+			Sig(params(intType), floatType), Sig(params(floatType), floatType), Sig(params(stringType), floatType),
+		},
+		Fn: toFloat,
+	},
+	{
+		Name: "toInt",
+		Signatures: []*Signature{
+			Sig(params(intType), intType), Sig(params(floatType), intType),
+			Sig(params(stringType), intType), Sig(params(charType), intType),
+		},
+		Fn: toInt,
+	},
+	{
+		Name:       "push",
+		Signatures: []*Signature{Sig(params(arrayT, typeT), arrayT), Sig(params(mapKV, typeK, typeV), mapKV)},
+		Fn:         push,
+	},
+	{Name: "pop", Signatures: []*Signature{Sig(params(arrayT), typeT), Sig(params(arrayT, intType), typeT)}, Fn: pop},
+	{Name: "insert", Signatures: []*Signature{Sig(params(arrayT, intType, typeT), arrayT)}, Fn: insert},
+	{
+		Name:       "remove",
+		Signatures: []*Signature{Sig(params(arrayT, typeT), arrayT), Sig(params(mapKV, typeK), mapKV)},
+		Fn:         remove,
+	},
+	{
+		Name:       "delete",
+		Signatures: []*Signature{Sig(params(arrayT, typeT), typeT), Sig(params(mapKV, typeK), typeV)},
+		Fn:         deleteValue,
+	},
+	{Name: "getIndex", Signatures: []*Signature{Sig(params(arrayT, typeT), intType)}, Fn: getIndex},
+	{Name: "keys", Signatures: []*Signature{Sig(params(mapKV), ktype.NewArrayType(typeK))}, Fn: keys},
+	{Name: "values", Signatures: []*Signature{Sig(params(mapKV), ktype.NewArrayType(typeV))}, Fn: values},
+	{Name: "containsKey", Signatures: []*Signature{Sig(params(mapKV, typeK), boolType)}, Fn: containsKey},
+	// the evaluator and the compiler give the type of the argument of `typeOf`, it's known before the program runs
+	{Name: "typeOf", Signatures: []*Signature{Sig(params(typeT), stringType)}},
+	{
+		Name: "slice",
+		Signatures: []*Signature{
+			Sig(params(arrayT, intType, intType), arrayT), Sig(params(arrayT, intType, intType, intType), arrayT),
+			Sig(params(stringType, intType, intType), stringType),
+			Sig(params(stringType, intType, intType, intType), stringType),
+		},
+		Fn: slice,
+	},
+	{
+		Name:       "equals",
+		Signatures: []*Signature{Sig(params(typeT, typeT), boolType)},
+		Check: func(argTypes []*ktype.Type) error {
+			if argTypes[0].Kind == ktype.TypeFunction {
+				return errors.New("functions can't be compared with `equals`")
+			}
+			return nil
+		},
+		Fn: equals,
+	},
+	{
+		Name:       "copy",
+		Signatures: []*Signature{Sig(params(typeT), typeT)},
+		Check: func(argTypes []*ktype.Type) error {
+			switch argTypes[0].Kind {
+			case ktype.TypeArray, ktype.TypeHashMap, ktype.TypeStruct, ktype.TypeEnum:
+				return nil
+			}
+			return errors.New(
+				"data structure not supported by `copy`, got: " +
+					argTypes[0].String() + ", want: array, hashmap, struct or enum",
+			)
+		},
+		Fn: func(_ *IO, args []object.Object) (object.Object, error) { return DeepCopy(args[0]), nil },
+	},
+	{Name: "ceil", Signatures: []*Signature{Sig(params(floatType), floatType)}, Fn: ceil},
+	{Name: "floor", Signatures: []*Signature{Sig(params(floatType), floatType)}, Fn: floor},
+	{
+		Name:       "round",
+		Signatures: []*Signature{Sig(params(floatType), floatType), Sig(params(floatType, intType), floatType)},
+		Fn:         round,
+	},
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Input and Output
+// ------------------------------------------------------------------------------------------------------------------
+func print(io *IO, args []object.Object) (object.Object, error) {
+	fmt.Fprint(io.Out, Printable(args[0]))
+	return nil, nil
+}
+
+func println(io *IO, args []object.Object) (object.Object, error) {
+	if len(args) == 0 {
+		fmt.Fprintln(io.Out)
+		return nil, nil
+	}
+	fmt.Fprintln(io.Out, Printable(args[0]))
+	return nil, nil
+}
+
+// prompt writes the prompt of `scan` and `scanln` if there is one, on its own line if the second argument is true
+func prompt(io *IO, args []object.Object) {
+	if len(args) == 0 {
+		return
+	}
+	strToPrint := args[0].(*object.String).Value
+	if len(args) == 2 && args[1].Inspect() == "true" {
+		fmt.Fprintln(io.Out, strToPrint)
+	} else {
+		fmt.Fprint(io.Out, strToPrint)
+	}
+}
+
+// scan reads lines up to an empty one and joins them with spaces
+func scan(io *IO, args []object.Object) (object.Object, error) {
+	prompt(io, args)
+	var input []string
+	for {
+		line, err := io.In.ReadString('\n')
+		if err != nil {
+			return nil, errors.New("error reading input: " + err.Error())
+		}
+		line = strings.TrimSpace(line)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+		input = append(input, line)
+	}
+	return &object.String{Value: strings.Join(input, " ")}, nil
+}
+
+func scanln(io *IO, args []object.Object) (object.Object, error) {
+	prompt(io, args)
+	input, err := io.In.ReadString('\n')
+	if err != nil {
+		return nil, errors.New("error reading input: " + err.Error())
+	}
+	input = strings.TrimSpace(input)
+	input = strings.TrimSuffix(input, "\n")
+	return &object.String{Value: input}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Conversions
+// ------------------------------------------------------------------------------------------------------------------
+func length(_ *IO, args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}, nil
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}, nil
+	case *object.HashMap:
+		return &object.Integer{Value: int64(arg.Len())}, nil
+	}
+	return nil, nil
+}
+
+func toString(_ *IO, args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return &object.String{Value: strconv.FormatInt(arg.Value, 10)}, nil
+	case *object.Float:
+		return &object.String{Value: strconv.FormatFloat(arg.Value, 'f', -1, 64)}, nil
+	case *object.Bool:
+		return &object.String{Value: strconv.FormatBool(arg.Value)}, nil
+	case *object.Char:
+		return &object.String{Value: arg.Value}, nil
+	case *object.String:
+		return arg, nil
+	default:
+		return &object.String{Value: arg.Inspect()}, nil
+	}
+}
+
+func toInt(_ *IO, args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return arg, nil
+	case *object.Float:
+		return &object.Integer{Value: int64(arg.Value)}, nil
+	case *object.Char:
+		code, _ := utf8.DecodeRuneInString(arg.Value)
+		if arg.Value == "" {
+			code = 0
+		}
+		return &object.Integer{Value: int64(code)}, nil
+	case *object.String:
+		i, err := strconv.ParseInt(arg.Value, 10, 64)
+		if err != nil {
+			return nil, errors.New("Error converting string to int, can't convert: " + arg.Value)
+		}
+		return &object.Integer{Value: i}, nil
+	}
+	return nil, nil
+}
+
+func toFloat(_ *IO, args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return &object.Float{Value: float64(arg.Value)}, nil
+	case *object.Float:
+		return arg, nil
+	case *object.String:
+		f, err := strconv.ParseFloat(arg.Value, 64)
+		if err != nil {
+			return nil, errors.New("Error converting string to float, can't convert: " + arg.Value)
+		}
+		return &object.Float{Value: f}, nil
+	}
+	return nil, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Arrays and HashMaps
+// ------------------------------------------------------------------------------------------------------------------
+func hashKey(key object.Object) (object.Hashable, error) {
+	k, ok := key.(object.Hashable)
+	if !ok {
+		return nil, errors.New("unusable as hash key: " + string(key.Type()))
+	}
+	return k, nil
+}
+
+func getIndex(_ *IO, args []object.Object) (object.Object, error) {
+	if arg, ok := args[0].(*object.Array); ok {
+		t := args[1].Inspect()
+		for i, ele := range arg.Elements {
+			if ele.Inspect() == t {
+				return &object.Integer{Value: int64(i)}, nil
+			}
+		}
+	}
+	return &object.Integer{Value: -1}, nil
+}
+
+func keys(_ *IO, args []object.Object) (object.Object, error) {
+	var keys []object.Object
+	for _, pair := range args[0].(*object.HashMap).Pairs() {
+		keys = append(keys, pair.Key)
+	}
+	return &object.Array{Elements: keys}, nil
+}
+
+func values(_ *IO, args []object.Object) (object.Object, error) {
+	var values []object.Object
+	for _, pair := range args[0].(*object.HashMap).Pairs() {
+		values = append(values, pair.Value)
+	}
+	return &object.Array{Elements: values}, nil
+}
+
+func containsKey(_ *IO, args []object.Object) (object.Object, error) {
+	k, err := hashKey(args[1])
+	if err != nil {
+		return nil, err
+	}
+	_, ok := args[0].(*object.HashMap).Get(k)
+	return object.NativeBool(ok), nil
+}
+
+func push(_ *IO, args []object.Object) (object.Object, error) {
+	if arg, ok := args[0].(*object.Array); ok {
+		arg.Elements = append(arg.Elements, args[1])
+		return arg, nil
+	}
+	k, err := hashKey(args[1])
+	if err != nil {
+		return nil, err
+	}
+	args[0].(*object.HashMap).Set(k, args[2])
+	return args[0], nil
+}
+
+func pop(_ *IO, args []object.Object) (object.Object, error) {
+	a := args[0].(*object.Array)
+	var popped object.Object
+	if len(args) == 1 {
+		if len(a.Elements) == 0 {
+			return nil, errors.New("array is empty, can't pop any elements")
+		}
+		popped = a.Elements[len(a.Elements)-1]
+		a.Elements = a.Elements[:len(a.Elements)-1]
+	} else {
+		idx := args[1].(*object.Integer).Value
+		if idx < 0 || idx >= int64(len(a.Elements)) {
+			return nil,
+				errors.New("index out of range, can't pop element at index: " +
+					strconv.FormatInt(idx, 10),
+				)
+		}
+		popped = a.Elements[idx]
+		a.Elements = append(a.Elements[:idx], a.Elements[idx+1:]...)
+	}
+	return popped, nil
+}
+
+func insert(_ *IO, args []object.Object) (object.Object, error) {
+	a := args[0].(*object.Array)
+	idx := args[1].(*object.Integer).Value
+	if idx < 0 || idx > int64(len(a.Elements)) {
+		return nil,
+			errors.New("index out of range, can't insert element at index: " +
+				strconv.FormatInt(idx, 10),
+			)
+	}
+	a.Elements = append(a.Elements[:idx], append([]object.Object{args[2]}, a.Elements[idx:]...)...)
+	return a, nil
+}
+
+func remove(_ *IO, args []object.Object) (object.Object, error) {
+	if arg, ok := args[0].(*object.Array); ok {
+		eleToRemove := args[1].Inspect()
+		for i, ele := range arg.Elements {
+			if ele.Inspect() == eleToRemove {
+				arg.Elements = append(arg.Elements[:i], arg.Elements[i+1:]...)
+				break
+			}
+		}
+		return arg, nil
+	}
+	k, err := hashKey(args[1])
+	if err != nil {
+		return nil, err
+	}
+	args[0].(*object.HashMap).Delete(k)
+	return args[0], nil
+}
+
+// deleteValue removes an element (or key) like `remove`, giving back the value it removed, nil if there was none
+func deleteValue(_ *IO, args []object.Object) (object.Object, error) {
+	if arg, ok := args[0].(*object.Array); ok {
+		eleToDelete := args[1].Inspect()
+		for i, ele := range arg.Elements {
+			if ele.Inspect() == eleToDelete {
+				arg.Elements = append(arg.Elements[:i], arg.Elements[i+1:]...)
+				return args[1], nil
+			}
+		}
+		return nil, nil
+	}
+	k, err := hashKey(args[1])
+	if err != nil {
+		return nil, err
+	}
+	pair, ok := args[0].(*object.HashMap).Delete(k)
+	if !ok {
+		return nil, nil
+	}
+	return pair.Value, nil
+}
+
+func slice(_ *IO, args []object.Object) (object.Object, error) {
+	start := args[1].(*object.Integer).Value
+	end := args[2].(*object.Integer).Value
+	var step int64 = 1
+	if len(args) == 4 {
+		step = args[3].(*object.Integer).Value
+	}
+
+	if arg, ok := args[0].(*object.Array); ok {
+		if start < 0 || start >= int64(len(arg.Elements)) ||
+			end < 0 || end > int64(len(arg.Elements)) || start > end {
+			return nil,
+				errors.New("index out of range, can't slice array from " +
+					strconv.FormatInt(start, 10) + " to " +
+					strconv.FormatInt(end, 10),
+				)
+		}
+		if len(args) == 3 {
+			return &object.Array{Elements: arg.Elements[start:end]}, nil
+		}
+		if err := checkStep(step); err != nil {
+			return nil, err
+		}
+		var sliced []object.Object
+		for i := start; i < end; i += step {
+			sliced = append(sliced, arg.Elements[i])
+		}
+		return &object.Array{Elements: sliced}, nil
+	}
+
+	s := []rune(args[0].(*object.String).Value)
+	if start < 0 || start >= int64(len(s)) ||
+		end < 0 || end > int64(len(s)) || start > end {
+		return nil,
+			errors.New("index out of range, can't slice string from " +
+				strconv.FormatInt(start, 10) + " to " +
+				strconv.FormatInt(end, 10),
+			)
+	}
+	if len(args) == 3 {
+		return &object.String{Value: string(s[start:end])}, nil
+	}
+	if err := checkStep(step); err != nil {
+		return nil, err
+	}
+	var sliced strings.Builder
+	for i := start; i < end; i += step {
+		sliced.WriteRune(s[i])
+	}
+	return &object.String{Value: sliced.String()}, nil
+}
+
+func checkStep(step int64) error {
+	if step <= 0 {
+		return errors.New("step must be a positive integer, got: " + strconv.FormatInt(step, 10))
+	}
+	return nil
+}
+
+func equals(_ *IO, args []object.Object) (object.Object, error) {
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return object.NativeBool(arg.Value == args[1].(*object.Integer).Value), nil
+	case *object.Float:
+		return object.NativeBool(arg.Value == args[1].(*object.Float).Value), nil
+	case *object.Bool:
+		return object.NativeBool(arg.Value == args[1].(*object.Bool).Value), nil
+	case *object.String:
+		return object.NativeBool(arg.Value == args[1].(*object.String).Value), nil
+	case *object.Char:
+		return object.NativeBool(arg.Value == args[1].(*object.Char).Value), nil
+	case *object.Array:
+		other := args[1].(*object.Array)
+		return object.NativeBool(len(arg.Elements) == len(other.Elements) && arg.Inspect() == other.Inspect()), nil
+	case *object.Struct, *object.EnumValue:
+		return object.NativeBool(arg.Inspect() == args[1].Inspect()), nil
+	}
+	h1 := args[0].(*object.HashMap)
+	h2 := args[1].(*object.HashMap)
+	if h1.Len() != h2.Len() {
+		return object.FALSE, nil
+	}
+	// the same pairs added in a different order are still equal
+	for _, pair := range h1.Pairs() {
+		other, ok := h2.Get(pair.Key.(object.Hashable))
+		if !ok || object.Display(other.Value) != object.Display(pair.Value) {
+			return object.FALSE, nil
+		}
+	}
+	return object.TRUE, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Math
+// ------------------------------------------------------------------------------------------------------------------
+func ceil(_ *IO, args []object.Object) (object.Object, error) {
+	return &object.Float{Value: math.Ceil(args[0].(*object.Float).Value)}, nil
+}
+
+func floor(_ *IO, args []object.Object) (object.Object, error) {
+	return &object.Float{Value: math.Floor(args[0].(*object.Float).Value)}, nil
+}
+
+func round(_ *IO, args []object.Object) (object.Object, error) {
+	arg := args[0].(*object.Float)
+	if len(args) == 1 {
+		return &object.Float{Value: math.Round(arg.Value)}, nil
+	}
+	precision := args[1].(*object.Integer).Value
+	if precision < 0 {
+		return nil, errors.New("precision must be a non-negative integer for `round`")
+	}
+	if precision > 15 {
+		precision = 15
+	}
+	multiplier := math.Pow(10, float64(precision))
+	return &object.Float{Value: math.Round(arg.Value*multiplier) / multiplier}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Helpers
+// ------------------------------------------------------------------------------------------------------------------
+
+// Printable returns the text print and println write out for o, strings and chars without their quotes
+func Printable(o object.Object) string {
+	switch obj := o.(type) {
+	case *object.String:
+		return obj.Value
+	case *object.Char:
+		return obj.Value
+	case *object.Integer:
+		return strconv.FormatInt(obj.Value, 10)
+	case *object.Float:
+		s := strconv.FormatFloat(obj.Value, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case *object.Bool:
+		return strconv.FormatBool(obj.Value)
+	default:
+		return o.Inspect()
+	}
+}
+
+// DeepCopy copies o and everything in it, so changing the copy doesn't change o
+func DeepCopy(o object.Object) object.Object {
+	switch obj := o.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: obj.Value}
+	case *object.Float:
+		return &object.Float{Value: obj.Value}
+	case *object.String:
+		return &object.String{Value: obj.Value}
+	case *object.Char:
+		return &object.Char{Value: obj.Value}
+	case *object.Array:
+		copyEle := make([]object.Object, len(obj.Elements))
+		for i, ele := range obj.Elements {
+			copyEle[i] = DeepCopy(ele)
+		}
+		return &object.Array{Elements: copyEle}
+	case *object.Struct:
+		copyValues := make([]object.Object, len(obj.Values))
+		for i, v := range obj.Values {
+			copyValues[i] = DeepCopy(v)
+		}
+		return &object.Struct{Name: obj.Name, Fields: obj.Fields, Values: copyValues}
+	case *object.EnumValue:
+		var copyValues []object.Object
+		for _, v := range obj.Values {
+			copyValues = append(copyValues, DeepCopy(v))
+		}
+		return &object.EnumValue{Enum: obj.Enum, Variant: obj.Variant, Index: obj.Index, Values: copyValues}
+	case *object.HashMap:
+		newPairs := object.NewHashMap()
+		for _, v := range obj.Pairs() {
+			newPairs.Set(v.Key.(object.Hashable), DeepCopy(v.Value))
+		}
+		return newPairs
+	default:
+		// bools are the two shared values and closures (of the evaluator or the vm) share the variables they
+		// captured, neither is copied
+		return obj
+	}
+}
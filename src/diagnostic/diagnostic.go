@@ -0,0 +1,124 @@
+package diagnostic
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Diagnostic: An error that knows where in the source it happened
+// eg: main.kol:12:9: type mismatch in variable/constant declaration, expected: int, got: string
+// ------------------------------------------------------------------------------------------------------------------
+type Diagnostic struct {
+	File string
+	Pos  lexer.Position
+	Msg  string
+	Err  error // the error Wrap gave the position to, nil for the errors made with New
+}
+
+func (d *Diagnostic) Error() string {
+	if d.File == "" {
+		return d.Pos.String() + ": " + d.Msg
+	}
+	return d.File + ":" + d.Pos.String() + ": " + d.Msg
+}
+
+func (d *Diagnostic) Unwrap() error { return d.Err }
+
+func New(pos lexer.Position, msg string) *Diagnostic {
+	return &Diagnostic{Pos: pos, Msg: msg}
+}
+
+// Wrap attaches pos to err, unless err already carries a position or pos is unknown
+func Wrap(pos lexer.Position, err error) error {
+	if err == nil || !pos.IsValid() {
+		return err
+	}
+	var d *Diagnostic
+	var lexErr *lexer.LexError
+	if errors.As(err, &d) || errors.As(err, &lexErr) {
+		return err
+	}
+	return &Diagnostic{Pos: pos, Msg: err.Error(), Err: err}
+}
+
+// WithFile records the name of the file err came from, so it gets printed as `file:line:col: msg`
+func WithFile(err error, file string) error {
+	if list, ok := err.(List); ok {
+		for _, e := range list {
+			WithFile(e, file)
+		}
+		return err
+	}
+	var lexErr *lexer.LexError
+	if errors.As(err, &lexErr) {
+		return &Diagnostic{File: file, Pos: lexErr.Pos, Msg: lexErr.Msg}
+	}
+	var d *Diagnostic
+	if errors.As(err, &d) && d.File == "" {
+		d.File = file
+	}
+	return err
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// List: All the errors found in a single pass over the source, one per line
+// ------------------------------------------------------------------------------------------------------------------
+type List []error
+
+func (l List) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Sort orders the list by file and position, errors without a position go at the end
+func (l List) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := Pos(l[i]), Pos(l[j])
+		if !a.IsValid() || !b.IsValid() {
+			return a.IsValid()
+		}
+		if fa, fb := file(l[i]), file(l[j]); fa != fb {
+			return fa < fb
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// Errors splits err back into the individual errors it is made of
+func Errors(err error) []error {
+	if list, ok := err.(List); ok {
+		return list
+	}
+	return []error{err}
+}
+
+// Pos returns the position err carries, unknown if it has none
+func Pos(err error) lexer.Position {
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return d.Pos
+	}
+	var lexErr *lexer.LexError
+	if errors.As(err, &lexErr) {
+		return lexErr.Pos
+	}
+	return lexer.Position{}
+}
+
+func file(err error) string {
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return d.File
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package environment
 
 import (
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/builtins"
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 	"github.com/KhushPatibandha/Kolon/src/object"
 )
@@ -12,6 +13,8 @@ const (
 	VAR IdentType = iota
 	CONST
 	FUNCTION
+	STRUCT
+	ENUM
 )
 
 type Symbol struct {
@@ -19,6 +22,8 @@ type Symbol struct {
 	Ident       *ast.Identifier
 	Type        *ktype.Type
 	Func        *FuncInfo
+	Struct      *ast.Struct
+	Enum        *ast.Enum
 	Env         *Environment
 	ValueObject object.Object
 }
@@ -26,11 +31,25 @@ type Symbol struct {
 type FuncInfo struct {
 	Function *ast.Function
 	Builtin  bool
+	Native   *builtins.Builtin // signatures and implementation of a builtin
 }
 
+// Closure is a function used as a value, Env is the environment it was created in, which it keeps alive so the
+// function can still reach the variables around it after that scope is gone
+type Closure struct {
+	Function *ast.Function
+	Env      *Environment
+	FuncType *ktype.Type
+}
+
+func (c *Closure) Inspect() string         { return c.FuncType.String() }
+func (c *Closure) Type() object.ObjectType { return object.FUNC_OBJ }
+
 type Environment struct {
 	VariableNameSpace map[string]*Symbol
 	FuncNameSpace     map[string]*Symbol
+	TypeNameSpace     map[string]*Symbol
+	Values            []object.Object // variables of a frame, at the slots the resolver gave them
 	Outer             *Environment
 }
 
@@ -38,6 +57,7 @@ func NewEnvironment() *Environment {
 	return &Environment{
 		VariableNameSpace: make(map[string]*Symbol),
 		FuncNameSpace:     make(map[string]*Symbol),
+		TypeNameSpace:     make(map[string]*Symbol),
 		Outer:             nil,
 	}
 }
@@ -48,6 +68,36 @@ func NewEnclosedEnvironment(Outer *Environment) *Environment {
 	return env
 }
 
+// NewFrame returns the environment of a scope the evaluator runs, its variables are kept in Values instead of by
+// name, only the top level of a file has the name spaces
+func NewFrame(Outer *Environment) *Environment {
+	return &Environment{Outer: Outer}
+}
+
+// Get returns the variable at slot index of the environment depth scopes out of e
+func (e *Environment) Get(depth, index int) object.Object {
+	for ; depth > 0; depth-- {
+		e = e.Outer
+	}
+	return e.Values[index]
+}
+
+// Assign sets the variable at slot index of the environment depth scopes out of e
+func (e *Environment) Assign(depth, index int, v object.Object) {
+	for ; depth > 0; depth-- {
+		e = e.Outer
+	}
+	e.Values[index] = v
+}
+
+// Define declares the variable at slot index of e, making room for it if needed
+func (e *Environment) Define(index int, v object.Object) {
+	for len(e.Values) <= index {
+		e.Values = append(e.Values, nil)
+	}
+	e.Values[index] = v
+}
+
 func (e *Environment) GetVar(name string) (*Symbol, bool) {
 	sym, ok := e.VariableNameSpace[name]
 	if !ok && e.Outer != nil {
@@ -70,6 +120,34 @@ func (e *Environment) GetFunc(name string) (*Symbol, bool) {
 	return sym, true
 }
 
+// GetType looks up a user defined type, a struct or an enum
+func (e *Environment) GetType(name string) (*Symbol, bool) {
+	sym, ok := e.TypeNameSpace[name]
+	if !ok && e.Outer != nil {
+		sym, ok = e.Outer.GetType(name)
+	}
+	if !ok {
+		return nil, false
+	}
+	return sym, true
+}
+
+func (e *Environment) GetStruct(name string) (*Symbol, bool) {
+	sym, ok := e.GetType(name)
+	if !ok || sym.IdentType != STRUCT {
+		return nil, false
+	}
+	return sym, true
+}
+
+func (e *Environment) GetEnum(name string) (*Symbol, bool) {
+	sym, ok := e.GetType(name)
+	if !ok || sym.IdentType != ENUM {
+		return nil, false
+	}
+	return sym, true
+}
+
 func (e *Environment) Set(sym *Symbol) {
 	sym.Type = ktype.InternType(sym.Type)
 	switch sym.IdentType {
@@ -77,6 +155,12 @@ func (e *Environment) Set(sym *Symbol) {
 		e.VariableNameSpace[sym.Ident.Value] = sym
 	case FUNCTION:
 		e.FuncNameSpace[sym.Ident.Value] = sym
+	case STRUCT, ENUM:
+		e.TypeNameSpace[sym.Ident.Value] = sym
+		// the types of a module are named `module.Type`, they're looked up by that name as well
+		if sym.Type != nil && sym.Type.Name != sym.Ident.Value {
+			e.TypeNameSpace[sym.Type.Name] = sym
+		}
 	}
 }
 
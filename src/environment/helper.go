@@ -2,16 +2,14 @@ package environment
 
 import (
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/builtins"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
 )
 
-func LoadBuiltins(env *Environment) {
-	builtins := []string{
-		"print", "println", "scan", "scanln", "len", "toString", "toFloat", "toInt",
-		"push", "pop", "insert", "remove", "getIndex", "keys", "values", "containsKey",
-		"typeOf", "slice", "delete", "equals", "copy", "ceil", "floor", "round",
-	}
-	for _, name := range builtins {
+// LoadBuiltins declares the builtins of reg in env
+func LoadBuiltins(env *Environment, reg *builtins.Registry) {
+	for _, name := range reg.Names() {
+		b, _ := reg.Get(name)
 		env.FuncNameSpace[name] = &Symbol{
 			IdentType: FUNCTION,
 			Ident: &ast.Identifier{
@@ -21,6 +19,7 @@ func LoadBuiltins(env *Environment) {
 			Func: &FuncInfo{
 				Builtin:  true,
 				Function: nil,
+				Native:   b,
 			},
 			Type: nil,
 			Env:  nil,
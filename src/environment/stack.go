@@ -13,6 +13,8 @@ func (s *Stack) Pop() *Environment {
 		return nil
 	}
 	env := s.Stk[len(s.Stk)-1]
+	// the slot is cleared so the popped environment isn't kept alive by the array under the stack
+	s.Stk[len(s.Stk)-1] = nil
 	s.Stk = s.Stk[:len(s.Stk)-1]
 	return env
 }
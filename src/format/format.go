@@ -0,0 +1,369 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+)
+
+const indentation = "    "
+
+// ------------------------------------------------------------------------------------------------------------------
+// Format: Writes a program back out with the canonical indentation and spacing. it works on the tokens (and the
+// comments kept on them) rather than the ast, so a program that doesn't type check, or imports a module that isn't
+// there, can still be formatted. the line breaks of the source are kept, except that every statement and every
+// block gets its own lines
+// ------------------------------------------------------------------------------------------------------------------
+func Source(source string) (string, error) {
+	tokens, err := lexer.Tokenizer(source)
+	if err != nil {
+		return "", err
+	}
+	f := &formatter{frames: []*frame{{kind: block}}}
+	for _, token := range tokens {
+		if err := f.write(token); err != nil {
+			return "", err
+		}
+	}
+	if open := f.top(); len(f.frames) > 1 {
+		return "", diagnostic.New(open.open.Start, "`"+open.open.Value+"` is never closed")
+	}
+	out := f.out.String()
+	if err := same(tokens, out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+type frameKind int
+
+const (
+	block      frameKind = iota // statements: the file, a function, an if, a loop, a struct or an enum
+	matchBlock                  // the arms of a match
+	literal                     // a hashmap or a struct literal
+	group                       // `( ... )` or `[ ... ]`
+	typeParams                  // `< ... >` of a generic function
+	interp                      // `${ ... }` of an interpolated string
+)
+
+// frame is a bracket that is open, indent is the one of the lines inside it
+type frame struct {
+	kind   frameKind
+	open   lexer.Token
+	indent int
+
+	// for a block, the first token of the statement being written and how many tokens of it are written
+	first lexer.TokenKind
+	n     int
+}
+
+type formatter struct {
+	out    strings.Builder
+	frames []*frame
+
+	prev     *lexer.Token    // the last token written, nil at the start
+	prev2    lexer.TokenKind // the kind of the token before it
+	prevLine int             // the line of the source the last token or comment written ends on
+	line     int             // the indent of the line being written
+	opened   *frame          // the frame prev opened
+	closed   *frame          // the frame prev closed
+	prefix   bool            // prev is a prefix operator (`-` or `!`)
+	comment  bool            // the line being written ends with a comment
+}
+
+func (f *formatter) top() *frame { return f.frames[len(f.frames)-1] }
+
+func (f *formatter) write(t lexer.Token) error {
+	closing, err := f.closes(t)
+	if err != nil {
+		return err
+	}
+	for _, c := range t.Trivia {
+		f.writeComment(c, closing)
+	}
+	if t.Kind == lexer.EOF {
+		if f.out.Len() != 0 {
+			f.out.WriteString("\n")
+		}
+		return nil
+	}
+
+	opening := f.opens(t)
+	if f.prev != nil || f.comment {
+		switch f.between(t, closing, opening) {
+		case space:
+			f.out.WriteString(" ")
+		case newline:
+			indent := f.indent()
+			if closing != nil {
+				indent = closing.indent - 1
+			}
+			blank := t.Start.Line-f.prevLine > 1 && f.opened == nil && closing == nil
+			f.newline(indent, blank)
+		}
+	}
+
+	if t.Kind == lexer.ELSE_IF {
+		f.out.WriteString("else if")
+	} else {
+		f.out.WriteString(t.Value)
+	}
+
+	f.prefix = (t.Kind == lexer.DASH || t.Kind == lexer.NOT) && !f.operand()
+	f.closed, f.opened = nil, nil
+	if closing != nil {
+		f.frames = f.frames[:len(f.frames)-1]
+		f.closed = closing
+	}
+	top := f.top()
+	if top.kind == block || top.kind == matchBlock {
+		if top.n == 0 {
+			top.first = t.Kind
+		}
+		top.n++
+		if t.Kind == lexer.SEMI_COLON || closing != nil && (closing.kind == block || closing.kind == matchBlock) {
+			top.n = 0
+		}
+	}
+	if opening != nil {
+		opening.indent = f.line + 1
+		f.frames = append(f.frames, opening)
+		f.opened = opening
+	}
+
+	if f.prev != nil {
+		f.prev2 = f.prev.Kind
+	}
+	f.prev = &t
+	f.prevLine = t.End.Line
+	f.comment = false
+	return nil
+}
+
+// writeComment writes c, at the end of the line being written if it was after the last token in the source,
+// or on its own line before the token it's kept on
+func (f *formatter) writeComment(c lexer.Trivia, closing *frame) {
+	switch {
+	case f.prev != nil && c.Start.Line == f.prevLine && !f.comment:
+		f.out.WriteString(" ")
+	case f.out.Len() != 0:
+		indent := f.indent()
+		if closing != nil {
+			indent = closing.indent
+		}
+		f.newline(indent, c.Start.Line-f.prevLine > 1 && f.opened == nil)
+	}
+	f.out.WriteString(c.Text)
+	f.prevLine = c.Start.Line
+	f.comment = true
+	f.opened = nil
+}
+
+func (f *formatter) newline(indent int, blank bool) {
+	if blank {
+		f.out.WriteString("\n")
+	}
+	f.out.WriteString("\n")
+	f.out.WriteString(strings.Repeat(indentation, indent))
+	f.line = indent
+}
+
+// indent returns the indent of a line started before the next token, a statement going on over more than one line
+// is indented once more than the block it's in
+func (f *formatter) indent() int {
+	top := f.top()
+	if (top.kind == block || top.kind == matchBlock) && top.n != 0 {
+		return top.indent + 1
+	}
+	return top.indent
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Brackets: the frames opened and closed by a token
+// ------------------------------------------------------------------------------------------------------------------
+
+// closes returns the frame closed by t, nil if t doesn't close one
+func (f *formatter) closes(t lexer.Token) (*frame, error) {
+	top := f.top()
+	var want lexer.TokenKind
+	switch t.Kind {
+	case lexer.CLOSE_BRACKET, lexer.CLOSE_SQUARE_BRACKET:
+		want = lexer.OPEN_BRACKET
+		if t.Kind == lexer.CLOSE_SQUARE_BRACKET {
+			want = lexer.OPEN_SQUARE_BRACKET
+		}
+	case lexer.CLOSE_CURLY_BRACKET:
+		want = lexer.OPEN_CURLY_BRACKET
+	case lexer.INTERP_MIDDLE, lexer.INTERP_END:
+		if top.kind != interp {
+			return nil, diagnostic.New(t.Start, "unexpected end of a string interpolation")
+		}
+		return top, nil
+	case lexer.GREATER_THAN:
+		if top.kind == typeParams {
+			return top, nil
+		}
+		return nil, nil
+	default:
+		return nil, nil
+	}
+	if len(f.frames) == 1 || top.open.Kind != want {
+		return nil, diagnostic.New(t.Start, "unexpected `"+t.Value+"`")
+	}
+	return top, nil
+}
+
+// opens returns the frame t opens, nil if it doesn't open one
+func (f *formatter) opens(t lexer.Token) *frame {
+	top := f.top()
+	statement := top.kind == block || top.kind == matchBlock
+	switch t.Kind {
+	case lexer.OPEN_BRACKET, lexer.OPEN_SQUARE_BRACKET:
+		return &frame{kind: group, open: t}
+	case lexer.INTERP_START, lexer.INTERP_MIDDLE:
+		return &frame{kind: interp, open: t}
+	case lexer.LESS_THAN:
+		// `fun: name<T>`
+		if statement && top.first == lexer.FUN && top.n == 3 {
+			return &frame{kind: typeParams, open: t}
+		}
+		return nil
+	case lexer.OPEN_CURLY_BRACKET:
+	default:
+		return nil
+	}
+
+	kind := literal
+	switch {
+	case f.prev == nil:
+	case f.prev.Kind == lexer.CLOSE_BRACKET:
+		// the body of a function, or of a function literal
+		kind = block
+	case f.prev.Kind == lexer.COLON && statement && (f.prev2 == lexer.CLOSE_BRACKET || f.prev2 == lexer.ELSE):
+		// `if: (...): {`, `for: (...): {`, `else: {`, `Circle(r): {`, ...
+		kind = block
+		if top.first == lexer.MATCH {
+			kind = matchBlock
+		}
+	case f.prev.Kind == lexer.COLON && top.kind == matchBlock && f.prev2 == lexer.IDENTIFIER:
+		// `Empty: {`
+		kind = block
+	case f.prev.Kind == lexer.IDENTIFIER && statement && (top.first == lexer.STRUCT || top.first == lexer.ENUM):
+		// `struct: Point {`
+		kind = block
+	}
+	return &frame{kind: kind, open: t}
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Spacing: what goes between the last token written and the next one
+// ------------------------------------------------------------------------------------------------------------------
+type separator int
+
+const (
+	none separator = iota
+	space
+	newline
+)
+
+func (f *formatter) between(t lexer.Token, closing *frame, opening *frame) separator {
+	top := f.top()
+	switch {
+	case f.comment:
+		return newline
+	case f.opened != nil && (f.opened.kind == block || f.opened.kind == matchBlock):
+		if closing == f.opened {
+			return none
+		}
+		return newline
+	case closing != nil && (closing.kind == block || closing.kind == matchBlock):
+		return newline
+	case f.prev.Kind == lexer.SEMI_COLON && (top.kind == block || top.kind == matchBlock):
+		return newline
+	case f.closed != nil && (f.closed.kind == block || f.closed.kind == matchBlock):
+		// the end of a function literal is followed by the rest of the expression it's in
+		if closing != nil {
+			return none
+		}
+		switch t.Kind {
+		case lexer.SEMI_COLON, lexer.COMMA, lexer.DOT, lexer.OPEN_BRACKET:
+			return none
+		case lexer.ELSE, lexer.ELSE_IF:
+			if top.kind != matchBlock {
+				return space
+			}
+		}
+		return newline
+	case opening != nil && (opening.kind == block || opening.kind == matchBlock):
+		return space
+	case top.kind != interp && (closing == nil || closing.kind != interp) && t.Start.Line > f.prevLine:
+		return newline
+	case f.spaced(t, closing, opening):
+		return space
+	}
+	return none
+}
+
+// spaced reports if t is written a space after the last token when they're on the same line
+func (f *formatter) spaced(t lexer.Token, closing *frame, opening *frame) bool {
+	prev := f.prev
+	switch t.Kind {
+	case lexer.COMMA, lexer.SEMI_COLON, lexer.COLON, lexer.DOT, lexer.PLUS_PLUS, lexer.MINUS_MINUS,
+		lexer.INTERP_MIDDLE, lexer.INTERP_END, lexer.CLOSE_BRACKET, lexer.CLOSE_SQUARE_BRACKET:
+		return false
+	}
+	switch {
+	case closing != nil || f.opened != nil && f.opened.kind != block:
+		return false
+	case prev.Kind == lexer.OPEN_BRACKET || prev.Kind == lexer.OPEN_SQUARE_BRACKET || prev.Kind == lexer.DOT ||
+		prev.Kind == lexer.INTERP_START || prev.Kind == lexer.INTERP_MIDDLE:
+		return false
+	case f.prefix:
+		// `- -x`, not `--x`
+		return prev.Kind == lexer.DASH && strings.HasPrefix(t.Value, "-")
+	case opening != nil && opening.kind == typeParams:
+		return false
+	case t.Kind == lexer.OPEN_BRACKET:
+		return !(prev.Kind == lexer.IDENTIFIER || prev.Kind == lexer.FUN || prev.Kind == lexer.CLOSE_BRACKET ||
+			prev.Kind == lexer.CLOSE_SQUARE_BRACKET || f.closed != nil && f.closed.kind == typeParams)
+	case t.Kind == lexer.OPEN_SQUARE_BRACKET:
+		return !(prev.Kind == lexer.IDENTIFIER || prev.Kind == lexer.TYPE || prev.Kind == lexer.STRING ||
+			prev.Kind == lexer.INTERP_END || prev.Kind == lexer.CLOSE_BRACKET ||
+			prev.Kind == lexer.CLOSE_SQUARE_BRACKET || f.closed != nil && f.closed.kind == literal)
+	case opening != nil && opening.kind == literal:
+		// `Point{x: 1}`
+		return prev.Kind != lexer.IDENTIFIER
+	}
+	return true
+}
+
+// operand reports if the token before the last one written ends an operand, making a `-` after it a subtraction
+func (f *formatter) operand() bool {
+	if f.prev == nil {
+		return false
+	}
+	switch f.prev.Kind {
+	case lexer.IDENTIFIER, lexer.INT, lexer.FLOAT, lexer.STRING, lexer.CHAR, lexer.BOOL, lexer.INTERP_END,
+		lexer.CLOSE_BRACKET, lexer.CLOSE_SQUARE_BRACKET, lexer.PLUS_PLUS, lexer.MINUS_MINUS:
+		return true
+	case lexer.CLOSE_CURLY_BRACKET:
+		return f.closed != nil && f.closed.kind == literal
+	}
+	return false
+}
+
+// same checks that formatting didn't change the tokens of the program or drop a comment
+func same(tokens []lexer.Token, out string) error {
+	formatted, err := lexer.Tokenizer(out)
+	if err != nil || len(formatted) != len(tokens) {
+		return diagnostic.New(lexer.Position{}, "formatting changed the program")
+	}
+	for i, a := range tokens {
+		b := formatted[i]
+		if a.Kind != b.Kind || a.Kind != lexer.ELSE_IF && a.Value != b.Value || len(a.Trivia) != len(b.Trivia) {
+			return diagnostic.New(a.Start, "formatting changed the program at `"+a.Value+"`")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+package code
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Code: the bytecode the compiler turns a program into and the vm runs, an instruction is an opcode followed by
+// its operands, each of them 1 or 2 bytes wide (big endian)
+// ------------------------------------------------------------------------------------------------------------------
+type (
+	Instructions []byte
+	Opcode       byte
+)
+
+const (
+	OpConstant Opcode = iota // push constant
+	OpTrue
+	OpFalse
+	OpNull
+	OpPop
+	OpDup  // push the value on top again
+	OpDup2 // push the two values on top again, in the same order
+
+	OpGetLocal // slot
+	OpSetLocal // slot, pops the value
+	OpNewCell  // slot, pops the value and puts it in a new cell, for a variable a function literal captures
+	OpGetCell  // slot
+	OpSetCell  // slot, pops the value
+	OpLoadCell // slot, pushes the cell itself, to be captured by OpClosure
+	OpGetFree  // index
+	OpSetFree  // index, pops the value
+	OpLoadFree // index, pushes the cell itself, to be captured again by a nested OpClosure
+
+	OpClosure      // function, type constant, number of captured cells (on the stack)
+	OpCall         // number of arguments, the closure is right below them
+	OpCallFunction // function, number of arguments
+	OpCallBuiltin  // name constant, number of arguments
+	OpReturnValue
+	OpReturn
+
+	OpJump        // address
+	OpJumpNotTrue // address, pops the condition
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpBitAnd
+	OpBitOr
+	OpAnd
+	OpOr
+	OpEqual
+	OpNotEqual
+	OpGreater
+	OpLess
+	OpGreaterEqual
+	OpLessEqual
+	OpMinus
+	OpBang
+	OpInc
+	OpDec
+
+	OpArray       // number of elements
+	OpHashMap     // number of pairs, the key of each pair is pushed before its value
+	OpStruct      // prototype constant
+	OpEnum        // prototype constant, number of values
+	OpInterpolate // parts constant, number of values
+	OpIndex
+	OpSetIndex // keep, pops the holder, the index and the value, pushes the value back if keep is 1
+	OpGetField // index
+	OpSetField // index, keep, pops the struct and the value, pushes the value back if keep is 1
+	OpUnpack   // index, pops an array and pushes its element at index
+
+	OpIterStart // number of variables, pops the array, string or hashmap a for-each loop goes over
+	OpIterNext  // slot of the iterator, address to jump to when it's done
+	OpIsVariant // variant index, pops the enum value
+	OpVariantValue
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+	OpNull:     {"OpNull", []int{}},
+	OpPop:      {"OpPop", []int{}},
+	OpDup:      {"OpDup", []int{}},
+	OpDup2:     {"OpDup2", []int{}},
+
+	OpGetLocal: {"OpGetLocal", []int{2}},
+	OpSetLocal: {"OpSetLocal", []int{2}},
+	OpNewCell:  {"OpNewCell", []int{2}},
+	OpGetCell:  {"OpGetCell", []int{2}},
+	OpSetCell:  {"OpSetCell", []int{2}},
+	OpLoadCell: {"OpLoadCell", []int{2}},
+	OpGetFree:  {"OpGetFree", []int{1}},
+	OpSetFree:  {"OpSetFree", []int{1}},
+	OpLoadFree: {"OpLoadFree", []int{1}},
+
+	OpClosure:      {"OpClosure", []int{2, 2, 1}},
+	OpCall:         {"OpCall", []int{1}},
+	OpCallFunction: {"OpCallFunction", []int{2, 1}},
+	OpCallBuiltin:  {"OpCallBuiltin", []int{2, 1}},
+	OpReturnValue:  {"OpReturnValue", []int{}},
+	OpReturn:       {"OpReturn", []int{}},
+
+	OpJump:        {"OpJump", []int{2}},
+	OpJumpNotTrue: {"OpJumpNotTrue", []int{2}},
+
+	OpAdd:          {"OpAdd", []int{}},
+	OpSub:          {"OpSub", []int{}},
+	OpMul:          {"OpMul", []int{}},
+	OpDiv:          {"OpDiv", []int{}},
+	OpMod:          {"OpMod", []int{}},
+	OpBitAnd:       {"OpBitAnd", []int{}},
+	OpBitOr:        {"OpBitOr", []int{}},
+	OpAnd:          {"OpAnd", []int{}},
+	OpOr:           {"OpOr", []int{}},
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpGreater:      {"OpGreater", []int{}},
+	OpLess:         {"OpLess", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpMinus:        {"OpMinus", []int{}},
+	OpBang:         {"OpBang", []int{}},
+	OpInc:          {"OpInc", []int{}},
+	OpDec:          {"OpDec", []int{}},
+
+	OpArray:       {"OpArray", []int{2}},
+	OpHashMap:     {"OpHashMap", []int{2}},
+	OpStruct:      {"OpStruct", []int{2}},
+	OpEnum:        {"OpEnum", []int{2, 1}},
+	OpInterpolate: {"OpInterpolate", []int{2, 1}},
+	OpIndex:       {"OpIndex", []int{}},
+	OpSetIndex:    {"OpSetIndex", []int{1}},
+	OpGetField:    {"OpGetField", []int{2}},
+	OpSetField:    {"OpSetField", []int{2, 1}},
+	OpUnpack:      {"OpUnpack", []int{1}},
+
+	OpIterStart:    {"OpIterStart", []int{1}},
+	OpIterNext:     {"OpIterNext", []int{2, 2}},
+	OpIsVariant:    {"OpIsVariant", []int{2}},
+	OpVariantValue: {"OpVariantValue", []int{1}},
+}
+
+// Operators maps the infix operators of the language to their opcodes
+var Operators = map[string]Opcode{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"%":  OpMod,
+	"&":  OpBitAnd,
+	"|":  OpBitOr,
+	"&&": OpAnd,
+	"||": OpOr,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	">":  OpGreater,
+	"<":  OpLess,
+	">=": OpGreaterEqual,
+	"<=": OpLessEqual,
+}
+
+var operators [256]string
+
+func init() {
+	for s, op := range Operators {
+		operators[op] = s
+	}
+}
+
+// OperatorOf is the infix operator of an opcode between OpAdd and OpLessEqual
+func OperatorOf(op Opcode) string { return operators[op] }
+
+func Lookup(op Opcode) (*Definition, error) {
+	def, ok := definitions[op]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes an instruction
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+
+	ins := make([]byte, length)
+	ins[0] = byte(op)
+	offset := 1
+	for i, o := range operands {
+		switch def.OperandWidths[i] {
+		case 2:
+			binary.BigEndian.PutUint16(ins[offset:], uint16(o))
+		case 1:
+			ins[offset] = byte(o)
+		}
+		offset += def.OperandWidths[i]
+	}
+	return ins
+}
+
+// CheckOperands returns an error if an operand of op is too large for its width, Make would cut it
+func CheckOperands(op Opcode, operands ...int) error {
+	def, err := Lookup(op)
+	if err != nil {
+		return err
+	}
+	for i, o := range operands {
+		max := 1<<(8*def.OperandWidths[i]) - 1
+		if o < 0 || o > max {
+			return fmt.Errorf("operand %d of %s is too large for the vm, at most %d fits", o, def.Name, max)
+		}
+	}
+	return nil
+}
+
+// ReadOperands decodes the operands of an instruction, it also returns how many bytes they take
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+	for i, w := range def.OperandWidths {
+		switch w {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ins[offset])
+		}
+		offset += w
+	}
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 { return binary.BigEndian.Uint16(ins) }
+
+func (ins Instructions) String() string {
+	var out strings.Builder
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(Opcode(ins[i]))
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s", i, def.Name)
+		for _, o := range operands {
+			fmt.Fprintf(&out, " %d", o)
+		}
+		out.WriteString("\n")
+		i += 1 + read
+	}
+	return out.String()
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Function: a compiled function, Positions maps the offset of an instruction to the place in the source it came
+// from, for the errors the vm runs into
+// ------------------------------------------------------------------------------------------------------------------
+type Function struct {
+	Name         string
+	File         string // the module the function is declared in, empty for the file being run
+	Instructions Instructions
+	NumParams    int
+	NumLocals    int
+	Positions    []Position
+}
+
+type Position struct {
+	Offset int
+	Pos    lexer.Position
+}
+
+// PosAt returns the position of the instruction at offset
+func (f *Function) PosAt(offset int) lexer.Position {
+	i := sort.Search(len(f.Positions), func(i int) bool { return f.Positions[i].Offset > offset })
+	if i == 0 {
+		return lexer.Position{}
+	}
+	return f.Positions[i-1].Pos
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Bytecode: the functions of a program (including the ones of the modules it imports) and its constants, Main is
+// the index of the `main` function, -1 if there is none. Names are the indexes of the functions the program can
+// call by name, the ones it declares and the ones it imports as `module.name`
+// ------------------------------------------------------------------------------------------------------------------
+type Bytecode struct {
+	Functions []*Function
+	Constants []object.Object
+	Main      int
+	Names     map[string]int
+}
+
+// StructPrototype is the constant OpStruct builds a struct from
+type StructPrototype struct {
+	Name   string
+	Fields []string
+}
+
+func (sp *StructPrototype) Inspect() string         { return sp.Name }
+func (sp *StructPrototype) Type() object.ObjectType { return object.STRUCT_OBJ }
+
+// EnumPrototype is the constant OpEnum builds an enum value from
+type EnumPrototype struct {
+	Enum    string
+	Variant string
+	Index   int
+}
+
+func (ep *EnumPrototype) Inspect() string         { return ep.Enum + "." + ep.Variant }
+func (ep *EnumPrototype) Type() object.ObjectType { return object.ENUM_OBJ }
+
+// Parts is the constant OpInterpolate puts the values of an interpolated string between
+type Parts struct {
+	Values []string
+}
+
+func (p *Parts) Inspect() string         { return strings.Join(p.Values, "{}") }
+func (p *Parts) Type() object.ObjectType { return object.STRING_OBJ }
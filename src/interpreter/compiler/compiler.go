@@ -0,0 +1,344 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/code"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Compiler: turns a type checked program into bytecode for the vm. the scopes follow the environments of the
+// evaluator, but a variable is a slot in the frame of its function instead of an entry in a map, only the ones a
+// function literal captures are kept in a cell, so the closure and the function share them
+// ------------------------------------------------------------------------------------------------------------------
+type Compiler struct {
+	constants []object.Object
+	ints      map[int64]int
+	strs      map[string]int
+	functions []*code.Function
+
+	// files that were already compiled, a module imported by more than one file is only compiled once
+	modules map[*ast.Module]*file
+
+	scope *funcScope
+
+	// positions of the nodes being compiled, the innermost one is given to the instructions emitted for it, like
+	// the evaluator gives an error the position of the innermost node it came from
+	positions []lexer.Position
+
+	// the first instruction emitted with an operand too large for it, the function it's in fails to compile
+	err error
+}
+
+// file holds the functions a file can call, the ones it declares and the ones it imports as `module.name`
+type file struct {
+	path      string // empty for the file being run
+	functions map[string]int
+}
+
+type kind int
+
+const (
+	LOCAL kind = iota
+	CELL
+	FREE
+)
+
+type binding struct {
+	kind  kind
+	index int
+}
+
+type loop struct {
+	breaks    []int
+	continues []int
+}
+
+// funcScope is the function being compiled, blocks are its scopes from the outermost one and free are the
+// bindings of the enclosing function a function literal captures, in the order it loads them
+type funcScope struct {
+	fn       *code.Function
+	file     *file
+	outer    *funcScope
+	blocks   []map[string]*binding
+	captured map[string]bool
+	free     []*binding
+	freeIdx  map[string]int
+	loops    []*loop
+}
+
+func New() *Compiler {
+	return &Compiler{
+		ints:    make(map[int64]int),
+		strs:    make(map[string]int),
+		modules: make(map[*ast.Module]*file),
+	}
+}
+
+func (c *Compiler) Compile(program *ast.Program) (*code.Bytecode, error) {
+	f, err := c.compileFile(program, "")
+	if err != nil {
+		return nil, err
+	}
+	main, ok := f.functions["main"]
+	if !ok {
+		main = -1
+	}
+	return &code.Bytecode{Functions: c.functions, Constants: c.constants, Main: main, Names: f.functions}, nil
+}
+
+// compileFile declares the functions of a file before compiling any of them, so a function can call the ones
+// declared after it like it can in the evaluator, which looks them up when they are called
+func (c *Compiler) compileFile(program *ast.Program, path string) (*file, error) {
+	f := &file{path: path, functions: make(map[string]int)}
+	var funcs []*ast.Function
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.Import:
+			if err := c.compileImport(stmt, f); err != nil {
+				return nil, diagnostic.Wrap(stmt.Pos(), err)
+			}
+		case *ast.Function:
+			if _, ok := f.functions[stmt.Name.Value]; !ok {
+				f.functions[stmt.Name.Value] = len(c.functions)
+				c.functions = append(c.functions, &code.Function{Name: stmt.Name.Value, File: path})
+			}
+			funcs = append(funcs, stmt)
+		case *ast.Struct, *ast.Enum:
+		default:
+			return nil, diagnostic.Wrap(stmt.Pos(), errors.New("the vm can only run declarations outside of a function"))
+		}
+	}
+	for _, fn := range funcs {
+		// a function declared ahead with `;` has no body, the declaration with the body comes later
+		if fn.Body == nil {
+			continue
+		}
+		target := &code.Function{Name: fn.Name.Value, File: path}
+		c.functions[f.functions[fn.Name.Value]] = target
+		if _, err := c.compileFunctionScope(fn, target, f, nil); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (c *Compiler) compileImport(i *ast.Import, f *file) error {
+	m, ok := c.modules[i.Module]
+	if !ok {
+		var err error
+		m, err = c.compileFile(i.Module.Program, i.Module.Path)
+		if err != nil {
+			return diagnostic.WithFile(err, i.Module.Path)
+		}
+		c.modules[i.Module] = m
+	}
+	for name, idx := range m.functions {
+		if ast.Exported(name) {
+			f.functions[i.Module.Name+"."+name] = idx
+		}
+	}
+	return nil
+}
+
+// compileFunctionScope compiles the body of fn into target, outer is the function around a function literal
+func (c *Compiler) compileFunctionScope(fn *ast.Function,
+	target *code.Function,
+	f *file,
+	outer *funcScope,
+) (*funcScope, error) {
+	s := &funcScope{
+		fn:       target,
+		file:     f,
+		outer:    outer,
+		captured: capturedNames(fn.Body),
+		freeIdx:  make(map[string]int),
+	}
+	prev := c.scope
+	c.scope = s
+	defer func() { c.scope = prev }()
+
+	target.NumParams = len(fn.Parameters)
+	c.enterBlock()
+	for _, param := range fn.Parameters {
+		b := c.declare(param.ParameterName.Value)
+		if b.kind == CELL {
+			c.emit(code.OpGetLocal, b.index)
+			c.emit(code.OpNewCell, b.index)
+		}
+	}
+	if err := c.compileStmts(fn.Body.Statements); err != nil {
+		return nil, err
+	}
+	c.leaveBlock()
+	c.emit(code.OpReturn)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return s, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Scopes
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) enterBlock() {
+	c.scope.blocks = append(c.scope.blocks, make(map[string]*binding))
+}
+
+func (c *Compiler) leaveBlock() {
+	c.scope.blocks = c.scope.blocks[:len(c.scope.blocks)-1]
+}
+
+// newSlot returns a slot of the current function no variable is using
+func (c *Compiler) newSlot() int {
+	c.scope.fn.NumLocals++
+	return c.scope.fn.NumLocals - 1
+}
+
+// declare gives name a new slot in the innermost block, it's a cell if a function literal in the function uses it
+func (c *Compiler) declare(name string) *binding {
+	b := &binding{kind: LOCAL, index: c.newSlot()}
+	if c.scope.captured[name] {
+		b.kind = CELL
+	}
+	c.scope.blocks[len(c.scope.blocks)-1][name] = b
+	return b
+}
+
+// declareValue declares name with the value on top of the stack
+func (c *Compiler) declareValue(name string) {
+	b := c.declare(name)
+	if b.kind == CELL {
+		c.emit(code.OpNewCell, b.index)
+	} else {
+		c.emit(code.OpSetLocal, b.index)
+	}
+}
+
+func (c *Compiler) resolve(name string) (*binding, bool) {
+	return c.scope.resolve(name)
+}
+
+func (s *funcScope) resolve(name string) (*binding, bool) {
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		if b, ok := s.blocks[i][name]; ok {
+			return b, true
+		}
+	}
+	if i, ok := s.freeIdx[name]; ok {
+		return &binding{kind: FREE, index: i}, true
+	}
+	if s.outer == nil {
+		return nil, false
+	}
+	b, ok := s.outer.resolve(name)
+	if !ok || b.kind == LOCAL {
+		return nil, false
+	}
+	s.free = append(s.free, b)
+	s.freeIdx[name] = len(s.free) - 1
+	return &binding{kind: FREE, index: len(s.free) - 1}, true
+}
+
+func (c *Compiler) load(b *binding) {
+	switch b.kind {
+	case LOCAL:
+		c.emit(code.OpGetLocal, b.index)
+	case CELL:
+		c.emit(code.OpGetCell, b.index)
+	default:
+		c.emit(code.OpGetFree, b.index)
+	}
+}
+
+func (c *Compiler) store(b *binding) {
+	switch b.kind {
+	case LOCAL:
+		c.emit(code.OpSetLocal, b.index)
+	case CELL:
+		c.emit(code.OpSetCell, b.index)
+	default:
+		c.emit(code.OpSetFree, b.index)
+	}
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Emit
+// ------------------------------------------------------------------------------------------------------------------
+
+// at runs compile with the instructions it emits getting the position of node
+func (c *Compiler) at(node ast.Node, compile func() error) error {
+	pos := node.Pos()
+	if !pos.IsValid() {
+		return compile()
+	}
+	c.positions = append(c.positions, pos)
+	err := compile()
+	c.positions = c.positions[:len(c.positions)-1]
+	return diagnostic.Wrap(pos, err)
+}
+
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	fn := c.scope.fn
+	offset := len(fn.Instructions)
+	if len(c.positions) != 0 {
+		pos := c.positions[len(c.positions)-1]
+		if n := len(fn.Positions); n == 0 || fn.Positions[n-1].Pos != pos {
+			fn.Positions = append(fn.Positions, code.Position{Offset: offset, Pos: pos})
+		}
+	}
+	if err := code.CheckOperands(op, operands...); err != nil && c.err == nil {
+		c.err = err
+		if len(c.positions) != 0 {
+			c.err = diagnostic.Wrap(c.positions[len(c.positions)-1], err)
+		}
+	}
+	fn.Instructions = append(fn.Instructions, code.Make(op, operands...)...)
+	return offset
+}
+
+// patch points the jump at offset to the next instruction
+func (c *Compiler) patch(offset int) error {
+	return c.patchTo(offset, len(c.scope.fn.Instructions))
+}
+
+func (c *Compiler) patchTo(offset, address int) error {
+	if address > 0xFFFF {
+		return fmt.Errorf("function `%s` is too long for the vm", c.scope.fn.Name)
+	}
+	ins := c.scope.fn.Instructions
+	op := code.Opcode(ins[offset])
+	operand := offset + 1
+	if op == code.OpIterNext {
+		operand += 2
+	}
+	ins[operand] = byte(address >> 8)
+	ins[operand+1] = byte(address)
+	return nil
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+func (c *Compiler) intConstant(v int64) int {
+	if i, ok := c.ints[v]; ok {
+		return i
+	}
+	c.ints[v] = c.addConstant(&object.Integer{Value: v})
+	return c.ints[v]
+}
+
+func (c *Compiler) strConstant(v string) int {
+	if i, ok := c.strs[v]; ok {
+		return i
+	}
+	c.strs[v] = c.addConstant(&object.String{Value: v})
+	return c.strs[v]
+}
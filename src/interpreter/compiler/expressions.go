@@ -0,0 +1,395 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/code"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Expressions: each of them leaves exactly one value on the stack, nil for a call to a function returning nothing
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileExpression(e ast.Expression) error {
+	return c.at(e, func() error {
+		switch e := e.(type) {
+		case *ast.Identifier:
+			return c.compileIdentifier(e)
+		case *ast.Integer:
+			c.emit(code.OpConstant, c.intConstant(e.Value))
+		case *ast.Float:
+			c.emit(code.OpConstant, c.addConstant(&object.Float{Value: e.Value}))
+		case *ast.Bool:
+			if e.Value {
+				c.emit(code.OpTrue)
+			} else {
+				c.emit(code.OpFalse)
+			}
+		case *ast.String:
+			c.emit(code.OpConstant, c.strConstant(e.Value))
+		case *ast.Char:
+			c.emit(code.OpConstant, c.addConstant(&object.Char{Value: e.Value}))
+		case *ast.InterpolatedString:
+			if err := c.compileExpressions(e.Expressions); err != nil {
+				return err
+			}
+			c.emit(code.OpInterpolate, c.addConstant(&code.Parts{Values: e.Parts}), len(e.Expressions))
+		case *ast.HashMap:
+			for _, p := range e.Pairs {
+				if err := c.compileExpression(p.Key); err != nil {
+					return err
+				}
+				if err := c.compileExpression(p.Value); err != nil {
+					return err
+				}
+			}
+			c.emit(code.OpHashMap, len(e.Pairs))
+		case *ast.Array:
+			if err := c.compileExpressions(e.Values); err != nil {
+				return err
+			}
+			c.emit(code.OpArray, len(e.Values))
+		case *ast.StructLiteral:
+			if err := c.compileExpressions(e.Values); err != nil {
+				return err
+			}
+			c.emit(code.OpStruct, c.addConstant(&code.StructPrototype{Name: e.Type.Name, Fields: e.Fields}))
+		case *ast.EnumValue:
+			if err := c.compileExpressions(e.Args); err != nil {
+				return err
+			}
+			proto := &code.EnumPrototype{Enum: e.Type.Name, Variant: e.Variant.Value, Index: e.Index}
+			c.emit(code.OpEnum, c.addConstant(proto), len(e.Args))
+		case *ast.FieldAccess:
+			if err := c.compileExpression(e.Left); err != nil {
+				return err
+			}
+			c.emit(code.OpGetField, e.Index)
+		case *ast.FunctionLiteral:
+			return c.compileFunctionLiteral(e)
+		case *ast.Prefix:
+			if err := c.compileExpression(e.Right); err != nil {
+				return err
+			}
+			if e.Operator == "!" {
+				c.emit(code.OpBang)
+			} else {
+				c.emit(code.OpMinus)
+			}
+		case *ast.Infix:
+			if err := c.compileExpression(e.Left); err != nil {
+				return err
+			}
+			if err := c.compileExpression(e.Right); err != nil {
+				return err
+			}
+			c.emit(code.Operators[e.Operator])
+		case *ast.Postfix:
+			return c.compilePostfix(e, true)
+		case *ast.Assignment:
+			return c.compileAssignment(e, true)
+		case *ast.IndexExpression:
+			if err := c.compileExpression(e.Left); err != nil {
+				return err
+			}
+			if err := c.compileExpression(e.Index); err != nil {
+				return err
+			}
+			c.emit(code.OpIndex)
+		case *ast.CallExpression:
+			return c.compileCall(e)
+		default:
+			return fmt.Errorf("no compile function for given node type, got: %T", e)
+		}
+		return nil
+	})
+}
+
+func (c *Compiler) compileExpressions(es []ast.Expression) error {
+	for _, e := range es {
+		if err := c.compileExpression(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Identifier: a variable, or a declared function used as a value
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileIdentifier(i *ast.Identifier) error {
+	if i.Index == ast.Unresolved {
+		return ast.UnresolvedError(i)
+	}
+	if b, ok := c.resolve(i.Value); ok {
+		c.load(b)
+		return nil
+	}
+	if fn, ok := c.scope.file.functions[i.Value]; ok {
+		c.emit(code.OpClosure, fn, c.strConstant(i.Type.String()), 0)
+		return nil
+	}
+	return fmt.Errorf("identifier not found: %s", i.Value)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// FunctionLiteral: the cells of the variables it captures are loaded for OpClosure to keep
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileFunctionLiteral(f *ast.FunctionLiteral) error {
+	fn := &code.Function{Name: "<function literal>", File: c.scope.file.path}
+	idx := len(c.functions)
+	c.functions = append(c.functions, fn)
+
+	s, err := c.compileFunctionScope(f.Function, fn, c.scope.file, c.scope)
+	if err != nil {
+		return err
+	}
+	for _, b := range s.free {
+		if b.kind == CELL {
+			c.emit(code.OpLoadCell, b.index)
+		} else {
+			c.emit(code.OpLoadFree, b.index)
+		}
+	}
+	c.emit(code.OpClosure, idx, c.strConstant(f.Type.String()), len(s.free))
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// CallExpression
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileCall(call *ast.CallExpression) error {
+	if call.Name == nil {
+		if err := c.compileExpression(call.Callee); err != nil {
+			return err
+		}
+		if err := c.compileExpressions(call.Args); err != nil {
+			return err
+		}
+		c.emit(code.OpCall, len(call.Args))
+		return nil
+	}
+
+	if err := c.compileExpressions(call.Args); err != nil {
+		return err
+	}
+	name := call.Name.Value
+	if fn, ok := c.scope.file.functions[name]; ok {
+		c.emit(code.OpCallFunction, fn, len(call.Args))
+	} else if name == "typeOf" {
+		// the type of the argument is known before the program runs
+		c.emit(code.OpPop)
+		c.emit(code.OpConstant, c.strConstant(call.Args[0].GetType().Types[0].String()))
+	} else {
+		c.emit(code.OpCallBuiltin, c.strConstant(name), len(call.Args))
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Assignment: keep leaves the assigned value on the stack, for an assignment used as a value
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileAssignment(a *ast.Assignment, keep bool) error {
+	if a.Operator == "=" {
+		return c.compileAssign(a, nil, keep)
+	}
+
+	op := code.Operators[strings.TrimSuffix(a.Operator, "=")]
+	switch left := a.Left.(type) {
+	case *ast.Identifier:
+		if left.Index == ast.Unresolved {
+			return ast.UnresolvedError(left)
+		}
+		b, ok := c.resolve(left.Value)
+		if !ok {
+			return fmt.Errorf("identifier not found: %s", left.Value)
+		}
+		c.load(b)
+		if err := c.compileExpression(a.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+		if keep {
+			c.emit(code.OpDup)
+		}
+		c.store(b)
+	case *ast.IndexExpression:
+		if err := c.compileExpressions([]ast.Expression{left.Left, left.Index}); err != nil {
+			return err
+		}
+		c.emit(code.OpDup2)
+		c.emit(code.OpIndex)
+		if err := c.compileExpression(a.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+		c.emit(code.OpSetIndex, flag(keep))
+	case *ast.FieldAccess:
+		if err := c.compileExpression(left.Left); err != nil {
+			return err
+		}
+		c.emit(code.OpDup)
+		c.emit(code.OpGetField, left.Index)
+		if err := c.compileExpression(a.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+		c.emit(code.OpSetField, left.Index, flag(keep))
+	default:
+		return fmt.Errorf("can't assign to %T", a.Left)
+	}
+	return nil
+}
+
+// compileAssign compiles a `=` assignment, the value comes from value if it isn't nil, or from the right side. the
+// evaluator gets the value before the parts of the left side, so unless neither can change what the other gives
+// the value waits in a hidden slot while they are compiled
+func (c *Compiler) compileAssign(a *ast.Assignment, value func() error, keep bool) error {
+	injected := value != nil
+	if !injected {
+		value = func() error { return c.compileExpression(a.Right) }
+	}
+
+	var parts []ast.Expression
+	switch left := a.Left.(type) {
+	case *ast.Identifier:
+		if left.Index == ast.Unresolved {
+			return ast.UnresolvedError(left)
+		}
+		b, ok := c.resolve(left.Value)
+		if !ok {
+			return fmt.Errorf("identifier not found: %s", left.Value)
+		}
+		if err := value(); err != nil {
+			return err
+		}
+		if keep {
+			c.emit(code.OpDup)
+		}
+		c.store(b)
+		return nil
+	case *ast.IndexExpression:
+		parts = []ast.Expression{left.Left, left.Index}
+	case *ast.FieldAccess:
+		parts = []ast.Expression{left.Left}
+	default:
+		return fmt.Errorf("can't assign to %T", a.Left)
+	}
+
+	inOrder := injected || safe(a.Right)
+	for _, part := range parts {
+		inOrder = inOrder && pure(part)
+	}
+	if inOrder {
+		if err := c.compileExpressions(parts); err != nil {
+			return err
+		}
+		if err := value(); err != nil {
+			return err
+		}
+	} else {
+		if err := value(); err != nil {
+			return err
+		}
+		tmp := c.newSlot()
+		c.emit(code.OpSetLocal, tmp)
+		if err := c.compileExpressions(parts); err != nil {
+			return err
+		}
+		c.emit(code.OpGetLocal, tmp)
+	}
+
+	if left, ok := a.Left.(*ast.FieldAccess); ok {
+		c.emit(code.OpSetField, left.Index, flag(keep))
+	} else {
+		c.emit(code.OpSetIndex, flag(keep))
+	}
+	return nil
+}
+
+// pure reports if e can neither fail nor change a variable, so it gives the same value whenever it's run
+func pure(e ast.Expression) bool {
+	switch e := e.(type) {
+	case *ast.Identifier, *ast.Integer, *ast.Float, *ast.Bool, *ast.String, *ast.Char:
+		return true
+	case *ast.FieldAccess:
+		return pure(e.Left)
+	}
+	return false
+}
+
+// safe reports if e can't change a variable, it can still fail
+func safe(e ast.Expression) bool {
+	switch e := e.(type) {
+	case *ast.Prefix:
+		return safe(e.Right)
+	case *ast.Infix:
+		return safe(e.Left) && safe(e.Right)
+	case *ast.IndexExpression:
+		return safe(e.Left) && safe(e.Index)
+	case *ast.FieldAccess:
+		return safe(e.Left)
+	}
+	return pure(e)
+}
+
+func flag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Postfix: gives the new value, like the evaluator
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compilePostfix(p *ast.Postfix, keep bool) error {
+	op := code.OpInc
+	if p.Operator == "--" {
+		op = code.OpDec
+	}
+
+	switch left := p.Left.(type) {
+	case *ast.Identifier:
+		if left.Index == ast.Unresolved {
+			return ast.UnresolvedError(left)
+		}
+		b, ok := c.resolve(left.Value)
+		if !ok {
+			return fmt.Errorf("identifier not found: %s", left.Value)
+		}
+		c.load(b)
+		c.emit(op)
+		if keep {
+			c.emit(code.OpDup)
+		}
+		c.store(b)
+	case *ast.IndexExpression:
+		if err := c.compileExpressions([]ast.Expression{left.Left, left.Index}); err != nil {
+			return err
+		}
+		c.emit(code.OpDup2)
+		c.emit(code.OpIndex)
+		c.emit(op)
+		c.emit(code.OpSetIndex, flag(keep))
+	case *ast.FieldAccess:
+		if err := c.compileExpression(left.Left); err != nil {
+			return err
+		}
+		c.emit(code.OpDup)
+		c.emit(code.OpGetField, left.Index)
+		c.emit(op)
+		c.emit(code.OpSetField, left.Index, flag(keep))
+	default:
+		if err := c.compileExpression(p.Left); err != nil {
+			return err
+		}
+		c.emit(op)
+		if !keep {
+			c.emit(code.OpPop)
+		}
+	}
+	return nil
+}
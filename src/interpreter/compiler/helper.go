@@ -0,0 +1,141 @@
+package compiler
+
+import (
+	"github.com/KhushPatibandha/Kolon/src/ast"
+)
+
+// capturedNames returns the names used inside the function literals in body, the variables of the function with
+// those names are kept in cells, so the closures share them with the function instead of getting a copy
+func capturedNames(body *ast.Body) map[string]bool {
+	names := map[string]bool{}
+	inLiteral := 0
+	var visit func(node ast.Node)
+	visit = func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			inLiteral++
+			walk(node.Function.Body, visit)
+			inLiteral--
+			return
+		case *ast.Identifier:
+			if inLiteral > 0 {
+				names[node.Value] = true
+			}
+		}
+		walk(node, visit)
+	}
+	walk(body, visit)
+	return names
+}
+
+// walk calls visit on each of the nodes right under node, visit decides if it goes further down
+func walk(node ast.Node, visit func(ast.Node)) {
+	switch node := node.(type) {
+	case *ast.Body:
+		if node == nil {
+			return
+		}
+		for _, stmt := range node.Statements {
+			visit(stmt)
+		}
+	case *ast.InterpolatedString:
+		for _, e := range node.Expressions {
+			visit(e)
+		}
+	case *ast.HashMap:
+		for _, p := range node.Pairs {
+			visit(p.Key)
+			visit(p.Value)
+		}
+	case *ast.Array:
+		for _, e := range node.Values {
+			visit(e)
+		}
+	case *ast.StructLiteral:
+		for _, e := range node.Values {
+			visit(e)
+		}
+	case *ast.EnumValue:
+		for _, e := range node.Args {
+			visit(e)
+		}
+	case *ast.FieldAccess:
+		visit(node.Left)
+	case *ast.FunctionLiteral:
+		visit(node.Function.Body)
+	case *ast.Prefix:
+		visit(node.Right)
+	case *ast.Infix:
+		visit(node.Left)
+		visit(node.Right)
+	case *ast.Postfix:
+		visit(node.Left)
+	case *ast.Assignment:
+		visit(node.Left)
+		visit(node.Right)
+	case *ast.CallExpression:
+		if node.Name != nil {
+			visit(node.Name)
+		} else {
+			visit(node.Callee)
+		}
+		for _, e := range node.Args {
+			visit(e)
+		}
+	case *ast.IndexExpression:
+		visit(node.Left)
+		visit(node.Index)
+	case *ast.ExpressionStatement:
+		visit(node.Expression)
+	case *ast.BareExpression:
+		visit(node.Expression)
+	case *ast.VarAndConst:
+		visit(node.Name)
+		if node.Value != nil {
+			visit(node.Value)
+		}
+	case *ast.MultiAssignment:
+		for _, stmt := range node.Objects {
+			visit(stmt)
+		}
+	case *ast.Return:
+		for _, e := range node.Value {
+			visit(e)
+		}
+	case *ast.If:
+		visit(node.Condition)
+		visit(node.Body)
+		for _, ei := range node.MultiConditionals {
+			visit(ei.Condition)
+			visit(ei.Body)
+		}
+		if node.Alternate != nil {
+			visit(node.Alternate.Body)
+		}
+	case *ast.Match:
+		visit(node.Value)
+		for _, arm := range node.Arms {
+			for _, b := range arm.Bindings {
+				visit(b)
+			}
+			visit(arm.Body)
+		}
+		if node.Alternate != nil {
+			visit(node.Alternate.Body)
+		}
+	case *ast.ForLoop:
+		visit(node.Left)
+		visit(node.Middle)
+		visit(node.Right)
+		visit(node.Body)
+	case *ast.ForEach:
+		for _, v := range node.Vars {
+			visit(v)
+		}
+		visit(node.Iterable)
+		visit(node.Body)
+	case *ast.WhileLoop:
+		visit(node.Condition)
+		visit(node.Body)
+	}
+}
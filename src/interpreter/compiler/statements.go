@@ -0,0 +1,363 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/code"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Statements
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileStmts(stmts []ast.Statement) error {
+	for _, stmt := range stmts {
+		if err := c.compileStmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileBody compiles body in a block of its own, like the evaluator runs it in an enclosed environment
+func (c *Compiler) compileBody(body *ast.Body) error {
+	c.enterBlock()
+	defer c.leaveBlock()
+	return c.compileStmts(body.Statements)
+}
+
+func (c *Compiler) compileStmt(stmt ast.Statement) error {
+	return c.at(stmt, func() error {
+		switch stmt := stmt.(type) {
+		case *ast.ExpressionStatement:
+			return c.compileExpressionStatement(stmt.Expression)
+		case *ast.BareExpression:
+			return c.compileExpressionStatement(stmt.Expression)
+		case *ast.VarAndConst:
+			return c.compileVarConst(stmt)
+		case *ast.MultiAssignment:
+			return c.compileMultiAssign(stmt)
+		case *ast.Return:
+			return c.compileReturn(stmt)
+		case *ast.If:
+			return c.compileIf(stmt)
+		case *ast.Match:
+			return c.compileMatch(stmt)
+		case *ast.ForLoop:
+			return c.compileForLoop(stmt)
+		case *ast.ForEach:
+			return c.compileForEach(stmt)
+		case *ast.WhileLoop:
+			return c.compileWhileLoop(stmt)
+		case *ast.Continue:
+			l := c.scope.loops[len(c.scope.loops)-1]
+			l.continues = append(l.continues, c.emit(code.OpJump, 0xFFFF))
+			return nil
+		case *ast.Break:
+			l := c.scope.loops[len(c.scope.loops)-1]
+			l.breaks = append(l.breaks, c.emit(code.OpJump, 0xFFFF))
+			return nil
+		case *ast.Struct, *ast.Enum:
+			return nil
+		default:
+			return fmt.Errorf("no compile function for given node type, got: %T", stmt)
+		}
+	})
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// ExpressionStatement: assignments and postfix operations don't leave their value behind when it isn't used
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileExpressionStatement(e ast.Expression) error {
+	switch e := e.(type) {
+	case *ast.Assignment:
+		return c.at(e, func() error { return c.compileAssignment(e, false) })
+	case *ast.Postfix:
+		return c.at(e, func() error { return c.compilePostfix(e, false) })
+	}
+	if err := c.compileExpression(e); err != nil {
+		return err
+	}
+	c.emit(code.OpPop)
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// VarAndConst
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileVarConst(vc *ast.VarAndConst) error {
+	if vc.Value == nil {
+		c.emit(code.OpNull)
+	} else if err := c.compileExpression(vc.Value); err != nil {
+		return err
+	}
+	c.declareValue(vc.Name.Value)
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Multi-Assignment: the values of a call returning more than one value come in an array, kept in a hidden slot
+// while they are given to the variables one by one
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileMultiAssign(ma *ast.MultiAssignment) error {
+	if !ma.SingleFunctionCall {
+		for _, ele := range ma.Objects {
+			var err error
+			switch ele := ele.(type) {
+			case *ast.VarAndConst:
+				err = c.compileVarConst(ele)
+			case *ast.ExpressionStatement:
+				err = c.compileAssign(ele.Expression.(*ast.Assignment), nil, false)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var call *ast.CallExpression
+	switch v := ma.Objects[0].(type) {
+	case *ast.VarAndConst:
+		call = v.Value.(*ast.CallExpression)
+	case *ast.ExpressionStatement:
+		call = v.Expression.(*ast.Assignment).Right.(*ast.CallExpression)
+	}
+	if err := c.compileCall(call); err != nil {
+		return err
+	}
+	values := c.newSlot()
+	c.emit(code.OpSetLocal, values)
+
+	for i, ele := range ma.Objects {
+		value := func() error {
+			c.emit(code.OpGetLocal, values)
+			c.emit(code.OpUnpack, i)
+			return nil
+		}
+		switch ele := ele.(type) {
+		case *ast.VarAndConst:
+			value()
+			c.declareValue(ele.Name.Value)
+		case *ast.ExpressionStatement:
+			if err := c.compileAssign(ele.Expression.(*ast.Assignment), value, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Return: more than one value goes back in an array, a multi-assignment takes them out of it
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileReturn(r *ast.Return) error {
+	if len(r.Value) == 0 {
+		c.emit(code.OpReturn)
+		return nil
+	}
+	for _, e := range r.Value {
+		if err := c.compileExpression(e); err != nil {
+			return err
+		}
+	}
+	if len(r.Value) > 1 {
+		c.emit(code.OpArray, len(r.Value))
+	}
+	c.emit(code.OpReturnValue)
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// If
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileIf(i *ast.If) error {
+	var ends []int
+	branch := func(condition ast.Expression, body *ast.Body) error {
+		if err := c.compileExpression(condition); err != nil {
+			return err
+		}
+		next := c.emit(code.OpJumpNotTrue, 0xFFFF)
+		if err := c.compileBody(body); err != nil {
+			return err
+		}
+		ends = append(ends, c.emit(code.OpJump, 0xFFFF))
+		return c.patch(next)
+	}
+
+	if err := branch(i.Condition, i.Body); err != nil {
+		return err
+	}
+	for _, ei := range i.MultiConditionals {
+		if err := branch(ei.Condition, ei.Body); err != nil {
+			return err
+		}
+	}
+	if i.Alternate != nil {
+		if err := c.compileBody(i.Alternate.Body); err != nil {
+			return err
+		}
+	}
+	for _, end := range ends {
+		if err := c.patch(end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Match: the enum value is kept in a hidden slot while the arms check which variant it holds
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileMatch(m *ast.Match) error {
+	if err := c.compileExpression(m.Value); err != nil {
+		return err
+	}
+	value := c.newSlot()
+	c.emit(code.OpSetLocal, value)
+
+	var ends []int
+	for _, arm := range m.Arms {
+		c.emit(code.OpGetLocal, value)
+		c.emit(code.OpIsVariant, arm.Index)
+		next := c.emit(code.OpJumpNotTrue, 0xFFFF)
+
+		c.enterBlock()
+		for i, binding := range arm.Bindings {
+			c.emit(code.OpGetLocal, value)
+			c.emit(code.OpVariantValue, i)
+			c.declareValue(binding.Value)
+		}
+		if err := c.compileStmts(arm.Body.Statements); err != nil {
+			return err
+		}
+		c.leaveBlock()
+
+		ends = append(ends, c.emit(code.OpJump, 0xFFFF))
+		if err := c.patch(next); err != nil {
+			return err
+		}
+	}
+	if m.Alternate != nil {
+		if err := c.compileBody(m.Alternate.Body); err != nil {
+			return err
+		}
+	}
+	for _, end := range ends {
+		if err := c.patch(end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Loops: `break` jumps past the loop, `continue` to what runs after the body (the update of a for loop or the
+// condition of a while loop)
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileLoopBody(body *ast.Body, l *loop) error {
+	c.scope.loops = append(c.scope.loops, l)
+	defer func() { c.scope.loops = c.scope.loops[:len(c.scope.loops)-1] }()
+	return c.compileStmts(body.Statements)
+}
+
+func (c *Compiler) patchLoop(l *loop, continueTo int) error {
+	for _, offset := range l.continues {
+		if err := c.patchTo(offset, continueTo); err != nil {
+			return err
+		}
+	}
+	for _, offset := range l.breaks {
+		if err := c.patch(offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// ForLoop
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileForLoop(f *ast.ForLoop) error {
+	c.enterBlock()
+	defer c.leaveBlock()
+
+	if err := c.compileStmt(f.Left); err != nil {
+		return err
+	}
+	start := len(c.scope.fn.Instructions)
+	if err := c.compileExpression(f.Middle); err != nil {
+		return err
+	}
+	exit := c.emit(code.OpJumpNotTrue, 0xFFFF)
+
+	l := &loop{}
+	c.enterBlock()
+	if err := c.compileLoopBody(f.Body, l); err != nil {
+		return err
+	}
+	c.leaveBlock()
+
+	update := len(c.scope.fn.Instructions)
+	if err := c.at(f.Right, func() error { return c.compileExpressionStatement(f.Right) }); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, start)
+	if err := c.patch(exit); err != nil {
+		return err
+	}
+	return c.patchLoop(l, update)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// ForEach: the iterator goes over the items taken from the array, string or hashmap before the loop starts
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileForEach(f *ast.ForEach) error {
+	if err := c.compileExpression(f.Iterable); err != nil {
+		return err
+	}
+	c.emit(code.OpIterStart, len(f.Vars))
+	c.enterBlock()
+	defer c.leaveBlock()
+	iter := c.newSlot()
+	c.emit(code.OpSetLocal, iter)
+
+	start := c.emit(code.OpIterNext, iter, 0xFFFF)
+	l := &loop{}
+	c.enterBlock()
+	for i := len(f.Vars) - 1; i >= 0; i-- {
+		c.declareValue(f.Vars[i].Name.Value)
+	}
+	if err := c.compileLoopBody(f.Body, l); err != nil {
+		return err
+	}
+	c.leaveBlock()
+	c.emit(code.OpJump, start)
+	if err := c.patch(start); err != nil {
+		return err
+	}
+	return c.patchLoop(l, start)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// WhileLoop
+// ------------------------------------------------------------------------------------------------------------------
+func (c *Compiler) compileWhileLoop(w *ast.WhileLoop) error {
+	start := len(c.scope.fn.Instructions)
+	if err := c.compileExpression(w.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(code.OpJumpNotTrue, 0xFFFF)
+
+	l := &loop{}
+	c.enterBlock()
+	if err := c.compileLoopBody(w.Body, l); err != nil {
+		return err
+	}
+	c.leaveBlock()
+	c.emit(code.OpJump, start)
+	if err := c.patch(exit); err != nil {
+		return err
+	}
+	return c.patchLoop(l, start)
+}
@@ -1,20 +1,26 @@
 package evaluator
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/builtins"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	"github.com/KhushPatibandha/Kolon/src/environment"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
 	"github.com/KhushPatibandha/Kolon/src/object"
 )
 
 var (
 	TRUE = &object.EvalResult{
-		Value:  &object.Bool{Value: true},
+		Value:  object.TRUE,
 		Signal: object.SIGNAL_NONE,
 	}
 	FALSE = &object.EvalResult{
-		Value:  &object.Bool{Value: false},
+		Value:  object.FALSE,
 		Signal: object.SIGNAL_NONE,
 	}
 	CONTINUE = &object.EvalResult{
@@ -31,6 +37,22 @@ type Evaluator struct {
 	inTesting bool
 	env       *environment.Environment
 	stack     *environment.Stack
+
+	// global environments of the modules that were already evaluated, shared with the evaluators of the modules
+	modules map[*ast.Module]*environment.Environment
+
+	builtins *builtins.Registry
+	io       *builtins.IO // where `print` writes to and `scan` reads from
+
+	skipMain bool // `main` is only declared, for a host calling another function of the program
+
+	ctx    context.Context
+	limits Limits
+	steps  int64
+	calls  []call // the functions running, `main` first
+
+	file  string                   // the file being evaluated, empty for the file being run
+	files map[*ast.Function]string // files of the functions declared in the modules, shared like modules
 }
 
 // ------------------------------------------------------------------------------------------------------------------
@@ -41,15 +63,60 @@ func New(inTesting bool) *Evaluator {
 		inTesting: inTesting,
 		env:       environment.NewEnvironment(),
 		stack:     environment.NewStack(),
+		modules:   make(map[*ast.Module]*environment.Environment),
+		files:     make(map[*ast.Function]string),
+		builtins:  builtins.New(),
+		io:        builtins.StdIO(),
 	}
 
 	e.stack.Push(e.env)
-	environment.LoadBuiltins(e.env)
+	environment.LoadBuiltins(e.env, e.builtins)
 
 	return e
 }
 
+// SetIO makes `print` write to out and `scan` read from in, instead of the standard output and input
+func (e *Evaluator) SetIO(out io.Writer, in io.Reader) {
+	e.io = &builtins.IO{Out: out, In: bufio.NewReader(in)}
+}
+
+// SetBuiltins makes the builtins of r callable, the registry the program was type checked with
+func (e *Evaluator) SetBuiltins(r *builtins.Registry) {
+	e.builtins = r
+	environment.LoadBuiltins(e.env, r)
+}
+
+// SkipMain keeps `main` from running when it's declared
+func (e *Evaluator) SkipMain() { e.skipMain = true }
+
+// Call runs the function name declared at the top of the program with args, once the program was evaluated
+func (e *Evaluator) Call(name string, args []object.Object) (object.Object, error) {
+	sym, ok := e.env.GetFunc(name)
+	if !ok || sym.Func.Builtin {
+		return nil, fmt.Errorf("function `%s` not found", name)
+	}
+	r, err := e.callFunction(sym.Func.Function, sym.Env, args, lexer.Position{})
+	if err != nil {
+		return nil, err
+	}
+	return r.Value, nil
+}
+
+// Depth is the number of scopes on the stack, only the global one is left between two statements of the top level
+func (e *Evaluator) Depth() int { return e.stack.Len() }
+
 func (e *Evaluator) Evaluate(node ast.Node) (*object.EvalResult, error) {
+	if err := e.step(); err != nil {
+		return nil, e.traced(diagnostic.Wrap(node.Pos(), err))
+	}
+	r, err := e.evaluate(node)
+	if err != nil {
+		return nil, e.traced(diagnostic.Wrap(node.Pos(), err))
+	}
+	return r, nil
+}
+
+func (e *Evaluator) evaluate(node ast.Node) (*object.EvalResult, error) {
 	switch node := node.(type) {
 	case *ast.Program:
 		return e.evalStmts(node.Statements)
@@ -63,6 +130,8 @@ func (e *Evaluator) Evaluate(node ast.Node) (*object.EvalResult, error) {
 		return e.evalBoolean(node)
 	case *ast.String:
 		return e.evalString(node)
+	case *ast.InterpolatedString:
+		return e.evalInterpolatedString(node)
 	case *ast.Char:
 		return e.evalChar(node)
 	case *ast.HashMap:
@@ -81,12 +150,26 @@ func (e *Evaluator) Evaluate(node ast.Node) (*object.EvalResult, error) {
 		return e.evalIndex(node)
 	case *ast.CallExpression:
 		return e.evalCall(node)
+	case *ast.StructLiteral:
+		return e.evalStructLiteral(node)
+	case *ast.FieldAccess:
+		return e.evalFieldAccess(node)
+	case *ast.EnumValue:
+		return e.evalEnumValue(node)
+	case *ast.FunctionLiteral:
+		return e.evalFunctionLiteral(node)
 	case *ast.ExpressionStatement:
 		return e.evalExpressionStatement(node)
+	case *ast.BareExpression:
+		return e.Evaluate(node.Expression)
 	case *ast.Body:
 		return e.evalStmts(node.Statements)
 	case *ast.Function:
 		return e.evalFunc(node)
+	case *ast.Import:
+		return e.evalImport(node)
+	case *ast.Struct, *ast.Enum:
+		return &object.EvalResult{Value: nil, Signal: object.SIGNAL_NONE}, nil
 	case *ast.VarAndConst:
 		return e.evalVarConst(node, false, nil)
 	case *ast.MultiAssignment:
@@ -95,8 +178,12 @@ func (e *Evaluator) Evaluate(node ast.Node) (*object.EvalResult, error) {
 		return e.evalReturn(node)
 	case *ast.If:
 		return e.evalIf(node)
+	case *ast.Match:
+		return e.evalMatch(node)
 	case *ast.ForLoop:
 		return e.evalForLoop(node)
+	case *ast.ForEach:
+		return e.evalForEach(node)
 	case *ast.WhileLoop:
 		return e.evalWhileLoop(node)
 	case *ast.Continue:
@@ -107,3 +194,23 @@ func (e *Evaluator) Evaluate(node ast.Node) (*object.EvalResult, error) {
 		return nil, fmt.Errorf("no eval function for given node type, got: %T", node)
 	}
 }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Interactive: evaluate a single top level statement from the repl, keeping the global environment around
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) EvaluateInteractive(stmt ast.Statement) (object.Object, error) {
+	var r *object.EvalResult
+	var err error
+
+	// a `main` typed in the repl is only declared, like any other function
+	e.skipMain = true
+	if es, ok := stmt.(*ast.ExpressionStatement); ok {
+		r, err = e.Evaluate(es.Expression)
+	} else {
+		r, err = e.Evaluate(stmt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Value, nil
+}
@@ -1,16 +1,14 @@
 package evaluator
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
-	"math"
-	"os"
-	"strconv"
 	"strings"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/builtins"
 	"github.com/KhushPatibandha/Kolon/src/environment"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
 	"github.com/KhushPatibandha/Kolon/src/object"
 )
 
@@ -22,12 +20,28 @@ import (
 // Identifier
 // ------------------------------------------------------------------------------------------------------------------
 func (e *Evaluator) evalIdentifier(i *ast.Identifier) (*object.EvalResult, error) {
+	if i.Index == ast.Unresolved {
+		return nil, ast.UnresolvedError(i)
+	}
+	if i.Depth != ast.Global {
+		return &object.EvalResult{
+			Value:  e.stack.Top().Get(i.Depth, i.Index),
+			Signal: object.SIGNAL_NONE,
+		}, nil
+	}
 	if sym, ok := e.stack.Top().GetVar(i.Value); ok {
 		return &object.EvalResult{
 			Value:  sym.ValueObject,
 			Signal: object.SIGNAL_NONE,
 		}, nil
 	}
+	// a declared function used as a value, it can only see the globals of the file it was declared in
+	if sym, ok := e.stack.Top().GetFunc(i.Value); ok && !sym.Func.Builtin {
+		return &object.EvalResult{
+			Value:  &environment.Closure{Function: sym.Func.Function, Env: sym.Env, FuncType: i.Type},
+			Signal: object.SIGNAL_NONE,
+		}, nil
+	}
 	return nil, fmt.Errorf("identifier not found: %s", i.Value)
 }
 
@@ -71,6 +85,32 @@ func (e *Evaluator) evalString(s *ast.String) (*object.EvalResult, error) {
 	}, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Interpolated String
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalInterpolatedString(s *ast.InterpolatedString) (*object.EvalResult, error) {
+	var out strings.Builder
+	for i, part := range s.Parts {
+		out.WriteString(part)
+		if i == len(s.Expressions) {
+			break
+		}
+		r, err := e.Evaluate(s.Expressions[i])
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(builtins.Printable(r.Value))
+	}
+	str := &object.String{Value: out.String()}
+	if err := e.alloc(str); err != nil {
+		return nil, err
+	}
+	return &object.EvalResult{
+		Value:  str,
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Char
 // ------------------------------------------------------------------------------------------------------------------
@@ -85,10 +125,10 @@ func (e *Evaluator) evalChar(c *ast.Char) (*object.EvalResult, error) {
 // HashMap
 // ------------------------------------------------------------------------------------------------------------------
 func (e *Evaluator) evalHashMap(h *ast.HashMap) (*object.EvalResult, error) {
-	pairs := make(map[object.HashKey]object.HashPair)
+	pairs := object.NewHashMap()
 
-	for k, v := range h.Pairs {
-		key, err := e.Evaluate(k)
+	for _, p := range h.Pairs {
+		key, err := e.Evaluate(p.Key)
 		if err != nil {
 			return nil, err
 		}
@@ -98,16 +138,18 @@ func (e *Evaluator) evalHashMap(h *ast.HashMap) (*object.EvalResult, error) {
 			return nil, errors.New("unusable as hash key: " + string(key.Value.Type()))
 		}
 
-		value, err := e.Evaluate(v)
+		value, err := e.Evaluate(p.Value)
 		if err != nil {
 			return nil, err
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key.Value, Value: value.Value}
+		pairs.Set(hashKey, value.Value)
+	}
+	if err := e.alloc(pairs); err != nil {
+		return nil, err
 	}
 	return &object.EvalResult{
-		Value:  &object.HashMap{Pairs: pairs},
+		Value:  pairs,
 		Signal: object.SIGNAL_NONE,
 	}, nil
 }
@@ -124,12 +166,70 @@ func (e *Evaluator) evalArray(a *ast.Array) (*object.EvalResult, error) {
 		}
 		res = append(res, r.Value)
 	}
+	if err := e.alloc(&object.Array{Elements: res}); err != nil {
+		return nil, err
+	}
 	return &object.EvalResult{
 		Value:  &object.Array{Elements: res},
 		Signal: object.SIGNAL_NONE,
 	}, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Struct Literal
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalStructLiteral(s *ast.StructLiteral) (*object.EvalResult, error) {
+	values := make([]object.Object, len(s.Values))
+	for i, v := range s.Values {
+		r, err := e.Evaluate(v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = r.Value
+	}
+	return &object.EvalResult{
+		Value:  &object.Struct{Name: s.Type.Name, Fields: s.Fields, Values: values},
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum Value
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalEnumValue(ev *ast.EnumValue) (*object.EvalResult, error) {
+	var values []object.Object
+	for _, arg := range ev.Args {
+		r, err := e.Evaluate(arg)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, r.Value)
+	}
+	return &object.EvalResult{
+		Value: &object.EnumValue{
+			Enum:    ev.Type.Name,
+			Variant: ev.Variant.Value,
+			Index:   ev.Index,
+			Values:  values,
+		},
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Field Access
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalFieldAccess(f *ast.FieldAccess) (*object.EvalResult, error) {
+	left, err := e.Evaluate(f.Left)
+	if err != nil {
+		return nil, err
+	}
+	return &object.EvalResult{
+		Value:  left.Value.(*object.Struct).Values[f.Index],
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Prefix
 // ------------------------------------------------------------------------------------------------------------------
@@ -177,33 +277,68 @@ func (e *Evaluator) evalInfix(i *ast.Infix) (*object.EvalResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	r, err := e.evalInfixValues(i.Operator, left.Value, right.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.alloc(r.Value); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// evalInfixValues applies operator to the already evaluated sides of an infix operation
+func (e *Evaluator) evalInfixValues(operator string, left, right object.Object) (*object.EvalResult, error) {
 	switch {
-	case left.Value.Type() == object.INTEGER_OBJ && right.Value.Type() == object.INTEGER_OBJ:
-		return e.evalInfixInteger(i.Operator, left.Value, right.Value)
-	case left.Value.Type() == object.FLOAT_OBJ && right.Value.Type() == object.FLOAT_OBJ:
-		return e.evalInfixFloat(i.Operator, left.Value, right.Value)
-	case left.Value.Type() == object.BOOLEAN_OBJ && right.Value.Type() == object.BOOLEAN_OBJ:
-		return e.evalInfixBool(i.Operator, left.Value, right.Value)
-	case left.Value.Type() == object.STRING_OBJ && right.Value.Type() == object.STRING_OBJ:
-		return e.evalInfixString(i.Operator, left.Value, right.Value)
-	case left.Value.Type() == object.CHAR_OBJ && right.Value.Type() == object.CHAR_OBJ:
-		return e.evalInfixChar(i.Operator, left.Value, right.Value)
-	case (left.Value.Type() == object.INTEGER_OBJ && right.Value.Type() == object.FLOAT_OBJ) ||
-		(left.Value.Type() == object.FLOAT_OBJ && right.Value.Type() == object.INTEGER_OBJ):
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return e.evalInfixInteger(operator, left, right)
+	case left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ:
+		return e.evalInfixFloat(operator, left, right)
+	case left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ:
+		return e.evalInfixBool(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return e.evalInfixString(operator, left, right)
+	case left.Type() == object.CHAR_OBJ && right.Type() == object.CHAR_OBJ:
+		return e.evalInfixChar(operator, left, right)
+	case (left.Type() == object.INTEGER_OBJ && right.Type() == object.FLOAT_OBJ) ||
+		(left.Type() == object.FLOAT_OBJ && right.Type() == object.INTEGER_OBJ):
 		l := 0.0
 		r := 0.0
 
-		if left.Value.Type() == object.INTEGER_OBJ {
-			l = float64(left.Value.(*object.Integer).Value)
-			r = right.Value.(*object.Float).Value
+		if left.Type() == object.INTEGER_OBJ {
+			l = float64(left.(*object.Integer).Value)
+			r = right.(*object.Float).Value
 		} else {
-			l = left.Value.(*object.Float).Value
-			r = float64(right.Value.(*object.Integer).Value)
+			l = left.(*object.Float).Value
+			r = float64(right.(*object.Integer).Value)
 		}
 
-		return e.evalInfixFloat(i.Operator, &object.Float{Value: l}, &object.Float{Value: r})
+		return e.evalInfixFloat(operator, &object.Float{Value: l}, &object.Float{Value: r})
+	case left.Type() == object.ENUM_OBJ && right.Type() == object.ENUM_OBJ:
+		return e.evalInfixEnum(operator, left, right)
+	default:
+		return e.evalInfixArray(operator, left, right)
+	}
+}
+
+// evalInfixEnum compares two enum values, they are equal if they hold the same variant with equal values
+func (e *Evaluator) evalInfixEnum(operator string,
+	left, right object.Object,
+) (*object.EvalResult, error) {
+	equal := left.Inspect() == right.Inspect()
+	switch operator {
+	case "==":
+		if equal {
+			return TRUE, nil
+		}
+		return FALSE, nil
+	case "!=":
+		if equal {
+			return FALSE, nil
+		}
+		return TRUE, nil
 	default:
-		return e.evalInfixArray(i.Operator, left.Value, right.Value)
+		return nil, errors.New("unknown operator for enums, got: " + operator)
 	}
 }
 
@@ -212,12 +347,10 @@ func (e *Evaluator) evalInfixChar(operator string,
 ) (*object.EvalResult, error) {
 	l := left.(*object.Char).Value
 	r := right.(*object.Char).Value
-	l = l[1 : len(l)-1]
-	r = r[1 : len(r)-1]
 	switch operator {
 	case "+":
 		return &object.EvalResult{
-			Value:  &object.String{Value: "\"" + l + r + "\""},
+			Value:  &object.String{Value: l + r},
 			Signal: object.SIGNAL_NONE,
 		}, nil
 	case "==":
@@ -238,12 +371,10 @@ func (e *Evaluator) evalInfixString(operator string,
 ) (*object.EvalResult, error) {
 	l := left.(*object.String).Value
 	r := right.(*object.String).Value
-	l = l[1 : len(l)-1]
-	r = r[1 : len(r)-1]
 	switch operator {
 	case "+":
 		return &object.EvalResult{
-			Value:  &object.String{Value: "\"" + l + r + "\""},
+			Value:  &object.String{Value: l + r},
 			Signal: object.SIGNAL_NONE,
 		}, nil
 	case "==":
@@ -459,22 +590,39 @@ func (e *Evaluator) evalInfixArray(operator string,
 // Postfix
 // ------------------------------------------------------------------------------------------------------------------
 func (e *Evaluator) evalPostfix(p *ast.Postfix) (*object.EvalResult, error) {
-	left, err := e.Evaluate(p.Left)
+	var pl *place
+	var left object.Object
+	var err error
+	switch p.Left.(type) {
+	case *ast.Identifier, *ast.FieldAccess, *ast.IndexExpression:
+		pl, err = e.evalPlace(p.Left)
+		if err == nil {
+			left, err = e.load(pl)
+		}
+	default:
+		var res *object.EvalResult
+		res, err = e.Evaluate(p.Left)
+		if err == nil {
+			left = res.Value
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 	var r *object.EvalResult
 
-	if left.Value.Type() == object.FLOAT_OBJ {
-		r, err = e.evalPostfixFloat(left.Value, p.Operator)
+	if left.Type() == object.FLOAT_OBJ {
+		r, err = e.evalPostfixFloat(left, p.Operator)
 	} else {
-		r, err = e.evalPostfixInteger(left.Value, p.Operator)
+		r, err = e.evalPostfixInteger(left, p.Operator)
 	}
 	if err != nil {
 		return nil, err
 	}
-	if i, ok := p.Left.(*ast.Identifier); ok {
-		e.stack.Top().SetValue(i.Value, r.Value)
+	if pl != nil {
+		if err := e.store(pl, r.Value); err != nil {
+			return nil, err
+		}
 	}
 	return r, nil
 }
@@ -523,16 +671,32 @@ func (e *Evaluator) evalAssignment(a *ast.Assignment,
 }
 
 func (e *Evaluator) evalAssignmentSymbol(a *ast.Assignment) (*object.EvalResult, error) {
-	in := &ast.Infix{
-		Left:     a.Left,
-		Operator: strings.TrimSuffix(a.Operator, "="),
-		Right:    a.Right,
+	pl, err := e.evalPlace(a.Left)
+	if err != nil {
+		return nil, err
+	}
+	left, err := e.load(pl)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.Evaluate(a.Right)
+	if err != nil {
+		return nil, err
 	}
-	right, err := e.evalInfix(in)
+	r, err := e.evalInfixValues(strings.TrimSuffix(a.Operator, "="), left, right.Value)
 	if err != nil {
 		return nil, err
 	}
-	return e.evalAssignmentEqual(a, true, right.Value)
+	if err := e.alloc(r.Value); err != nil {
+		return nil, err
+	}
+	if err := e.store(pl, r.Value); err != nil {
+		return nil, err
+	}
+	return &object.EvalResult{
+		Value:  r.Value,
+		Signal: object.SIGNAL_NONE,
+	}, nil
 }
 
 func (e *Evaluator) evalAssignmentEqual(a *ast.Assignment,
@@ -551,7 +715,9 @@ func (e *Evaluator) evalAssignmentEqual(a *ast.Assignment,
 		r = res.Value
 	}
 
-	e.stack.Top().SetValue(a.Left.Value, r)
+	if err := e.assign(a.Left, r); err != nil {
+		return nil, err
+	}
 	return &object.EvalResult{
 		Value:  r,
 		Signal: object.SIGNAL_NONE,
@@ -562,24 +728,90 @@ func (e *Evaluator) evalAssignmentEqual(a *ast.Assignment,
 // CallExpression
 // ------------------------------------------------------------------------------------------------------------------
 func (e *Evaluator) evalCall(c *ast.CallExpression) (*object.EvalResult, error) {
+	if c.Name == nil {
+		return e.evalCallValue(c)
+	}
 	args, err := e.evalCallArgs(c)
 	if err != nil {
 		return nil, err
 	}
 
-	sym, _ := e.env.GetFunc(c.Name.Value)
+	sym, _ := e.stack.Top().GetFunc(c.Name.Value)
 	if sym.Func.Builtin {
-		return e.evalBuiltin(c, args)
+		if c.Name.Value == "typeOf" {
+			return &object.EvalResult{
+				Value:  &object.String{Value: c.Args[0].GetType().Types[0].String()},
+				Signal: object.SIGNAL_NONE,
+			}, nil
+		}
+		r, err := e.Builtin(c.Name.Value, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.alloc(r); err != nil {
+			return nil, err
+		}
+		return &object.EvalResult{
+			Value:  r,
+			Signal: object.SIGNAL_NONE,
+		}, nil
+	}
+
+	// declared functions only see the globals of their file, not the variables of whoever called them
+	return e.callFunction(sym.Func.Function, sym.Env, args, c.Pos())
+}
+
+// evalCallValue calls a closure, the value of any expression of function type
+func (e *Evaluator) evalCallValue(c *ast.CallExpression) (*object.EvalResult, error) {
+	callee, err := e.Evaluate(c.Callee)
+	if err != nil {
+		return nil, err
+	}
+	args, err := e.evalCallArgs(c)
+	if err != nil {
+		return nil, err
 	}
+	closure := callee.Value.(*environment.Closure)
+	return e.callFunction(closure.Function, closure.Env, args, c.Pos())
+}
 
-	localEnv := environment.BootstrapFuncEnv(sym.Func.Function, e.stack.Top())
-	e.stack.Push(localEnv)
-	for i, param := range sym.Func.Function.Parameters {
-		localEnv.SetValue(param.ParameterName.Value, args[i])
+// callFunction runs the body of fn in a new frame enclosed by env, its parameters are the first slots, set to args.
+// pos is where it's called from
+func (e *Evaluator) callFunction(fn *ast.Function,
+	env *environment.Environment,
+	args []object.Object,
+	pos lexer.Position,
+) (*object.EvalResult, error) {
+	name := "<function literal>"
+	if fn.Name != nil {
+		name = fn.Name.Value
+	}
+	file, ok := e.files[fn]
+	if !ok {
+		file = e.file
 	}
-	r, err := e.evalStmts(sym.Func.Function.Body.Statements)
+	leave, err := e.enter(name, file, pos)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
 
-	return r, err
+	localEnv := environment.NewFrame(env)
+	localEnv.Values = args
+	return e.evalBlock(localEnv, fn.Body.Statements)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// FunctionLiteral
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalFunctionLiteral(f *ast.FunctionLiteral) (*object.EvalResult, error) {
+	if file := e.currentFile(); file != "" {
+		e.files[f.Function] = file
+	}
+	return &object.EvalResult{
+		Value:  &environment.Closure{Function: f.Function, Env: e.stack.Top(), FuncType: f.Type},
+		Signal: object.SIGNAL_NONE,
+	}, nil
 }
 
 func (e *Evaluator) evalCallArgs(c *ast.CallExpression) ([]object.Object, error) {
@@ -620,14 +852,8 @@ func (e *Evaluator) evalIndex(i *ast.IndexExpression) (*object.EvalResult, error
 func (e *Evaluator) evalIndexArray(left, index object.Object) (*object.EvalResult, error) {
 	a := left.(*object.Array)
 	i := index.(*object.Integer).Value
-	maxIdx := int64(len(a.Elements) - 1)
-	if i < 0 || i > maxIdx {
-		return nil,
-			errors.New(
-				"index out of range, index: " +
-					strconv.FormatInt(i, 10) + ", max index: " +
-					strconv.FormatInt(maxIdx, 10) + ", min index: 0",
-			)
+	if err := checkIndex(i, len(a.Elements)); err != nil {
+		return nil, err
 	}
 	return &object.EvalResult{
 		Value:  a.Elements[i],
@@ -636,21 +862,14 @@ func (e *Evaluator) evalIndexArray(left, index object.Object) (*object.EvalResul
 }
 
 func (e *Evaluator) evalIndexString(left, index object.Object) (*object.EvalResult, error) {
-	s := left.(*object.String).Value
-	s = s[1 : len(s)-1]
+	s := []rune(left.(*object.String).Value)
 
 	i := index.(*object.Integer).Value
-	maxIdx := int64(len(s) - 1)
-	if i < 0 || i > maxIdx {
-		return nil,
-			errors.New(
-				"index out of range, index: " +
-					strconv.FormatInt(i, 10) + ", max index: " +
-					strconv.FormatInt(maxIdx, 10) + ", min index: 0",
-			)
+	if err := checkIndex(i, len(s)); err != nil {
+		return nil, err
 	}
 	return &object.EvalResult{
-		Value:  &object.Char{Value: "'" + string([]rune(s)[i]) + "'"},
+		Value:  &object.Char{Value: string(s[i])},
 		Signal: object.SIGNAL_NONE,
 	}, nil
 }
@@ -661,548 +880,12 @@ func (e *Evaluator) evalIndexHashMap(left, index object.Object) (*object.EvalRes
 	if !ok {
 		return nil, errors.New("unusable as hash key: " + string(index.Type()))
 	}
-	pair, ok := h.Pairs[k.HashKey()]
+	pair, ok := h.Get(k)
 	if !ok {
-		return nil, errors.New("key not found: " + index.Inspect())
+		return nil, errors.New("key not found: " + object.Display(index))
 	}
 	return &object.EvalResult{
 		Value:  pair.Value,
 		Signal: object.SIGNAL_NONE,
 	}, nil
 }
-
-// ------------------------------------------------------------------------------------------------------------------
-// Builtin
-// ------------------------------------------------------------------------------------------------------------------
-func (e *Evaluator) evalBuiltin(c *ast.CallExpression, args []object.Object) (*object.EvalResult, error) {
-	name := c.Name.Value
-	switch name {
-	case "len":
-		var r object.Object
-		switch arg := args[0].(type) {
-		case *object.String:
-			r = &object.Integer{Value: int64(len(arg.Value) - 2)}
-		case *object.Array:
-			r = &object.Integer{Value: int64(len(arg.Elements))}
-		case *object.HashMap:
-			r = &object.Integer{Value: int64(len(arg.Pairs))}
-		}
-		return &object.EvalResult{
-			Value:  r,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "toString":
-		var r object.Object
-		switch arg := args[0].(type) {
-		case *object.Integer:
-			s := strconv.FormatInt(arg.Value, 10)
-			s = "\"" + s + "\""
-			r = &object.String{Value: s}
-		case *object.Float:
-			s := strconv.FormatFloat(arg.Value, 'f', -1, 64)
-			s = "\"" + s + "\""
-			r = &object.String{Value: s}
-		case *object.Bool:
-			s := strconv.FormatBool(arg.Value)
-			s = "\"" + s + "\""
-			r = &object.String{Value: s}
-		case *object.Char:
-			s := arg.Value[1 : len(arg.Value)-1]
-			s = "\"" + s + "\""
-			r = &object.String{Value: s}
-		case *object.String:
-			r = arg
-		case *object.Array:
-			r = &object.String{Value: "\"" + arg.Inspect() + "\""}
-		case *object.HashMap:
-			r = &object.String{Value: "\"" + arg.Inspect() + "\""}
-		}
-		return &object.EvalResult{
-			Value:  r,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "toInt":
-		var r object.Object
-		switch arg := args[0].(type) {
-		case *object.Integer:
-			r = arg
-		case *object.Float:
-			r = &object.Integer{Value: int64(arg.Value)}
-		case *object.Char:
-			s := arg.Value[1 : len(arg.Value)-1]
-			r = &object.Integer{Value: int64(s[0])}
-		case *object.String:
-			s := arg.Value[1 : len(arg.Value)-1]
-			i, err := strconv.ParseInt(s, 10, 64)
-			if err != nil {
-				return nil, errors.New("Error converting string to int, can't convert: " + s)
-			}
-			r = &object.Integer{Value: i}
-		}
-		return &object.EvalResult{
-			Value:  r,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "toFloat":
-		var r object.Object
-		switch arg := args[0].(type) {
-		case *object.Integer:
-			r = &object.Float{Value: float64(arg.Value)}
-		case *object.Float:
-			r = arg
-		case *object.String:
-			s := arg.Value[1 : len(arg.Value)-1]
-			f, err := strconv.ParseFloat(s, 64)
-			if err != nil {
-				return nil, errors.New("Error converting string to float, can't convert: " + s)
-			}
-			r = &object.Float{Value: f}
-		}
-		return &object.EvalResult{
-			Value:  r,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "print":
-		switch arg := args[0].(type) {
-		case *object.String:
-			fmt.Print(arg.Value[1 : len(arg.Value)-1])
-		case *object.Char:
-			fmt.Print(arg.Value[1 : len(arg.Value)-1])
-		case *object.Integer:
-			fmt.Print(strconv.FormatInt(arg.Value, 10))
-		case *object.Float:
-			s := strconv.FormatFloat(arg.Value, 'f', -1, 64)
-			if !strings.Contains(s, ".") {
-				s += ".0"
-			}
-			fmt.Print(s)
-		case *object.Bool:
-			fmt.Print(strconv.FormatBool(arg.Value))
-		default:
-			fmt.Print(arg.Inspect())
-		}
-		return &object.EvalResult{
-			Value:  nil,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "println":
-		if len(args) == 0 {
-			fmt.Println()
-			return nil, nil
-		}
-		switch arg := args[0].(type) {
-		case *object.String:
-			fmt.Println(arg.Value[1 : len(arg.Value)-1])
-		case *object.Char:
-			fmt.Println(arg.Value[1 : len(arg.Value)-1])
-		case *object.Integer:
-			fmt.Println(strconv.FormatInt(arg.Value, 10))
-		case *object.Float:
-			s := strconv.FormatFloat(arg.Value, 'f', -1, 64)
-			if !strings.Contains(s, ".") {
-				s += ".0"
-			}
-			fmt.Println(s)
-		case *object.Bool:
-			fmt.Println(strconv.FormatBool(arg.Value))
-		default:
-			fmt.Println(arg.Inspect())
-		}
-		return &object.EvalResult{
-			Value:  nil,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "scan":
-		if len(args) != 0 {
-			strToPrint := args[0].(*object.String).
-				Value[1 : len(args[0].(*object.String).Value)-1]
-			if len(args) == 2 && args[1].Inspect() == "true" {
-				fmt.Println(strToPrint)
-			} else {
-				fmt.Print(strToPrint)
-			}
-		}
-		reader := bufio.NewReader(os.Stdin)
-		var input []string
-		for {
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				return nil, errors.New("error reading input: " + err.Error())
-			}
-			line = strings.TrimSpace(line)
-			line = strings.TrimSuffix(line, "\n")
-			if line == "" {
-				break
-			}
-			input = append(input, line)
-		}
-		res := strings.Join(input, " ")
-		return &object.EvalResult{
-			Value:  &object.String{Value: "\"" + res + "\""},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "scanln":
-		if len(args) != 0 {
-			strToPrint := args[0].(*object.String).
-				Value[1 : len(args[0].(*object.String).Value)-1]
-			if len(args) == 2 && args[1].Inspect() == "true" {
-				fmt.Println(strToPrint)
-			} else {
-				fmt.Print(strToPrint)
-			}
-		}
-		reader := bufio.NewReader(os.Stdin)
-		var input string
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return nil, errors.New("error reading input: " + err.Error())
-		}
-		input = strings.TrimSpace(input)
-		input = strings.TrimSuffix(input, "\n")
-		return &object.EvalResult{
-			Value:  &object.String{Value: "\"" + input + "\""},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "getIndex":
-		switch arg := args[0].(type) {
-		case *object.Array:
-			t := args[1].Inspect()
-			for i, ele := range arg.Elements {
-				if ele.Inspect() == t {
-					return &object.EvalResult{
-						Value:  &object.Integer{Value: int64(i)},
-						Signal: object.SIGNAL_NONE,
-					}, nil
-				}
-			}
-		}
-		return &object.EvalResult{
-			Value:  &object.Integer{Value: -1},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "keys":
-		var keys []object.Object
-		for _, pair := range args[0].(*object.HashMap).Pairs {
-			keys = append(keys, pair.Key)
-		}
-		return &object.EvalResult{
-			Value:  &object.Array{Elements: keys},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "values":
-		var values []object.Object
-		for _, pair := range args[0].(*object.HashMap).Pairs {
-			values = append(values, pair.Value)
-		}
-		return &object.EvalResult{
-			Value:  &object.Array{Elements: values},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "containsKey":
-		h := args[0].(*object.HashMap)
-		k, ok := args[1].(object.Hashable)
-		if !ok {
-			return nil, errors.New("unusable as hash key: " + string(args[1].Type()))
-		}
-		_, ok = h.Pairs[k.HashKey()]
-		if ok {
-			return TRUE, nil
-		}
-		return FALSE, nil
-	case "typeOf":
-		return &object.EvalResult{
-			Value: &object.String{Value: "\"" +
-				c.Args[0].GetType().Types[0].String() + "\""},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "push":
-		switch arg := args[0].(type) {
-		case *object.Array:
-			arg.Elements = append(arg.Elements, args[1])
-			return &object.EvalResult{
-				Value:  arg,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		default:
-			hashKey, ok := args[1].(object.Hashable)
-			if !ok {
-				return nil, errors.New("unusable as hash key: " + string(args[1].Type()))
-			}
-			arg.(*object.HashMap).Pairs[hashKey.HashKey()] = object.HashPair{
-				Key:   args[1],
-				Value: args[2],
-			}
-			return &object.EvalResult{
-				Value:  arg,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		}
-	case "pop":
-		a := args[0].(*object.Array)
-		var popped object.Object
-		if len(args) == 1 {
-			if len(a.Elements) == 0 {
-				return nil, errors.New("array is empty, can't pop any elements")
-			}
-			popped = a.Elements[len(a.Elements)-1]
-			a.Elements = a.Elements[:len(a.Elements)-1]
-		} else {
-			idx := args[1].(*object.Integer).Value
-			if idx < 0 || idx >= int64(len(a.Elements)) {
-				return nil,
-					errors.New("index out of range, can't pop element at index: " +
-						strconv.FormatInt(idx, 10),
-					)
-			}
-			popped = a.Elements[idx]
-			a.Elements = append(a.Elements[:idx], a.Elements[idx+1:]...)
-		}
-		return &object.EvalResult{
-			Value:  popped,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "insert":
-		a := args[0].(*object.Array)
-		idx := args[1].(*object.Integer).Value
-		if idx < 0 || idx > int64(len(a.Elements)) {
-			return nil,
-				errors.New("index out of range, can't insert element at index: " +
-					strconv.FormatInt(idx, 10),
-				)
-		}
-		value := args[2]
-		a.Elements = append(a.Elements[:idx], append([]object.Object{value}, a.Elements[idx:]...)...)
-		return &object.EvalResult{
-			Value:  a,
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "remove":
-		switch arg := args[0].(type) {
-		case *object.Array:
-			eleToRemove := args[1].Inspect()
-			for i, ele := range arg.Elements {
-				if ele.Inspect() == eleToRemove {
-					arg.Elements = append(arg.Elements[:i], arg.Elements[i+1:]...)
-					break
-				}
-			}
-			return &object.EvalResult{
-				Value:  arg,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		default:
-			hashKey, ok := args[1].(object.Hashable)
-			if !ok {
-				return nil, errors.New("unusable as hash key: " + string(args[1].Type()))
-			}
-			delete(arg.(*object.HashMap).Pairs, hashKey.HashKey())
-			return &object.EvalResult{
-				Value:  arg,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		}
-	case "delete":
-		switch arg := args[0].(type) {
-		case *object.Array:
-			eleToDelete := args[1].Inspect()
-			for i, ele := range arg.Elements {
-				if ele.Inspect() == eleToDelete {
-					arg.Elements = append(arg.Elements[:i], arg.Elements[i+1:]...)
-					return &object.EvalResult{
-						Value:  args[1],
-						Signal: object.SIGNAL_NONE,
-					}, nil
-				}
-			}
-			return &object.EvalResult{
-				Value:  nil,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		default:
-			hashKey, ok := args[1].(object.Hashable)
-			if !ok {
-				return nil, errors.New("unusable as hash key: " + string(args[1].Type()))
-			}
-			hMap := arg.(*object.HashMap)
-			pair, ok := hMap.Pairs[hashKey.HashKey()]
-			if !ok {
-				return &object.EvalResult{
-					Value:  nil,
-					Signal: object.SIGNAL_NONE,
-				}, nil
-			}
-			delete(hMap.Pairs, hashKey.HashKey())
-			return &object.EvalResult{
-				Value:  pair.Value,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		}
-	case "slice":
-		start := args[1].(*object.Integer).Value
-		end := args[2].(*object.Integer).Value
-		switch arg := args[0].(type) {
-		case *object.Array:
-			if start < 0 || start >= int64(len(arg.Elements)) ||
-				end < 0 || end > int64(len(arg.Elements)) || start > end {
-				return nil,
-					errors.New("index out of range, can't slice array from " +
-						strconv.FormatInt(start, 10) + " to " +
-						strconv.FormatInt(end, 10),
-					)
-			}
-			newArr := &object.Array{}
-
-			if len(args) == 3 {
-				sliced := arg.Elements[start:end]
-				newArr.Elements = sliced
-			} else {
-				step := args[3].(*object.Integer).Value
-				if step <= 0 {
-					return nil,
-						errors.New("step must be a positive integer, got: " +
-							strconv.FormatInt(step, 10),
-						)
-				}
-				var sliced []object.Object
-				for i := start; i < end; i += step {
-					sliced = append(sliced, arg.Elements[i])
-				}
-				newArr.Elements = sliced
-			}
-
-			return &object.EvalResult{
-				Value:  newArr,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		default:
-			s := arg.(*object.String).Value
-			s = s[1 : len(s)-1]
-			if start < 0 || start >= int64(len(s)) ||
-				end < 0 || end > int64(len(s)) || start > end {
-				return nil,
-					errors.New("index out of range, can't slice string from " +
-						strconv.FormatInt(start, 10) + " to " +
-						strconv.FormatInt(end, 10),
-					)
-			}
-			newStr := &object.String{}
-
-			if len(args) == 3 {
-				sliced := s[start:end]
-				newStr.Value = "\"" + sliced + "\""
-			} else {
-				step := args[3].(*object.Integer).Value
-				if step <= 0 {
-					return nil,
-						errors.New("step must be a positive integer, got: " +
-							strconv.FormatInt(step, 10),
-						)
-				}
-				var sliced strings.Builder
-				for i := start; i < end; i += step {
-					sliced.WriteByte(s[i])
-				}
-				newStr.Value = "\"" + sliced.String() + "\""
-			}
-
-			return &object.EvalResult{
-				Value:  newStr,
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		}
-	case "equals":
-		switch arg := args[0].(type) {
-		case *object.Integer:
-			other := args[1].(*object.Integer)
-			if arg.Value == other.Value {
-				return TRUE, nil
-			}
-			return FALSE, nil
-		case *object.Float:
-			other := args[1].(*object.Float)
-			if arg.Value == other.Value {
-				return TRUE, nil
-			}
-			return FALSE, nil
-		case *object.Bool:
-			other := args[1].(*object.Bool)
-			if arg.Value == other.Value {
-				return TRUE, nil
-			}
-			return FALSE, nil
-		case *object.String:
-			other := args[1].(*object.String)
-			if arg.Value == other.Value {
-				return TRUE, nil
-			}
-			return FALSE, nil
-		case *object.Char:
-			other := args[1].(*object.Char)
-			if arg.Value == other.Value {
-				return TRUE, nil
-			}
-			return FALSE, nil
-		case *object.Array:
-			other := args[1].(*object.Array)
-			if len(arg.Elements) != len(other.Elements) {
-				return FALSE, nil
-			}
-			if arg.Inspect() != other.Inspect() {
-				return FALSE, nil
-			}
-			return TRUE, nil
-		default:
-			h1 := args[0].(*object.HashMap)
-			h2 := args[1].(*object.HashMap)
-			if len(h1.Pairs) != len(h2.Pairs) {
-				return FALSE, nil
-			}
-			if h1.Inspect() != h2.Inspect() {
-				return FALSE, nil
-			}
-			return TRUE, nil
-		}
-	case "copy":
-		return &object.EvalResult{
-			Value:  deepCopy(args[0]),
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "ceil", "floor":
-		arg := args[0].(*object.Float)
-		var v float64
-		if name == "ceil" {
-			v = math.Ceil(arg.Value)
-		} else {
-			v = math.Floor(arg.Value)
-		}
-		return &object.EvalResult{
-			Value:  &object.Float{Value: v},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	case "round":
-		arg := args[0].(*object.Float)
-		if len(args) == 1 {
-			v := math.Round(arg.Value)
-			return &object.EvalResult{
-				Value:  &object.Float{Value: v},
-				Signal: object.SIGNAL_NONE,
-			}, nil
-		}
-		precision := args[1].(*object.Integer).Value
-		if precision < 0 {
-			return nil,
-				errors.New("precision must be a non-negative integer for `round`")
-		}
-		if precision > 15 {
-			precision = 15
-		}
-		multiplier := math.Pow(10, float64(precision))
-		v := math.Round(arg.Value*multiplier) / multiplier
-		return &object.EvalResult{
-			Value:  &object.Float{Value: v},
-			Signal: object.SIGNAL_NONE,
-		}, nil
-	default:
-		return nil, nil
-	}
-}
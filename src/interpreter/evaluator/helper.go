@@ -1,33 +1,175 @@
 package evaluator
 
-import "github.com/KhushPatibandha/Kolon/src/object"
-
-func deepCopy(o object.Object) object.Object {
-	switch obj := o.(type) {
-	case *object.Integer:
-		return &object.Integer{Value: obj.Value}
-	case *object.Float:
-		return &object.Float{Value: obj.Value}
-	case *object.Bool:
-		return &object.Bool{Value: obj.Value}
-	case *object.String:
-		return &object.String{Value: obj.Value}
-	case *object.Char:
-		return &object.Char{Value: obj.Value}
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// assign stores value in target, a variable, a field of a struct or an element of an array or a hashmap
+func (e *Evaluator) assign(target ast.Expression, value object.Object) error {
+	pl, err := e.evalPlace(target)
+	if err != nil {
+		return err
+	}
+	return e.store(pl, value)
+}
+
+// place is the left side of an assignment with its parts evaluated, holder is the struct of a field access (or
+// the array/hashmap of an index expression) and key is the index. a compound assignment or a postfix operation
+// reads and then writes it, without running the expressions on the left twice
+type place struct {
+	target ast.Expression
+	holder object.Object
+	key    object.Object
+}
+
+func (e *Evaluator) evalPlace(target ast.Expression) (*place, error) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return &place{target: target}, nil
+	case *ast.FieldAccess:
+		left, err := e.Evaluate(target.Left)
+		if err != nil {
+			return nil, err
+		}
+		return &place{target: target, holder: left.Value}, nil
+	case *ast.IndexExpression:
+		left, err := e.Evaluate(target.Left)
+		if err != nil {
+			return nil, err
+		}
+		index, err := e.Evaluate(target.Index)
+		if err != nil {
+			return nil, err
+		}
+		return &place{target: target, holder: left.Value, key: index.Value}, nil
+	}
+	return nil, fmt.Errorf("can't assign to %T", target)
+}
+
+func (e *Evaluator) load(pl *place) (object.Object, error) {
+	switch target := pl.target.(type) {
+	case *ast.Identifier:
+		r, err := e.evalIdentifier(target)
+		if err != nil {
+			return nil, err
+		}
+		return r.Value, nil
+	case *ast.FieldAccess:
+		return pl.holder.(*object.Struct).Values[target.Index], nil
+	}
+	var r *object.EvalResult
+	var err error
+	if pl.holder.Type() == object.ARRAY_OBJ {
+		r, err = e.evalIndexArray(pl.holder, pl.key)
+	} else {
+		r, err = e.evalIndexHashMap(pl.holder, pl.key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Value, nil
+}
+
+// store writes value to pl, an element of an array must already exist while a key of a hashmap is added if it
+// isn't there yet
+func (e *Evaluator) store(pl *place, value object.Object) error {
+	switch target := pl.target.(type) {
+	case *ast.Identifier:
+		if target.Index == ast.Unresolved {
+			return ast.UnresolvedError(target)
+		}
+		if target.Depth != ast.Global {
+			e.stack.Top().Assign(target.Depth, target.Index, value)
+		} else {
+			e.stack.Top().SetValue(target.Value, value)
+		}
+		return nil
+	case *ast.FieldAccess:
+		pl.holder.(*object.Struct).Values[target.Index] = value
+		return nil
+	}
+	switch holder := pl.holder.(type) {
 	case *object.Array:
-		copyEle := make([]object.Object, len(obj.Elements))
-		for i, ele := range obj.Elements {
-			copyEle[i] = deepCopy(ele)
+		i := pl.key.(*object.Integer).Value
+		if err := checkIndex(i, len(holder.Elements)); err != nil {
+			return err
 		}
-		return &object.Array{Elements: copyEle}
-	default:
-		newPairs := make(map[object.HashKey]object.HashPair)
-		for k, v := range obj.(*object.HashMap).Pairs {
-			newPairs[k] = object.HashPair{
-				Key:   deepCopy(v.Key),
-				Value: deepCopy(v.Value),
-			}
+		holder.Elements[i] = value
+	case *object.HashMap:
+		k, ok := pl.key.(object.Hashable)
+		if !ok {
+			return errors.New("unusable as hash key: " + string(pl.key.Type()))
+		}
+		// a new key makes the hashmap bigger, like a push makes an array bigger
+		n := holder.Len()
+		holder.Set(k, value)
+		if holder.Len() > n {
+			return e.alloc(holder)
 		}
-		return &object.HashMap{Pairs: newPairs}
 	}
+	return nil
+}
+
+// checkIndex returns an error if i isn't an index of something of length n
+func checkIndex(i int64, n int) error {
+	maxIdx := int64(n - 1)
+	if i < 0 || i > maxIdx {
+		return errors.New(
+			"index out of range, index: " +
+				strconv.FormatInt(i, 10) + ", max index: " +
+				strconv.FormatInt(maxIdx, 10) + ", min index: 0",
+		)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Shared: the operations the vm runs on values, so both of them give the same results and the same errors
+// ------------------------------------------------------------------------------------------------------------------
+
+// Infix applies operator to the values of the two sides of an infix operation
+func Infix(operator string, left, right object.Object) (object.Object, error) {
+	r, err := (&Evaluator{}).evalInfixValues(operator, left, right)
+	if err != nil {
+		return nil, err
+	}
+	return r.Value, nil
+}
+
+// Index returns the element of an array (or string) at index, or the value of a hashmap for the key index
+func Index(left, index object.Object) (object.Object, error) {
+	var r *object.EvalResult
+	var err error
+	switch left.Type() {
+	case object.ARRAY_OBJ:
+		r, err = (&Evaluator{}).evalIndexArray(left, index)
+	case object.STRING_OBJ:
+		r, err = (&Evaluator{}).evalIndexString(left, index)
+	default:
+		r, err = (&Evaluator{}).evalIndexHashMap(left, index)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Value, nil
+}
+
+// SetIndex stores value as the element of an array at index, or as the value of a hashmap for the key index
+func SetIndex(holder, index, value object.Object) error {
+	return (&Evaluator{}).store(&place{target: &ast.IndexExpression{}, holder: holder, key: index}, value)
+}
+
+// Builtin calls the builtin function name, all but `typeOf`, which only needs the type of its argument. `print`
+// and `scan` use the streams of e
+func (e *Evaluator) Builtin(name string, args []object.Object) (object.Object, error) {
+	b, ok := e.builtins.Get(name)
+	if !ok {
+		return nil, errors.New("unknown builtin function `" + name + "`")
+	}
+	return b.Fn(e.io, args)
 }
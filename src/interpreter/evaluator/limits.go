@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// DefaultMaxDepth is the call depth limit when none is given, deep enough for any program that isn't recursing
+// forever and still far from running out of Go stack
+const DefaultMaxDepth = 10000
+
+// ------------------------------------------------------------------------------------------------------------------
+// Limits: how far a program can go before it's stopped, a MaxSteps or MaxAlloc of 0 is no limit and a MaxDepth of
+// 0 is DefaultMaxDepth. the vm stops a program at the same limits
+// ------------------------------------------------------------------------------------------------------------------
+type Limits struct {
+	MaxSteps int64 // nodes evaluated (instructions run on the vm)
+	MaxDepth int   // function calls running at the same time, `main` included
+	MaxAlloc int   // elements of an array or a hashmap and bytes of a string made by a single operation
+}
+
+// Depth returns the call depth limit of l
+func (l Limits) Depth() int {
+	if l.MaxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return l.MaxDepth
+}
+
+// AllocExceeded reports if o is bigger than the allocation limit of l
+func (l Limits) AllocExceeded(o object.Object) bool {
+	if l.MaxAlloc <= 0 {
+		return false
+	}
+	size := 0
+	switch o := o.(type) {
+	case *object.String:
+		size = len(o.Value)
+	case *object.Array:
+		size = len(o.Elements)
+	case *object.HashMap:
+		size = o.Len()
+	}
+	return size > l.MaxAlloc
+}
+
+type LimitKind int
+
+const (
+	StepLimit LimitKind = iota
+	DepthLimit
+	AllocLimit
+	Stopped // the context of the program was cancelled or timed out
+)
+
+// LimitError is the error of a program stopped by its limits or its context, the RuntimeError around it has the
+// calls that were running
+type LimitError struct {
+	Kind  LimitKind
+	Limit int64
+	Err   error // the error of the context, for Stopped
+}
+
+func (l *LimitError) Error() string {
+	switch l.Kind {
+	case StepLimit:
+		return fmt.Sprintf("step limit of %d exceeded", l.Limit)
+	case DepthLimit:
+		return fmt.Sprintf("call depth limit of %d exceeded", l.Limit)
+	case AllocLimit:
+		return fmt.Sprintf("allocation limit of %d exceeded", l.Limit)
+	default:
+		return "program stopped: " + l.Err.Error()
+	}
+}
+
+func (l *LimitError) Unwrap() error { return l.Err }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Evaluator Limits
+// ------------------------------------------------------------------------------------------------------------------
+
+// SetLimits stops the program once it goes over limits or ctx is done
+func (e *Evaluator) SetLimits(ctx context.Context, limits Limits) {
+	e.ctx = ctx
+	e.limits = limits
+}
+
+// step counts a node evaluated, the context is only looked at once every 1024 of them
+func (e *Evaluator) step() error {
+	e.steps++
+	if e.limits.MaxSteps > 0 && e.steps > e.limits.MaxSteps {
+		return limitError(StepLimit, e.limits.MaxSteps, nil)
+	}
+	if e.ctx != nil && e.steps&1023 == 0 {
+		if err := e.ctx.Err(); err != nil {
+			return limitError(Stopped, 0, err)
+		}
+	}
+	return nil
+}
+
+// alloc checks o, a value just made, against the allocation limit
+func (e *Evaluator) alloc(o object.Object) error {
+	if e.limits.AllocExceeded(o) {
+		return limitError(AllocLimit, int64(e.limits.MaxAlloc), nil)
+	}
+	return nil
+}
+
+func limitError(kind LimitKind, limit int64, err error) *LimitError {
+	return &LimitError{Kind: kind, Limit: limit, Err: err}
+}
@@ -1,7 +1,11 @@
 package evaluator
 
 import (
+	"strings"
+	"unicode/utf8"
+
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	"github.com/KhushPatibandha/Kolon/src/environment"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
 	"github.com/KhushPatibandha/Kolon/src/object"
@@ -26,10 +30,19 @@ func (e *Evaluator) evalExpressionStatement(es *ast.ExpressionStatement) (*objec
 }
 
 // ------------------------------------------------------------------------------------------------------------------
-// Body
+// Body: a scope is pushed and popped by the same call, so the stack is back to where it was however the scope is
+// left, by reaching its end, a `return`, `break` or `continue`, or an error
 // ------------------------------------------------------------------------------------------------------------------
-func (e *Evaluator) evalStmts(stmts []ast.Statement) (*object.EvalResult, error) {
+
+// evalBlock runs stmts with env pushed as the innermost scope
+func (e *Evaluator) evalBlock(env *environment.Environment, stmts []ast.Statement) (*object.EvalResult, error) {
+	e.stack.Push(env)
 	defer e.stack.Pop()
+	return e.evalStmts(stmts)
+}
+
+// evalStmts runs stmts in the current scope
+func (e *Evaluator) evalStmts(stmts []ast.Statement) (*object.EvalResult, error) {
 	for _, stmt := range stmts {
 		r, err := e.Evaluate(stmt)
 		if err != nil {
@@ -63,6 +76,17 @@ func (e *Evaluator) evalVarConst(vc *ast.VarAndConst,
 		}
 		r = res.Value
 	}
+	if vc.Name.Index == ast.Unresolved {
+		return nil, ast.UnresolvedError(vc.Name)
+	}
+	// only the globals are kept by name
+	if vc.Name.Depth != ast.Global {
+		e.stack.Top().Define(vc.Name.Index, r)
+		return &object.EvalResult{
+			Value:  nil,
+			Signal: object.SIGNAL_NONE,
+		}, nil
+	}
 
 	sym := &environment.Symbol{
 		Ident:       vc.Name,
@@ -149,15 +173,60 @@ func (e *Evaluator) evalFunc(f *ast.Function) (*object.EvalResult, error) {
 			Function: f,
 			Builtin:  false,
 		},
-		Env:  nil,
+		Env:  e.env,
 		Type: nil,
 	})
-	if f.Name.Value == "main" {
-		if sym, ok := e.env.GetFunc("main"); ok {
-			localEnv := environment.NewEnclosedEnvironment(e.stack.Top())
-			sym.Env = localEnv
-			e.stack.Push(sym.Env)
-			return e.evalStmts(f.Body.Statements)
+	if f.Name.Value == "main" && !e.skipMain {
+		leave, err := e.enter("main", e.file, lexer.Position{})
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+		return e.evalBlock(environment.NewFrame(e.stack.Top()), f.Body.Statements)
+	}
+	return &object.EvalResult{
+		Value:  nil,
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Import: the module is evaluated in its own global environment the first time it's imported, its exported
+// functions are then declared as `module.name`, they keep running in the environment of the module
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalImport(i *ast.Import) (*object.EvalResult, error) {
+	env, ok := e.modules[i.Module]
+	if !ok {
+		m := &Evaluator{
+			inTesting: e.inTesting,
+			env:       environment.NewEnvironment(),
+			stack:     environment.NewStack(),
+			modules:   e.modules,
+			builtins:  e.builtins,
+			io:        e.io,
+			ctx:       e.ctx,
+			limits:    e.limits,
+			file:      i.Module.Path,
+			files:     e.files,
+		}
+		m.stack.Push(m.env)
+		environment.LoadBuiltins(m.env, m.builtins)
+		if _, err := m.Evaluate(i.Module.Program); err != nil {
+			return nil, diagnostic.WithFile(err, i.Module.Path)
+		}
+		env = m.env
+		e.modules[i.Module] = env
+		// the functions it imported from other modules are `module.name`, their files are already known
+		for name, sym := range env.FuncNameSpace {
+			if !sym.Func.Builtin && !strings.Contains(name, ".") {
+				e.files[sym.Func.Function] = i.Module.Path
+			}
+		}
+	}
+
+	for name, sym := range env.FuncNameSpace {
+		if !sym.Func.Builtin && ast.Exported(name) {
+			e.env.FuncNameSpace[i.Module.Name+"."+name] = sym
 		}
 	}
 	return &object.EvalResult{
@@ -176,9 +245,7 @@ func (e *Evaluator) evalIf(i *ast.If) (*object.EvalResult, error) {
 	}
 
 	if condition.Value == TRUE.Value {
-		localEnv := environment.NewEnclosedEnvironment(e.stack.Top())
-		e.stack.Push(localEnv)
-		return e.evalStmts(i.Body.Statements)
+		return e.evalBlock(environment.NewFrame(e.stack.Top()), i.Body.Statements)
 	} else if i.MultiConditionals != nil {
 		for _, mc := range i.MultiConditionals {
 			r, success, err := e.evalElseIf(mc)
@@ -191,9 +258,37 @@ func (e *Evaluator) evalIf(i *ast.If) (*object.EvalResult, error) {
 		}
 	}
 	if i.Alternate != nil {
-		localEnv := environment.NewEnclosedEnvironment(e.stack.Top())
-		e.stack.Push(localEnv)
-		return e.evalStmts(i.Alternate.Body.Statements)
+		return e.evalBlock(environment.NewFrame(e.stack.Top()), i.Alternate.Body.Statements)
+	}
+
+	return &object.EvalResult{
+		Value:  nil,
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Match
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalMatch(m *ast.Match) (*object.EvalResult, error) {
+	value, err := e.Evaluate(m.Value)
+	if err != nil {
+		return nil, err
+	}
+	v := value.Value.(*object.EnumValue)
+
+	for _, arm := range m.Arms {
+		if arm.Index != v.Index {
+			continue
+		}
+		localEnv := environment.NewFrame(e.stack.Top())
+		for i, binding := range arm.Bindings {
+			localEnv.Define(binding.Index, v.Values[i])
+		}
+		return e.evalBlock(localEnv, arm.Body.Statements)
+	}
+	if m.Alternate != nil {
+		return e.evalBlock(environment.NewFrame(e.stack.Top()), m.Alternate.Body.Statements)
 	}
 
 	return &object.EvalResult{
@@ -211,9 +306,7 @@ func (e *Evaluator) evalElseIf(ei *ast.ElseIf) (*object.EvalResult, bool, error)
 		return nil, false, err
 	}
 	if condition.Value == TRUE.Value {
-		localEnv := environment.NewEnclosedEnvironment(e.stack.Top())
-		e.stack.Push(localEnv)
-		r, err := e.evalStmts(ei.Body.Statements)
+		r, err := e.evalBlock(environment.NewFrame(e.stack.Top()), ei.Body.Statements)
 		return r, true, err
 	}
 	return nil, false, nil
@@ -223,8 +316,9 @@ func (e *Evaluator) evalElseIf(ei *ast.ElseIf) (*object.EvalResult, bool, error)
 // ForLoop
 // ------------------------------------------------------------------------------------------------------------------
 func (e *Evaluator) evalForLoop(f *ast.ForLoop) (*object.EvalResult, error) {
-	localEnv := environment.NewEnclosedEnvironment(e.stack.Top())
+	localEnv := environment.NewFrame(e.stack.Top())
 	e.stack.Push(localEnv)
+	defer e.stack.Pop()
 
 	_, err := e.Evaluate(f.Left)
 	if err != nil {
@@ -237,9 +331,7 @@ func (e *Evaluator) evalForLoop(f *ast.ForLoop) (*object.EvalResult, error) {
 	}
 
 	for condition.Value == TRUE.Value {
-		bodyLocalEnv := environment.NewEnclosedEnvironment(e.stack.Top())
-		e.stack.Push(bodyLocalEnv)
-		r, err := e.evalStmts(f.Body.Statements)
+		r, err := e.evalBlock(environment.NewFrame(e.stack.Top()), f.Body.Statements)
 		if err != nil {
 			return nil, err
 		}
@@ -263,7 +355,6 @@ func (e *Evaluator) evalForLoop(f *ast.ForLoop) (*object.EvalResult, error) {
 			break
 		}
 	}
-	e.stack.Pop()
 
 	return &object.EvalResult{
 		Value:  nil,
@@ -271,6 +362,96 @@ func (e *Evaluator) evalForLoop(f *ast.ForLoop) (*object.EvalResult, error) {
 	}, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// ForEach
+// ------------------------------------------------------------------------------------------------------------------
+func (e *Evaluator) evalForEach(f *ast.ForEach) (*object.EvalResult, error) {
+	iterable, err := e.Evaluate(f.Iterable)
+	if err != nil {
+		return nil, err
+	}
+	it := NewForEachIter(iterable.Value)
+	vars := make([]object.Object, len(f.Vars))
+
+	for it.Next(vars) {
+		// the variables of the loop are the first slots of the scope of each iteration
+		bodyLocalEnv := environment.NewFrame(e.stack.Top())
+		for i, v := range f.Vars {
+			bodyLocalEnv.Define(v.Name.Index, vars[i])
+		}
+		r, err := e.evalBlock(bodyLocalEnv, f.Body.Statements)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Signal == object.SIGNAL_BREAK {
+			break
+		} else if r.Signal == object.SIGNAL_RETURN {
+			return r, nil
+		}
+	}
+
+	return &object.EvalResult{
+		Value:  nil,
+		Signal: object.SIGNAL_NONE,
+	}, nil
+}
+
+// ForEachIter goes over an array (or string) or a hashmap for a for-each loop. the elements (or pairs) are taken
+// before the loop starts, so elements (or keys) the body adds, removes or changes don't change what the loop goes
+// over. the index (and the char of a string) an iteration gets is only made when it runs
+type ForEachIter struct {
+	elements []object.Object
+	pairs    []object.HashPair
+	str      string
+	pos      int // byte offset of the next char of str
+	next     int // index of the next item
+}
+
+func NewForEachIter(iterable object.Object) *ForEachIter {
+	it := &ForEachIter{}
+	switch v := iterable.(type) {
+	case *object.Array:
+		it.elements = append([]object.Object(nil), v.Elements...)
+	case *object.String:
+		it.str = v.Value
+	case *object.HashMap:
+		it.pairs = v.Pairs()
+	}
+	return it
+}
+
+// Next puts the values the variables of the loop get in the next iteration into vars, it returns false once
+// there are none left. with a single variable that's the element of an array (or string) and the key of a
+// hashmap (in the order the keys were added)
+func (it *ForEachIter) Next(vars []object.Object) bool {
+	var value object.Object
+	switch {
+	case it.next < len(it.elements):
+		value = it.elements[it.next]
+	case it.pos < len(it.str):
+		ch, size := utf8.DecodeRuneInString(it.str[it.pos:])
+		it.pos += size
+		value = &object.Char{Value: string(ch)}
+	case it.next < len(it.pairs):
+		pair := it.pairs[it.next]
+		vars[0] = pair.Key
+		if len(vars) == 2 {
+			vars[1] = pair.Value
+		}
+		it.next++
+		return true
+	default:
+		return false
+	}
+	if len(vars) == 2 {
+		vars[0] = &object.Integer{Value: int64(it.next)}
+	}
+	vars[len(vars)-1] = value
+	it.next++
+	return true
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // WhileLoop
 // ------------------------------------------------------------------------------------------------------------------
@@ -281,9 +462,7 @@ func (e *Evaluator) evalWhileLoop(w *ast.WhileLoop) (*object.EvalResult, error)
 	}
 
 	for condition.Value == TRUE.Value {
-		localEnv := environment.NewEnclosedEnvironment(e.stack.Top())
-		e.stack.Push(localEnv)
-		r, err := e.evalStmts(w.Body.Statements)
+		r, err := e.evalBlock(environment.NewFrame(e.stack.Top()), w.Body.Statements)
 		if err != nil {
 			return nil, err
 		}
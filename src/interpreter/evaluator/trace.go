@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Traceback: the functions that were running when a program ran into an error
+// ------------------------------------------------------------------------------------------------------------------
+
+// Frame is a function that was running, Pos is where in it the program was: the call to the next frame, or the
+// error for the last one. File is empty for the file being run
+type Frame struct {
+	Function string
+	File     string
+	Pos      lexer.Position
+}
+
+// String formats f like a line of a traceback, eg: lib.kol:4:9 in `parse`
+func (f Frame) String() string {
+	if !f.Pos.IsValid() {
+		return "in `" + f.Function + "`"
+	}
+	if f.File == "" {
+		return f.Pos.String() + " in `" + f.Function + "`"
+	}
+	return f.File + ":" + f.Pos.String() + " in `" + f.Function + "`"
+}
+
+// RuntimeError is an error a program ran into while running, Stack is the frames that were running, `main` (or the
+// function the host called) first
+type RuntimeError struct {
+	Err   error
+	Stack []Frame
+}
+
+func (r *RuntimeError) Error() string { return r.Err.Error() }
+
+func (r *RuntimeError) Unwrap() error { return r.Err }
+
+// Traceback formats the stack the way the kolon command prints it, the last call last. a frame repeated more than 3
+// times in a row (a recursion) is only written 3 times
+func (r *RuntimeError) Traceback() string {
+	lines := []string{"Traceback (most recent call last):"}
+	repeated := 0
+	for i, f := range r.Stack {
+		if i > 0 && f == r.Stack[i-1] {
+			repeated++
+		} else {
+			lines = appendRepeated(lines, repeated)
+			repeated = 0
+		}
+		if repeated < 3 {
+			lines = append(lines, "    "+f.String())
+		}
+	}
+	lines = appendRepeated(lines, repeated)
+	return strings.Join(lines, "\n")
+}
+
+func appendRepeated(lines []string, repeated int) []string {
+	if repeated < 3 {
+		return lines
+	}
+	if repeated == 3 {
+		return append(lines, "    ... the frame above 1 more time")
+	}
+	return append(lines, "    ... the frame above "+strconv.Itoa(repeated-2)+" more times")
+}
+
+// call is a function running in the evaluator, pos is where it was called from, unknown for `main` and a function
+// called by the host
+type call struct {
+	function string
+	file     string
+	pos      lexer.Position
+}
+
+// enter records the call of function (declared in file) from pos, it's taken off by the returned func
+func (e *Evaluator) enter(function string, file string, pos lexer.Position) (func(), error) {
+	if len(e.calls) >= e.limits.Depth() {
+		return nil, limitError(DepthLimit, int64(e.limits.Depth()), nil)
+	}
+	e.calls = append(e.calls, call{function: function, file: file, pos: pos})
+	return func() { e.calls = e.calls[:len(e.calls)-1] }, nil
+}
+
+// currentFile is the file of the function running, empty for the file being run
+func (e *Evaluator) currentFile() string {
+	if len(e.calls) == 0 {
+		return e.file
+	}
+	return e.calls[len(e.calls)-1].file
+}
+
+// traced gives err the frames running where it happened, unless a node inside the one that failed already did
+func (e *Evaluator) traced(err error) error {
+	var r *RuntimeError
+	if errors.As(err, &r) {
+		return err
+	}
+	if file := e.currentFile(); file != "" {
+		err = diagnostic.WithFile(err, file)
+	}
+	pos := diagnostic.Pos(err)
+	stack := make([]Frame, len(e.calls))
+	for i, c := range e.calls {
+		stack[i] = Frame{Function: c.function, File: c.file, Pos: pos}
+		if i+1 < len(e.calls) {
+			stack[i].Pos = e.calls[i+1].pos
+		}
+	}
+	return &RuntimeError{Err: err, Stack: stack}
+}
@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/builtins"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/code"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// Closure is a function used as a value, Free are the cells of the variables it captured from the functions
+// around it
+type Closure struct {
+	Fn       *code.Function
+	Free     []*Cell
+	FuncType string
+}
+
+func (c *Closure) Inspect() string         { return c.FuncType }
+func (c *Closure) Type() object.ObjectType { return object.FUNC_OBJ }
+
+// Cell holds a variable a closure captured, the function declaring it and the closure both read and write it
+type Cell struct {
+	Value object.Object
+}
+
+func (c *Cell) Inspect() string         { return object.Display(c.Value) }
+func (c *Cell) Type() object.ObjectType { return c.Value.Type() }
+
+// iterator goes over the items of a for-each loop, vars are the values its variables get in the current iteration
+type iterator struct {
+	*evaluator.ForEachIter
+	vars []object.Object
+}
+
+func (it *iterator) Inspect() string         { return "iterator" }
+func (it *iterator) Type() object.ObjectType { return object.ARRAY_OBJ }
+
+// infix runs the operation of op on two integers here, anything else goes to the evaluator
+func infix(op code.Opcode, left, right object.Object) (object.Object, error) {
+	l, ok := left.(*object.Integer)
+	if !ok {
+		return evaluator.Infix(code.OperatorOf(op), left, right)
+	}
+	r, ok := right.(*object.Integer)
+	if !ok {
+		return evaluator.Infix(code.OperatorOf(op), left, right)
+	}
+	switch op {
+	case code.OpAdd:
+		return &object.Integer{Value: l.Value + r.Value}, nil
+	case code.OpSub:
+		return &object.Integer{Value: l.Value - r.Value}, nil
+	case code.OpMul:
+		return &object.Integer{Value: l.Value * r.Value}, nil
+	case code.OpDiv:
+		if r.Value == 0 {
+			return nil, errors.New("integer division by zero")
+		}
+		return &object.Integer{Value: l.Value / r.Value}, nil
+	case code.OpMod:
+		if r.Value == 0 {
+			return nil, errors.New("modulo by zero")
+		}
+		return &object.Integer{Value: l.Value % r.Value}, nil
+	case code.OpGreater:
+		return boolean(l.Value > r.Value), nil
+	case code.OpLess:
+		return boolean(l.Value < r.Value), nil
+	case code.OpGreaterEqual:
+		return boolean(l.Value >= r.Value), nil
+	case code.OpLessEqual:
+		return boolean(l.Value <= r.Value), nil
+	case code.OpEqual:
+		return boolean(l.Value == r.Value), nil
+	case code.OpNotEqual:
+		return boolean(l.Value != r.Value), nil
+	}
+	return evaluator.Infix(code.OperatorOf(op), left, right)
+}
+
+// interpolate puts values between parts, the way print writes them out
+func interpolate(parts []string, values []object.Object) string {
+	var out strings.Builder
+	for i, part := range parts {
+		out.WriteString(part)
+		if i == len(values) {
+			break
+		}
+		out.WriteString(builtins.Printable(values[i]))
+	}
+	return out.String()
+}
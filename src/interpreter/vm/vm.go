@@ -0,0 +1,471 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/KhushPatibandha/Kolon/src/builtins"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/code"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// VM: runs the bytecode of a program on a stack, the locals of a call live in the slots right above its arguments
+// and the values it works on above them. it gives the same output and the same errors as the evaluator, the
+// operations on values that can fail are shared with it
+// ------------------------------------------------------------------------------------------------------------------
+type VM struct {
+	constants []object.Object
+	functions []*code.Function
+	main      int
+
+	// closures of the declared functions, for the frames of the calls that don't go through a value
+	declared []*Closure
+	names    map[string]int // the functions the program can call, by name
+
+	// runs the builtins, with the streams `print` and `scan` use
+	host *evaluator.Evaluator
+
+	stack []object.Object
+	sp    int
+
+	frames []frame
+
+	ctx    context.Context
+	limits evaluator.Limits
+	steps  int64
+}
+
+// frame is a call being run, bp is where its locals start and base is where the stack goes back to when it returns
+type frame struct {
+	closure *Closure
+	ip      int
+	bp      int
+	base    int
+}
+
+func New(bytecode *code.Bytecode) *VM {
+	declared := make([]*Closure, len(bytecode.Functions))
+	for i, fn := range bytecode.Functions {
+		declared[i] = &Closure{Fn: fn}
+	}
+	return &VM{
+		constants: bytecode.Constants,
+		functions: bytecode.Functions,
+		main:      bytecode.Main,
+		declared:  declared,
+		names:     bytecode.Names,
+		host:      evaluator.New(false),
+		stack:     make([]object.Object, 2048),
+	}
+}
+
+// SetIO makes `print` write to out and `scan` read from in, instead of the standard output and input
+func (vm *VM) SetIO(out io.Writer, in io.Reader) { vm.host.SetIO(out, in) }
+
+// SetBuiltins runs the builtins the program calls from r, the registry it was type checked with
+func (vm *VM) SetBuiltins(r *builtins.Registry) { vm.host.SetBuiltins(r) }
+
+// SetLimits stops the program once it goes over limits or ctx is done, like the evaluator does
+func (vm *VM) SetLimits(ctx context.Context, limits evaluator.Limits) {
+	vm.ctx = ctx
+	vm.limits = limits
+}
+
+// Run calls `main`, if the program has one
+func (vm *VM) Run() error {
+	if vm.main < 0 {
+		return nil
+	}
+	if err := vm.call(vm.declared[vm.main], 0, 0); err != nil {
+		return err
+	}
+	return vm.run()
+}
+
+// Call runs the function name declared at the top of the program with args and returns what it returned
+func (vm *VM) Call(name string, args []object.Object) (object.Object, error) {
+	fn, ok := vm.names[name]
+	if !ok {
+		return nil, fmt.Errorf("function `%s` not found", name)
+	}
+	base := vm.sp
+	for _, arg := range args {
+		vm.push(arg)
+	}
+	err := vm.call(vm.declared[fn], len(args), base)
+	if err == nil {
+		err = vm.run()
+	}
+	if err != nil {
+		// the frames the error left behind are dropped, so the vm can still be called
+		for i := base; i < vm.sp; i++ {
+			vm.stack[i] = nil
+		}
+		vm.sp = base
+		vm.frames = vm.frames[:0]
+		return nil, err
+	}
+	return vm.pop(), nil
+}
+
+// call starts a frame for closure, its argc arguments are on top of the stack
+func (vm *VM) call(closure *Closure, argc int, base int) error {
+	if len(vm.frames) >= vm.limits.Depth() {
+		return limitError(evaluator.DepthLimit, int64(vm.limits.Depth()), nil)
+	}
+	bp := vm.sp - argc
+	top := bp + closure.Fn.NumLocals
+	vm.reserve(top)
+	for i := vm.sp; i < top; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = top
+	vm.frames = append(vm.frames, frame{closure: closure, bp: bp, base: base})
+	return nil
+}
+
+// reserve grows the stack so it has room for n values
+func (vm *VM) reserve(n int) {
+	if n <= len(vm.stack) {
+		return
+	}
+	size := 2 * len(vm.stack)
+	for size < n {
+		size *= 2
+	}
+	stack := make([]object.Object, size)
+	copy(stack, vm.stack[:vm.sp])
+	vm.stack = stack
+}
+
+func (vm *VM) push(o object.Object) {
+	if vm.sp == len(vm.stack) {
+		vm.reserve(vm.sp + 1)
+	}
+	vm.stack[vm.sp] = o
+	vm.sp++
+}
+
+func (vm *VM) pop() object.Object {
+	vm.sp--
+	o := vm.stack[vm.sp]
+	vm.stack[vm.sp] = nil
+	return o
+}
+
+// popN takes the n values on top of the stack off it, in the order they were pushed
+func (vm *VM) popN(n int) []object.Object {
+	if n == 0 {
+		return nil
+	}
+	values := make([]object.Object, n)
+	copy(values, vm.stack[vm.sp-n:vm.sp])
+	for i := vm.sp - n; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp -= n
+	return values
+}
+
+func boolean(b bool) object.Object {
+	if b {
+		return evaluator.TRUE.Value
+	}
+	return evaluator.FALSE.Value
+}
+
+func (vm *VM) run() error {
+	for len(vm.frames) != 0 {
+		fr := &vm.frames[len(vm.frames)-1]
+		ins := fr.closure.Fn.Instructions
+		start := fr.ip
+		op := code.Opcode(ins[fr.ip])
+		fr.ip++
+
+		vm.steps++
+		if vm.limits.MaxSteps > 0 && vm.steps > vm.limits.MaxSteps {
+			return vm.traced(start, limitError(evaluator.StepLimit, vm.limits.MaxSteps, nil))
+		}
+		if vm.ctx != nil && vm.steps&1023 == 0 {
+			if err := vm.ctx.Err(); err != nil {
+				return vm.traced(start, limitError(evaluator.Stopped, 0, err))
+			}
+		}
+
+		var err error
+		switch op {
+		case code.OpConstant:
+			vm.push(vm.constants[vm.operand(fr, ins)])
+		case code.OpTrue:
+			vm.push(evaluator.TRUE.Value)
+		case code.OpFalse:
+			vm.push(evaluator.FALSE.Value)
+		case code.OpNull:
+			vm.push(nil)
+		case code.OpPop:
+			vm.pop()
+		case code.OpDup:
+			vm.push(vm.stack[vm.sp-1])
+		case code.OpDup2:
+			vm.push(vm.stack[vm.sp-2])
+			vm.push(vm.stack[vm.sp-2])
+
+		case code.OpGetLocal:
+			vm.push(vm.stack[fr.bp+vm.operand(fr, ins)])
+		case code.OpSetLocal:
+			vm.stack[fr.bp+vm.operand(fr, ins)] = vm.pop()
+		case code.OpNewCell:
+			vm.stack[fr.bp+vm.operand(fr, ins)] = &Cell{Value: vm.pop()}
+		case code.OpGetCell:
+			vm.push(vm.stack[fr.bp+vm.operand(fr, ins)].(*Cell).Value)
+		case code.OpSetCell:
+			vm.stack[fr.bp+vm.operand(fr, ins)].(*Cell).Value = vm.pop()
+		case code.OpLoadCell:
+			vm.push(vm.stack[fr.bp+vm.operand(fr, ins)])
+		case code.OpGetFree:
+			vm.push(fr.closure.Free[vm.byteOperand(fr, ins)].Value)
+		case code.OpSetFree:
+			fr.closure.Free[vm.byteOperand(fr, ins)].Value = vm.pop()
+		case code.OpLoadFree:
+			vm.push(fr.closure.Free[vm.byteOperand(fr, ins)])
+
+		case code.OpClosure:
+			fn := vm.operand(fr, ins)
+			typ := vm.operand(fr, ins)
+			n := vm.byteOperand(fr, ins)
+			var free []*Cell
+			if n != 0 {
+				free = make([]*Cell, n)
+				for i, cell := range vm.popN(n) {
+					free[i] = cell.(*Cell)
+				}
+			}
+			vm.push(&Closure{
+				Fn:       vm.functions[fn],
+				Free:     free,
+				FuncType: vm.constants[typ].(*object.String).Value,
+			})
+		case code.OpCall:
+			argc := vm.byteOperand(fr, ins)
+			closure := vm.stack[vm.sp-1-argc].(*Closure)
+			err = vm.call(closure, argc, vm.sp-1-argc)
+		case code.OpCallFunction:
+			fn := vm.operand(fr, ins)
+			argc := vm.byteOperand(fr, ins)
+			err = vm.call(vm.declared[fn], argc, vm.sp-argc)
+		case code.OpCallBuiltin:
+			name := vm.constants[vm.operand(fr, ins)].(*object.String).Value
+			args := vm.popN(vm.byteOperand(fr, ins))
+			var r object.Object
+			r, err = vm.host.Builtin(name, args)
+			if err == nil {
+				err = vm.alloc(r)
+				vm.push(r)
+			}
+		case code.OpReturnValue:
+			r := vm.pop()
+			vm.ret(fr)
+			vm.push(r)
+		case code.OpReturn:
+			vm.ret(fr)
+			vm.push(nil)
+
+		case code.OpJump:
+			fr.ip = int(code.ReadUint16(ins[fr.ip:]))
+		case code.OpJumpNotTrue:
+			addr := vm.operand(fr, ins)
+			if vm.pop() != evaluator.TRUE.Value {
+				fr.ip = addr
+			}
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod, code.OpBitAnd, code.OpBitOr,
+			code.OpAnd, code.OpOr, code.OpEqual, code.OpNotEqual,
+			code.OpGreater, code.OpLess, code.OpGreaterEqual, code.OpLessEqual:
+			right := vm.pop()
+			left := vm.pop()
+			var r object.Object
+			r, err = infix(op, left, right)
+			if err == nil {
+				err = vm.alloc(r)
+				vm.push(r)
+			}
+		case code.OpMinus:
+			switch right := vm.pop().(type) {
+			case *object.Float:
+				vm.push(&object.Float{Value: -right.Value})
+			case *object.Integer:
+				vm.push(&object.Integer{Value: -right.Value})
+			}
+		case code.OpBang:
+			vm.push(boolean(vm.pop() != evaluator.TRUE.Value))
+		case code.OpInc, code.OpDec:
+			d := int64(1)
+			if op == code.OpDec {
+				d = -1
+			}
+			switch left := vm.pop().(type) {
+			case *object.Float:
+				vm.push(&object.Float{Value: left.Value + float64(d)})
+			case *object.Integer:
+				vm.push(&object.Integer{Value: left.Value + d})
+			}
+
+		case code.OpArray:
+			a := &object.Array{Elements: vm.popN(vm.operand(fr, ins))}
+			err = vm.alloc(a)
+			vm.push(a)
+		case code.OpHashMap:
+			values := vm.popN(2 * vm.operand(fr, ins))
+			h := object.NewHashMap()
+			for i := 0; i < len(values); i += 2 {
+				key, ok := values[i].(object.Hashable)
+				if !ok {
+					err = errors.New("unusable as hash key: " + string(values[i].Type()))
+					break
+				}
+				h.Set(key, values[i+1])
+			}
+			if err == nil {
+				err = vm.alloc(h)
+			}
+			vm.push(h)
+		case code.OpStruct:
+			proto := vm.constants[vm.operand(fr, ins)].(*code.StructPrototype)
+			values := vm.popN(len(proto.Fields))
+			if values == nil {
+				values = []object.Object{}
+			}
+			vm.push(&object.Struct{Name: proto.Name, Fields: proto.Fields, Values: values})
+		case code.OpEnum:
+			proto := vm.constants[vm.operand(fr, ins)].(*code.EnumPrototype)
+			values := vm.popN(vm.byteOperand(fr, ins))
+			vm.push(&object.EnumValue{Enum: proto.Enum, Variant: proto.Variant, Index: proto.Index, Values: values})
+		case code.OpInterpolate:
+			parts := vm.constants[vm.operand(fr, ins)].(*code.Parts)
+			str := &object.String{Value: interpolate(parts.Values, vm.popN(vm.byteOperand(fr, ins)))}
+			err = vm.alloc(str)
+			vm.push(str)
+		case code.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			var r object.Object
+			r, err = evaluator.Index(left, index)
+			if err == nil {
+				vm.push(r)
+			}
+		case code.OpSetIndex:
+			keep := vm.byteOperand(fr, ins)
+			value := vm.pop()
+			index := vm.pop()
+			holder := vm.pop()
+			n := 0
+			if m, ok := holder.(*object.HashMap); ok {
+				n = m.Len()
+			}
+			err = evaluator.SetIndex(holder, index, value)
+			// a new key makes the hashmap bigger, like a push makes an array bigger
+			if m, ok := holder.(*object.HashMap); ok && err == nil && m.Len() > n {
+				err = vm.alloc(m)
+			}
+			if keep == 1 {
+				vm.push(value)
+			}
+		case code.OpGetField:
+			vm.push(vm.pop().(*object.Struct).Values[vm.operand(fr, ins)])
+		case code.OpSetField:
+			field := vm.operand(fr, ins)
+			keep := vm.byteOperand(fr, ins)
+			value := vm.pop()
+			vm.pop().(*object.Struct).Values[field] = value
+			if keep == 1 {
+				vm.push(value)
+			}
+		case code.OpUnpack:
+			vm.push(vm.pop().(*object.Array).Elements[vm.byteOperand(fr, ins)])
+
+		case code.OpIterStart:
+			n := vm.byteOperand(fr, ins)
+			vm.push(&iterator{ForEachIter: evaluator.NewForEachIter(vm.pop()), vars: make([]object.Object, n)})
+		case code.OpIterNext:
+			it := vm.stack[fr.bp+vm.operand(fr, ins)].(*iterator)
+			addr := vm.operand(fr, ins)
+			if !it.Next(it.vars) {
+				fr.ip = addr
+				break
+			}
+			for _, v := range it.vars {
+				vm.push(v)
+			}
+		case code.OpIsVariant:
+			idx := vm.operand(fr, ins)
+			vm.push(boolean(vm.pop().(*object.EnumValue).Index == idx))
+		case code.OpVariantValue:
+			i := vm.byteOperand(fr, ins)
+			vm.push(vm.pop().(*object.EnumValue).Values[i])
+		}
+
+		if err != nil {
+			return vm.traced(start, err)
+		}
+	}
+	return nil
+}
+
+// alloc checks o, a value just made, against the allocation limit
+func (vm *VM) alloc(o object.Object) error {
+	if vm.limits.AllocExceeded(o) {
+		return limitError(evaluator.AllocLimit, int64(vm.limits.MaxAlloc), nil)
+	}
+	return nil
+}
+
+func limitError(kind evaluator.LimitKind, limit int64, err error) *evaluator.LimitError {
+	return &evaluator.LimitError{Kind: kind, Limit: limit, Err: err}
+}
+
+// traced gives err, which the instruction at start of the last frame ran into, its position and the frames that
+// were running, each one at the call to the next
+func (vm *VM) traced(start int, err error) error {
+	last := vm.frames[len(vm.frames)-1].closure.Fn
+	err = diagnostic.Wrap(last.PosAt(start), err)
+	if last.File != "" {
+		err = diagnostic.WithFile(err, last.File)
+	}
+	stack := make([]evaluator.Frame, len(vm.frames))
+	for i, fr := range vm.frames {
+		stack[i] = evaluator.Frame{Function: fr.closure.Fn.Name, File: fr.closure.Fn.File}
+		if i+1 < len(vm.frames) {
+			stack[i].Pos = fr.closure.Fn.PosAt(fr.ip - 1)
+		} else {
+			stack[i].Pos = diagnostic.Pos(err)
+		}
+	}
+	return &evaluator.RuntimeError{Err: err, Stack: stack}
+}
+
+// ret ends the frame fr, taking its locals (and the closure it was called through) off the stack
+func (vm *VM) ret(fr *frame) {
+	for i := fr.base; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = fr.base
+	vm.frames = vm.frames[:len(vm.frames)-1]
+}
+
+// operand reads the 2 byte operand at the instruction pointer of fr
+func (vm *VM) operand(fr *frame, ins code.Instructions) int {
+	v := int(code.ReadUint16(ins[fr.ip:]))
+	fr.ip += 2
+	return v
+}
+
+func (vm *VM) byteOperand(fr *frame, ins code.Instructions) int {
+	v := int(ins[fr.ip])
+	fr.ip++
+	return v
+}
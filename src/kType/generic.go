@@ -0,0 +1,174 @@
+package ktype
+
+// ------------------------------------------------------------------------------------------------------------------
+// Generics: Type parameters are inferred at each call by unifying the parameter types of the function with the
+// types of the arguments, the bindings found are then substituted into its return types
+// ------------------------------------------------------------------------------------------------------------------
+func NewTypeParam(name string) *Type {
+	ty := &Type{
+		Kind: TypeParam,
+		Name: name,
+	}
+	return InternType(ty)
+}
+
+// HasTypeParams reports whether a type parameter appears anywhere in t
+func (t *Type) HasTypeParams() bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind {
+	case TypeParam:
+		return true
+	case TypeArray:
+		return t.ElementType.HasTypeParams()
+	case TypeHashMap:
+		return t.KeyType.HasTypeParams() || t.ValueType.HasTypeParams()
+	case TypeFunction:
+		for _, param := range t.ParamTypes {
+			if param.HasTypeParams() {
+				return true
+			}
+		}
+		for _, ret := range t.ReturnTypes {
+			if ret.HasTypeParams() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TypeParams returns the type parameters appearing in types, in the order they first appear
+func TypeParams(types []*Type) []*Type {
+	var out []*Type
+	seen := map[string]bool{}
+	var walk func(t *Type)
+	walk = func(t *Type) {
+		if t == nil {
+			return
+		}
+		switch t.Kind {
+		case TypeParam:
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				out = append(out, t)
+			}
+		case TypeArray:
+			walk(t.ElementType)
+		case TypeHashMap:
+			walk(t.KeyType)
+			walk(t.ValueType)
+		case TypeFunction:
+			for _, param := range t.ParamTypes {
+				walk(param)
+			}
+			for _, ret := range t.ReturnTypes {
+				walk(ret)
+			}
+		}
+	}
+	for _, t := range types {
+		walk(t)
+	}
+	return out
+}
+
+// NewBindings returns the bindings for Unify to infer typeParams in, type parameters not in it (eg: those of the
+// function whose body is being checked) are treated like any other type
+func NewBindings(typeParams []*Type) map[string]*Type {
+	bindings := map[string]*Type{}
+	for _, param := range typeParams {
+		bindings[param.Name] = nil
+	}
+	return bindings
+}
+
+// Unify matches param (which may contain type parameters) against arg, recording what each type parameter
+// stands for in bindings. it returns false if arg can't be passed for param. the element types of an empty
+// array or hashmap literal are unknown, they don't bind anything
+func Unify(param, arg *Type, bindings map[string]*Type) bool {
+	if param == nil || arg == nil {
+		return true
+	}
+	switch param.Kind {
+	case TypeParam:
+		bound, ok := bindings[param.Name]
+		if !ok {
+			return param.Equals(arg)
+		}
+		if bound != nil {
+			if bound.incomplete() {
+				bindings[param.Name] = arg
+				return arg.Equals(bound)
+			}
+			return bound.Equals(arg)
+		}
+		bindings[param.Name] = arg
+		return true
+	case TypeArray:
+		if arg.Kind != TypeArray {
+			return false
+		}
+		return Unify(param.ElementType, arg.ElementType, bindings)
+	case TypeHashMap:
+		if arg.Kind != TypeHashMap {
+			return false
+		}
+		return Unify(param.KeyType, arg.KeyType, bindings) && Unify(param.ValueType, arg.ValueType, bindings)
+	case TypeFunction:
+		if arg.Kind != TypeFunction ||
+			len(param.ParamTypes) != len(arg.ParamTypes) ||
+			len(param.ReturnTypes) != len(arg.ReturnTypes) {
+			return false
+		}
+		for i := range param.ParamTypes {
+			if !Unify(param.ParamTypes[i], arg.ParamTypes[i], bindings) {
+				return false
+			}
+		}
+		for i := range param.ReturnTypes {
+			if !Unify(param.ReturnTypes[i], arg.ReturnTypes[i], bindings) {
+				return false
+			}
+		}
+		return true
+	default:
+		return param.Equals(arg)
+	}
+}
+
+// Substitute returns t with the type parameters in bindings replaced by what they stand for, type parameters
+// that aren't bound are left as they are
+func Substitute(t *Type, bindings map[string]*Type) *Type {
+	if t == nil || !t.HasTypeParams() {
+		return t
+	}
+	switch t.Kind {
+	case TypeParam:
+		if bound := bindings[t.Name]; bound != nil {
+			return bound
+		}
+		return t
+	case TypeArray:
+		return NewArrayType(Substitute(t.ElementType, bindings))
+	case TypeHashMap:
+		return NewHashMapType(Substitute(t.KeyType, bindings), Substitute(t.ValueType, bindings))
+	default:
+		params := make([]*Type, len(t.ParamTypes))
+		for i, param := range t.ParamTypes {
+			params[i] = Substitute(param, bindings)
+		}
+		var returns []*Type
+		for _, ret := range t.ReturnTypes {
+			returns = append(returns, Substitute(ret, bindings))
+		}
+		return NewFunctionType(params, returns)
+	}
+}
+
+// incomplete reports whether t is the type of an empty array or hashmap literal, whose element types are unknown
+func (t *Type) incomplete() bool {
+	return t.Kind == TypeArray && t.ElementType == nil ||
+		t.Kind == TypeHashMap && t.KeyType == nil && t.ValueType == nil
+}
@@ -1,5 +1,7 @@
 package ktype
 
+import "github.com/KhushPatibandha/Kolon/src/lexer"
+
 func NewBaseType(t string) *Type {
 	ty := &Type{
 		Kind: TypeBase,
@@ -25,11 +27,53 @@ func NewHashMapType(kt, vt *Type) *Type {
 	return InternType(ty)
 }
 
+func NewStructType(name string) *Type {
+	ty := &Type{
+		Kind: TypeStruct,
+		Name: name,
+	}
+	return InternType(ty)
+}
+
+func NewEnumType(name string) *Type {
+	ty := &Type{
+		Kind: TypeEnum,
+		Name: name,
+	}
+	return InternType(ty)
+}
+
+func NewInvalidType() *Type {
+	ty := &Type{
+		Kind: TypeInvalid,
+	}
+	return InternType(ty)
+}
+
+func NewFunctionType(params, returns []*Type) *Type {
+	ty := &Type{
+		Kind:        TypeFunction,
+		ParamTypes:  params,
+		ReturnTypes: returns,
+	}
+	return InternType(ty)
+}
+
+// WithToken returns a copy of t that remembers where in the source it was written, the copy is not interned
+func (t *Type) WithToken(token lexer.Token) *Type {
+	ty := *t
+	ty.Token = token
+	return &ty
+}
+
 func (t *Type) Equals(other *Type) bool {
 	if t == other {
 		return true
 	}
 	// fmt.Println("curr type and other type check miss")
+	if other != nil && (t.Kind == TypeInvalid || other.Kind == TypeInvalid) {
+		return true
+	}
 	if other == nil || t.Kind != other.Kind {
 		return false
 	}
@@ -48,6 +92,23 @@ func (t *Type) Equals(other *Type) bool {
 			return true
 		}
 		return t.ElementType.Equals(other.ElementType)
+	case TypeStruct, TypeEnum, TypeParam:
+		return t.Name == other.Name
+	case TypeFunction:
+		if len(t.ParamTypes) != len(other.ParamTypes) || len(t.ReturnTypes) != len(other.ReturnTypes) {
+			return false
+		}
+		for i, param := range t.ParamTypes {
+			if !param.Equals(other.ParamTypes[i]) {
+				return false
+			}
+		}
+		for i, ret := range t.ReturnTypes {
+			if !ret.Equals(other.ReturnTypes[i]) {
+				return false
+			}
+		}
+		return true
 	default:
 		if other.Kind != TypeHashMap {
 			return false
@@ -69,6 +130,16 @@ func (t *Type) TypeKindToString() string {
 		return "TypeArray"
 	case TypeHashMap:
 		return "TypeHashMap"
+	case TypeStruct:
+		return "TypeStruct"
+	case TypeEnum:
+		return "TypeEnum"
+	case TypeFunction:
+		return "TypeFunction"
+	case TypeParam:
+		return "TypeParam"
+	case TypeInvalid:
+		return "TypeInvalid"
 	default:
 		return "UnknownTypeKind"
 	}
@@ -1,12 +1,29 @@
 package ktype
 
-var typePool = make(map[string]*Type)
+import "sync"
+
+// the pool is shared by every program, embedders can compile and run programs on more than one goroutine
+var (
+	typePool   = make(map[string]*Type)
+	typePoolMu sync.Mutex
+)
 
 func InternType(t *Type) *Type {
 	if t == nil {
 		return nil
 	}
+	// the type of an empty array or hashmap literal gets its element types from whatever it's first compared
+	// with (see Equals), every literal needs its own
+	if t.incomplete() {
+		return t
+	}
 	key := t.String()
+	// a type parameter can share its name with a struct or an enum declared somewhere else
+	if t.HasTypeParams() {
+		key = "<generic>" + key
+	}
+	typePoolMu.Lock()
+	defer typePoolMu.Unlock()
 	if existing, ok := typePool[key]; ok {
 		return existing
 	}
@@ -15,5 +32,7 @@ func InternType(t *Type) *Type {
 }
 
 func ResetTypePool() {
+	typePoolMu.Lock()
+	defer typePoolMu.Unlock()
 	typePool = make(map[string]*Type)
 }
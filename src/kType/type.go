@@ -12,9 +12,14 @@ import (
 type TypeKind int
 
 const (
-	TypeBase    TypeKind = iota // For BaseTypes -- Refer to BaseType interface
-	TypeArray                   // For Array types
-	TypeHashMap                 // For HashMap types
+	TypeBase     TypeKind = iota // For BaseTypes -- Refer to BaseType interface
+	TypeArray                    // For Array types
+	TypeHashMap                  // For HashMap types
+	TypeStruct                   // For user defined structs
+	TypeEnum                     // For user defined enums
+	TypeFunction                 // For functions used as values
+	TypeParam                    // For type parameters of generic functions
+	TypeInvalid                  // For names whose declaration failed, compatible with every other type
 )
 
 type TypeCheckResult struct {
@@ -36,6 +41,21 @@ type Type struct {
 	// For HashMap types -- Kind == TypeHashMap
 	KeyType   *Type
 	ValueType *Type
+
+	// For Struct types -- Kind == TypeStruct, Name is the name of the struct
+	// its fields are looked up from the declaration in the environment
+	// same goes for Enum types -- Kind == TypeEnum, and their variants
+
+	// For Function types -- Kind == TypeFunction
+	// eg: fun(int, int): (int)
+	ParamTypes  []*Type
+	ReturnTypes []*Type
+
+	// For Type parameters -- Kind == TypeParam, Name is the name of the parameter
+	// eg: T in `fun: first<T>(a: T[]): (T)`
+
+	// For names whose declaration failed -- Kind == TypeInvalid, the error is already reported so their
+	// uses aren't checked any further
 }
 
 func (t *Type) TokenValue() string { return t.Token.Value }
@@ -51,6 +71,16 @@ func (t *Type) String() string {
 			return "unknown[]"
 		}
 		return fmt.Sprintf("%s[]", t.ElementType.String())
+	case TypeStruct, TypeEnum, TypeParam:
+		return t.Name
+	case TypeInvalid:
+		return "<invalid>"
+	case TypeFunction:
+		out := "fun(" + joinTypes(t.ParamTypes) + ")"
+		if len(t.ReturnTypes) != 0 {
+			out += ": (" + joinTypes(t.ReturnTypes) + ")"
+		}
+		return out
 	default:
 		if t.KeyType == nil && t.ValueType == nil {
 			return "unknown[unknown]"
@@ -58,3 +88,14 @@ func (t *Type) String() string {
 		return fmt.Sprintf("%s[%s]", t.KeyType.String(), t.ValueType.String())
 	}
 }
+
+func joinTypes(types []*Type) string {
+	out := ""
+	for i, t := range types {
+		if i != 0 {
+			out += ", "
+		}
+		out += t.String()
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package lexer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Escape Sequences: `\n`, `\t`, `\r`, `\\`, `\"`, `\'`, `\$` and `\u{...}` (1 to 6 hex digits) in strings and chars
+// ------------------------------------------------------------------------------------------------------------------
+
+// readEscape decodes the escape sequence at the start of s, returning the character and how many bytes it
+// took up. the length is 0 if s doesn't start with a valid escape sequence
+func readEscape(s string) (rune, int) {
+	if len(s) < 2 || s[0] != '\\' {
+		return 0, 0
+	}
+	switch s[1] {
+	case 'n':
+		return '\n', 2
+	case 't':
+		return '\t', 2
+	case 'r':
+		return '\r', 2
+	case '\\', '"', '\'', '$':
+		return rune(s[1]), 2
+	case 'u':
+		if len(s) < 3 || s[2] != '{' {
+			return 0, 0
+		}
+		end := strings.IndexByte(s, '}')
+		if end < 4 || end > 9 {
+			return 0, 0
+		}
+		v, err := strconv.ParseUint(s[3:end], 16, 32)
+		if err != nil || v > unicode.MaxRune || (v >= 0xD800 && v <= 0xDFFF) {
+			return 0, 0
+		}
+		return rune(v), end + 1
+	}
+	return 0, 0
+}
+
+// badEscape returns the text of the invalid escape sequence at the start of s, for error messages
+func badEscape(s string) string {
+	if strings.HasPrefix(s, `\u{`) {
+		if end := strings.IndexByte(s, '}'); end != -1 && end < 16 {
+			return s[:end+1]
+		}
+		return `\u{`
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	return s[:1+size]
+}
+
+// Unquote returns the value of a STRING, CHAR or INTERP_* token, without the quotes (and `}`, `${` of an
+// interpolation) and with its escape sequences decoded. raw strings (between backticks) are returned as they are
+func Unquote(raw string) (string, error) {
+	if len(raw) >= 2 && raw[0] == '`' && raw[len(raw)-1] == '`' {
+		return raw[1 : len(raw)-1], nil
+	}
+
+	var body string
+	switch {
+	case len(raw) < 2 || !strings.ContainsRune("\"'}", rune(raw[0])):
+		return "", errors.New("invalid string/char literal: " + raw)
+	case strings.HasSuffix(raw[1:], "${"):
+		body = raw[1 : len(raw)-2]
+	case raw[len(raw)-1] == '"' || raw[len(raw)-1] == '\'':
+		body = raw[1 : len(raw)-1]
+	default:
+		return "", errors.New("invalid string/char literal: " + raw)
+	}
+	if !strings.Contains(body, `\`) {
+		return body, nil
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(body); {
+		if body[i] != '\\' {
+			out.WriteByte(body[i])
+			i++
+			continue
+		}
+		r, n := readEscape(body[i:])
+		if n == 0 {
+			return "", errors.New("invalid escape sequence `" + badEscape(body[i:]) + "`")
+		}
+		out.WriteRune(r)
+		i += n
+	}
+	return out.String(), nil
+}
+
+// Quote is the opposite of Unquote, it wraps value in quote (`"` or `'`), escaping whatever can't be written
+// between them as it is
+func Quote(value string, quote byte) string {
+	var out strings.Builder
+	out.WriteByte(quote)
+	for i, r := range value {
+		switch {
+		case r == '\\' || r == rune(quote) || (r == '$' && strings.HasPrefix(value[i:], "${")):
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case !unicode.IsPrint(r):
+			out.WriteString(`\u{` + strconv.FormatInt(int64(r), 16) + `}`)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteByte(quote)
+	return out.String()
+}
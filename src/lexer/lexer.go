@@ -1,167 +1,364 @@
 package lexer
 
 import (
-	"fmt"
-	"regexp"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-type regexHandler func(lex *Lexer, regex *regexp.Regexp)
-
-type regexPattern struct {
-	regex   *regexp.Regexp
-	handler regexHandler
-}
-
 type Lexer struct {
-	patterns []regexPattern
 	Tokens   []Token
 	source   string
 	position int
+	line     int
+	column   int
+
+	// string interpolations (`${ ... }`) the lexer is currently inside of, innermost last
+	interpolations []interpolation
+
+	trivia []Trivia // comments read since the last token, they go on the next one
 }
 
-func Tokenizer(source string) []Token {
+type interpolation struct {
+	start Position
+	depth int // curly brackets opened inside the interpolation and not closed yet
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// LexError: The source couldn't be split into tokens, Text is the part of it that was rejected
+// ------------------------------------------------------------------------------------------------------------------
+type LexError struct {
+	Text string
+	Pos  Position
+	Msg  string
+}
+
+func (e *LexError) Error() string { return e.Pos.String() + ": " + e.Msg }
+
+func Tokenizer(source string) ([]Token, error) {
 	lexer := createLexer(source)
 	for !lexer.atEOF() {
-		matched := false
-		for _, pattern := range lexer.patterns {
-			lineOfCode := pattern.regex.FindStringIndex(lexer.remainder())
-			if lineOfCode != nil && lineOfCode[0] == 0 {
-				pattern.handler(lexer, pattern.regex)
-				matched = true
-				break
-			}
+		if err := lexer.scan(); err != nil {
+			return nil, err
 		}
-		if !matched {
-			panic(fmt.Sprintf("Lexer error: unrecognized token '%v' near --> '%v'", lexer.remainder()[:1], lexer.remainder()))
+	}
+	if len(lexer.interpolations) != 0 {
+		return nil, &LexError{
+			Text: "${",
+			Pos:  lexer.interpolations[len(lexer.interpolations)-1].start,
+			Msg:  "unterminated string interpolation, expected a closing curly bracket (`}`)",
 		}
 	}
-	lexer.push(GetNewToken(EOF, "EOF"))
-	return lexer.Tokens
+	lexer.push(GetNewToken(EOF, "EOF"), 0)
+	return lexer.Tokens, nil
 }
 
 func createLexer(source string) *Lexer {
 	return &Lexer{
 		position: 0,
+		line:     1,
+		column:   1,
 		source:   source,
-		Tokens:   make([]Token, 0),
-		patterns: []regexPattern{
-			{regexp.MustCompile(`\t+`), skipHandler},
-			{regexp.MustCompile(`\s+`), skipHandler},
-			{regexp.MustCompile(`\/\/.*`), skipHandler},
-
-			{regexp.MustCompile(`else\s+if`), identifierHandler},
-
-			{regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*`), identifierHandler},
-
-			{regexp.MustCompile(`\d+\.\d+`), floatHandler(FLOAT)},
-			{regexp.MustCompile(`\d+`), intHandler(INT)},
-
-			{regexp.MustCompile(`"[^"]*"`), stringHandler(STRING)},
-			{regexp.MustCompile(`'[^']'`), stringHandler(CHAR)},
-			{regexp.MustCompile(`true`), defaultHandler(BOOL, "true")},
-			{regexp.MustCompile(`false`), defaultHandler(BOOL, "false")},
-
-			{regexp.MustCompile(`\[`), defaultHandler(OPEN_SQUARE_BRACKET, "[")},
-			{regexp.MustCompile(`\]`), defaultHandler(CLOSE_SQUARE_BRACKET, "]")},
-			{regexp.MustCompile(`\{`), defaultHandler(OPEN_CURLY_BRACKET, "{")},
-			{regexp.MustCompile(`\}`), defaultHandler(CLOSE_CURLY_BRACKET, "}")},
-			{regexp.MustCompile(`\(`), defaultHandler(OPEN_BRACKET, "(")},
-			{regexp.MustCompile(`\)`), defaultHandler(CLOSE_BRACKET, ")")},
-
-			{regexp.MustCompile(`==`), defaultHandler(DOUBLE_EQUAL, "==")},
-			{regexp.MustCompile(`!=`), defaultHandler(NOT_EQUAL, "!=")},
-			{regexp.MustCompile(`<=`), defaultHandler(LESS_THAN_EQUAL, "<=")},
-			{regexp.MustCompile(`>=`), defaultHandler(GREATER_THAN_EQUAL, ">=")},
-			{regexp.MustCompile(`<`), defaultHandler(LESS_THAN, "<")},
-			{regexp.MustCompile(`>`), defaultHandler(GREATER_THAN, ">")},
-
-			{regexp.MustCompile(`\+\+`), defaultHandler(PLUS_PLUS, "++")},
-			{regexp.MustCompile(`\+=`), defaultHandler(PLUS_EQUAL, "+=")},
-			{regexp.MustCompile(`\+`), defaultHandler(PLUS, "+")},
-			{regexp.MustCompile(`--`), defaultHandler(MINUS_MINUS, "--")},
-			{regexp.MustCompile(`-=`), defaultHandler(MINUS_EQUAL, "-=")},
-			{regexp.MustCompile(`-`), defaultHandler(DASH, "-")},
-			{regexp.MustCompile(`\*=`), defaultHandler(STAR_EQUAL, "*=")},
-			{regexp.MustCompile(`\*`), defaultHandler(STAR, "*")},
-			{regexp.MustCompile(`/=`), defaultHandler(SLASH_EQUAL, "/=")},
-			{regexp.MustCompile(`/`), defaultHandler(SLASH, "/")},
-			{regexp.MustCompile(`%=`), defaultHandler(PERCENT_EQUAL, "%=")},
-			{regexp.MustCompile(`%`), defaultHandler(PERCENT, "%")},
-
-			{regexp.MustCompile(`&&`), defaultHandler(AND_AND, "&&")},
-			{regexp.MustCompile(`\|\|`), defaultHandler(OR_OR, "||")},
-			{regexp.MustCompile(`&`), defaultHandler(AND, "&")},
-			{regexp.MustCompile(`\|`), defaultHandler(OR, "|")},
-
-			{regexp.MustCompile(`=`), defaultHandler(EQUAL_ASSIGN, "=")},
-			{regexp.MustCompile(`!`), defaultHandler(NOT, "!")},
-			{regexp.MustCompile(`:`), defaultHandler(COLON, ":")},
-			{regexp.MustCompile(`;`), defaultHandler(SEMI_COLON, ";")},
-			{regexp.MustCompile(`,`), defaultHandler(COMMA, ",")},
-		},
-	}
-}
-
-func skipHandler(lexer *Lexer, regex *regexp.Regexp) {
-	lexer.advanceN(regex.FindStringIndex(lexer.remainder())[1])
-}
-
-func defaultHandler(k TokenKind, v string) regexHandler {
-	return func(lex *Lexer, regex *regexp.Regexp) {
-		lex.push(GetNewToken(k, v))
-		lex.advanceN(len(v))
-	}
-}
-
-func floatHandler(k TokenKind) regexHandler {
-	return func(lex *Lexer, regex *regexp.Regexp) {
-		matchedString := regex.FindString(lex.remainder())
-		_, err := strconv.ParseFloat(matchedString, 64)
-		if err != nil {
-			panic(fmt.Sprintf("Lexer error: number handler error: %v", err))
+		Tokens:   make([]Token, 0, len(source)/4),
+	}
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Scanner: Looks at the next character to decide what kind of token starts there
+// ------------------------------------------------------------------------------------------------------------------
+func (lexer *Lexer) scan() error {
+	ch := lexer.peek(0)
+	switch {
+	case isSpace(ch):
+		lexer.advanceN(lexer.countWhile(0, isSpace))
+		return nil
+	case ch == '/' && lexer.peek(1) == '/':
+		end := strings.IndexByte(lexer.remainder(), '\n')
+		if end == -1 {
+			end = len(lexer.remainder())
+		}
+		start := Position{Line: lexer.line, Column: lexer.column}
+		text := strings.TrimRight(lexer.remainder()[:end], " \t\r\f")
+		lexer.trivia = append(lexer.trivia, Trivia{Text: text, Start: start})
+		lexer.advanceN(end)
+		return nil
+	case isLetter(ch):
+		lexer.scanIdentifier()
+		return nil
+	case isDigit(ch):
+		return lexer.scanNumber()
+	case ch == '"':
+		return lexer.scanString(STRING, INTERP_START)
+	case ch == '{' && len(lexer.interpolations) != 0:
+		lexer.interpolations[len(lexer.interpolations)-1].depth++
+	case ch == '}' && len(lexer.interpolations) != 0:
+		last := len(lexer.interpolations) - 1
+		if lexer.interpolations[last].depth == 0 {
+			lexer.interpolations = lexer.interpolations[:last]
+			return lexer.scanString(INTERP_END, INTERP_MIDDLE)
 		}
-		lex.push(GetNewToken(k, matchedString))
-		lex.advanceN(len(matchedString))
+		lexer.interpolations[last].depth--
+	case ch == '\'':
+		return lexer.scanChar()
+	case ch == '`':
+		return lexer.scanRawString()
 	}
+
+	if kind, n := operator(ch, lexer.peek(1)); n != 0 {
+		lexer.push(GetNewToken(kind, lexer.source[lexer.position:lexer.position+n]), n)
+		return nil
+	}
+	r, _ := utf8.DecodeRuneInString(lexer.remainder())
+	return lexer.error(string(r), "unrecognized token `"+string(r)+"`")
 }
 
-func intHandler(k TokenKind) regexHandler {
-	return func(lex *Lexer, regex *regexp.Regexp) {
-		matchedString := regex.FindString(lex.remainder())
-		if _, err := strconv.ParseInt(matchedString, 10, 64); err == nil {
-			lex.push(GetNewToken(k, matchedString))
-		} else {
-			if _, err := strconv.ParseUint(matchedString, 10, 64); err == nil {
-				lex.push(GetNewToken(k, matchedString))
-			} else {
-				panic(fmt.Sprintf("Lexer error: number handler error: %v", err))
+func (lexer *Lexer) scanIdentifier() {
+	n := lexer.countWhile(0, isAlphaNumeric)
+
+	// `else if` is read as a single token
+	if lexer.source[lexer.position:lexer.position+n] == "else" {
+		spaces := lexer.countWhile(n, isSpace)
+		if spaces != 0 && strings.HasPrefix(lexer.source[lexer.position+n+spaces:], "if") {
+			n += spaces + len("if")
+		}
+	}
+
+	value := lexer.source[lexer.position : lexer.position+n]
+	lexer.push(GetNewToken(LookupIdentifier(value), value), n)
+}
+
+func (lexer *Lexer) scanNumber() error {
+	n := lexer.countWhile(0, isDigit)
+	if lexer.peek(n) == '.' && isDigit(lexer.peek(n+1)) {
+		n += 1 + lexer.countWhile(n+1, isDigit)
+		value := lexer.source[lexer.position : lexer.position+n]
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return lexer.error(value, "invalid float literal `"+value+"`, "+numError(err))
+		}
+		lexer.push(GetNewToken(FLOAT, value), n)
+		return nil
+	}
+
+	value := lexer.source[lexer.position : lexer.position+n]
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+			return lexer.error(value, "invalid int literal `"+value+"`, "+numError(err))
+		}
+	}
+	lexer.push(GetNewToken(INT, value), n)
+	return nil
+}
+
+// scanString reads a string, or the part of one after an interpolation (starting at its `}`). the token is of
+// kind end if the string ends without another interpolation, and of kind interp if one starts
+func (lexer *Lexer) scanString(end TokenKind, interp TokenKind) error {
+	n := 1
+	for {
+		switch lexer.peek(n) {
+		case '"':
+			n++
+			lexer.push(GetNewToken(end, lexer.source[lexer.position:lexer.position+n]), n)
+			return nil
+		case '$':
+			n++
+			if lexer.peek(n) == '{' {
+				n++
+				lexer.push(GetNewToken(interp, lexer.source[lexer.position:lexer.position+n]), n)
+				start := Position{Line: lexer.line, Column: lexer.column - len("${")}
+				lexer.interpolations = append(lexer.interpolations, interpolation{start: start})
+				return nil
+			}
+		case '\\':
+			size, err := lexer.scanEscape(n)
+			if err != nil {
+				return err
+			}
+			n += size
+		default:
+			if lexer.position+n >= len(lexer.source) {
+				return lexer.error("\"", "unterminated string literal")
 			}
+			n++
+		}
+	}
+}
+
+func (lexer *Lexer) scanRawString() error {
+	end := strings.IndexByte(lexer.source[lexer.position+1:], '`')
+	if end == -1 {
+		return lexer.error("`", "unterminated raw string literal")
+	}
+	n := end + 2
+	lexer.push(GetNewToken(STRING, lexer.source[lexer.position:lexer.position+n]), n)
+	return nil
+}
+
+func (lexer *Lexer) scanChar() error {
+	size := 0
+	switch lexer.peek(1) {
+	case '\\':
+		n, err := lexer.scanEscape(1)
+		if err != nil {
+			return err
+		}
+		size = n
+	case '\'':
+	default:
+		_, size = utf8.DecodeRuneInString(lexer.source[lexer.position+1:])
+	}
+	if size == 0 || lexer.peek(1+size) != '\'' {
+		return lexer.error("'", "invalid char literal, expected a single character between single quotes (`'`)")
+	}
+	n := size + 2
+	lexer.push(GetNewToken(CHAR, lexer.source[lexer.position:lexer.position+n]), n)
+	return nil
+}
+
+// scanEscape checks the escape sequence offset bytes ahead of the current position and returns its length
+func (lexer *Lexer) scanEscape(offset int) (int, error) {
+	rest := lexer.source[lexer.position+offset:]
+	if _, n := readEscape(rest); n != 0 {
+		return n, nil
+	}
+	text := badEscape(rest)
+	return 0, lexer.errorAt(offset, text, "invalid escape sequence `"+text+"`")
+}
+
+// operator returns the kind and length of the operator or punctuation starting with ch (followed by next),
+// the length is 0 if ch doesn't start one
+func operator(ch byte, next byte) (TokenKind, int) {
+	switch ch {
+	case '[':
+		return OPEN_SQUARE_BRACKET, 1
+	case ']':
+		return CLOSE_SQUARE_BRACKET, 1
+	case '{':
+		return OPEN_CURLY_BRACKET, 1
+	case '}':
+		return CLOSE_CURLY_BRACKET, 1
+	case '(':
+		return OPEN_BRACKET, 1
+	case ')':
+		return CLOSE_BRACKET, 1
+	case ':':
+		return COLON, 1
+	case ';':
+		return SEMI_COLON, 1
+	case ',':
+		return COMMA, 1
+	case '.':
+		return DOT, 1
+	case '=':
+		if next == '=' {
+			return DOUBLE_EQUAL, 2
+		}
+		return EQUAL_ASSIGN, 1
+	case '!':
+		if next == '=' {
+			return NOT_EQUAL, 2
+		}
+		return NOT, 1
+	case '<':
+		if next == '=' {
+			return LESS_THAN_EQUAL, 2
+		}
+		return LESS_THAN, 1
+	case '>':
+		if next == '=' {
+			return GREATER_THAN_EQUAL, 2
+		}
+		return GREATER_THAN, 1
+	case '+':
+		if next == '+' {
+			return PLUS_PLUS, 2
+		} else if next == '=' {
+			return PLUS_EQUAL, 2
+		}
+		return PLUS, 1
+	case '-':
+		if next == '-' {
+			return MINUS_MINUS, 2
+		} else if next == '=' {
+			return MINUS_EQUAL, 2
+		}
+		return DASH, 1
+	case '*':
+		if next == '=' {
+			return STAR_EQUAL, 2
+		}
+		return STAR, 1
+	case '/':
+		if next == '=' {
+			return SLASH_EQUAL, 2
+		}
+		return SLASH, 1
+	case '%':
+		if next == '=' {
+			return PERCENT_EQUAL, 2
+		}
+		return PERCENT, 1
+	case '&':
+		if next == '&' {
+			return AND_AND, 2
 		}
-		lex.advanceN(len(matchedString))
+		return AND, 1
+	case '|':
+		if next == '|' {
+			return OR_OR, 2
+		}
+		return OR, 1
+	}
+	return EOF, 0
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Helper Methods
+// ------------------------------------------------------------------------------------------------------------------
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f'
+}
+
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+}
+
+func isDigit(ch byte) bool { return ch >= '0' && ch <= '9' }
+
+func isAlphaNumeric(ch byte) bool { return isLetter(ch) || isDigit(ch) }
+
+func numError(err error) string {
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return "value out of range"
 	}
+	return "invalid syntax"
 }
 
-func stringHandler(k TokenKind) regexHandler {
-	return func(lex *Lexer, regex *regexp.Regexp) {
-		match := regex.FindString(lex.remainder())
-		lex.push(GetNewToken(k, match))
-		lex.advanceN(len(match))
+// peek returns the byte offset bytes ahead of the current position, or 0 past the end of the source
+func (lexer *Lexer) peek(offset int) byte {
+	if lexer.position+offset >= len(lexer.source) {
+		return 0
 	}
+	return lexer.source[lexer.position+offset]
 }
 
-func identifierHandler(lex *Lexer, regex *regexp.Regexp) {
-	value := regex.FindString(lex.remainder())
-	kind, ok := reservedWords[value]
-	if ok {
-		lex.push(GetNewToken(kind, value))
-	} else {
-		lex.push(GetNewToken(IDENTIFIER, value))
+// countWhile returns how many bytes, starting offset bytes ahead of the current position, satisfy fn
+func (lexer *Lexer) countWhile(offset int, fn func(byte) bool) int {
+	n := 0
+	for fn(lexer.peek(offset + n)) {
+		n++
 	}
-	lex.advanceN(len(value))
+	return n
 }
 
 func (lexer *Lexer) advanceN(n int) {
+	for _, r := range lexer.source[lexer.position : lexer.position+n] {
+		if r == '\n' {
+			lexer.line++
+			lexer.column = 1
+		} else {
+			lexer.column++
+		}
+	}
 	lexer.position += n
 }
 
@@ -169,10 +366,32 @@ func (lexer *Lexer) remainder() string {
 	return lexer.source[lexer.position:]
 }
 
-func (lexer *Lexer) push(token Token) {
+func (lexer *Lexer) push(token Token, n int) {
+	token.Start = Position{Line: lexer.line, Column: lexer.column}
+	lexer.advanceN(n)
+	token.End = Position{Line: lexer.line, Column: lexer.column}
+	token.Trivia, lexer.trivia = lexer.trivia, nil
 	lexer.Tokens = append(lexer.Tokens, token)
 }
 
+func (lexer *Lexer) error(text string, msg string) *LexError {
+	return lexer.errorAt(0, text, msg)
+}
+
+// errorAt reports an error offset bytes ahead of the current position
+func (lexer *Lexer) errorAt(offset int, text string, msg string) *LexError {
+	pos := Position{Line: lexer.line, Column: lexer.column}
+	for _, r := range lexer.source[lexer.position : lexer.position+offset] {
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return &LexError{Text: text, Pos: pos, Msg: msg}
+}
+
 func (lexer *Lexer) atEOF() bool {
 	return lexer.position >= len(lexer.source)
 }
@@ -1,6 +1,9 @@
 package lexer
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 type TokenKind int
 
@@ -57,6 +60,21 @@ const (
 	RETURN
 	CONTINUE
 	BREAK
+
+	// "a ${x} b ${y} c" is split into INTERP_START(`"a ${`), the tokens of x, INTERP_MIDDLE(`} b ${`),
+	// the tokens of y and INTERP_END(`} c"`)
+	INTERP_START
+	INTERP_MIDDLE
+	INTERP_END
+
+	STRUCT
+	DOT
+
+	ENUM
+	MATCH
+
+	IMPORT
+	IN
 )
 
 var reservedWords = map[string]TokenKind{
@@ -78,15 +96,52 @@ var reservedWords = map[string]TokenKind{
 	"bool":     TYPE,
 	"continue": CONTINUE,
 	"break":    BREAK,
+	"struct":   STRUCT,
+	"enum":     ENUM,
+	"match":    MATCH,
+	"import":   IMPORT,
+	"in":       IN,
+}
+
+// LookupIdentifier returns the kind of the keyword value, or IDENTIFIER if it isn't one
+func LookupIdentifier(value string) TokenKind {
+	if kind, ok := reservedWords[value]; ok {
+		return kind
+	}
+	return IDENTIFIER
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Position: line and column (both starting at 1) of a character in the source
+// ------------------------------------------------------------------------------------------------------------------
+type Position struct {
+	Line   int
+	Column int
 }
 
+func (pos Position) IsValid() bool { return pos.Line > 0 }
+func (pos Position) String() string {
+	return strconv.Itoa(pos.Line) + ":" + strconv.Itoa(pos.Column)
+}
+
+// Start is the position of the first character of the token, End is the position right after the last one
 type Token struct {
-	Kind  TokenKind
-	Value string
+	Kind   TokenKind
+	Value  string
+	Start  Position
+	End    Position
+	Trivia []Trivia // the comments between the token before and this one
+}
+
+// Trivia is a comment (`// ...`), it's kept on the token after it so the formatter can write it back
+type Trivia struct {
+	Text  string
+	Start Position
 }
 
 func (token Token) Help() {
-	if token.Kind == STRING || token.Kind == INT || token.Kind == BOOL || token.Kind == CHAR || token.Kind == FLOAT || token.Kind == IDENTIFIER || token.Kind == TYPE {
+	if token.Kind == STRING || token.Kind == INT || token.Kind == BOOL || token.Kind == CHAR || token.Kind == FLOAT || token.Kind == IDENTIFIER || token.Kind == TYPE ||
+		token.Kind == INTERP_START || token.Kind == INTERP_MIDDLE || token.Kind == INTERP_END {
 		fmt.Printf("%s(%s)\n", TokenKindString(token.Kind), token.Value)
 	} else {
 		fmt.Printf("%s()\n", TokenKindString(token.Kind))
@@ -94,7 +149,7 @@ func (token Token) Help() {
 }
 
 func GetNewToken(k TokenKind, v string) Token {
-	return Token{k, v}
+	return Token{Kind: k, Value: v}
 }
 
 func TokenKindString(tKind TokenKind) string {
@@ -203,6 +258,24 @@ func TokenKindString(tKind TokenKind) string {
 		return "CONTINUE"
 	case BREAK:
 		return "BREAK"
+	case INTERP_START:
+		return "INTERP_START"
+	case INTERP_MIDDLE:
+		return "INTERP_MIDDLE"
+	case INTERP_END:
+		return "INTERP_END"
+	case STRUCT:
+		return "STRUCT"
+	case DOT:
+		return "DOT"
+	case ENUM:
+		return "ENUM"
+	case MATCH:
+		return "MATCH"
+	case IMPORT:
+		return "IMPORT"
+	case IN:
+		return "IN"
 	default:
 		return fmt.Sprintf("unknown(%d)", tKind)
 	}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/lexer"
 )
 
 type (
@@ -21,6 +24,9 @@ const (
 	STRING_OBJ  = "STRING"
 	CHAR_OBJ    = "CHAR"
 	MULTI_OBJ   = "MULTI"
+	STRUCT_OBJ  = "STRUCT"
+	ENUM_OBJ    = "ENUM"
+	FUNC_OBJ    = "FUNCTION"
 )
 
 const (
@@ -45,6 +51,18 @@ type HashKey struct {
 	Value uint64
 }
 
+// Display returns obj the way it would be written in kolon source, so strings and chars get their quotes. it's
+// used for the values inside arrays and hashmaps, where `["a, b"]` and `["a", "b"]` must not look the same
+func Display(obj Object) string {
+	switch obj := obj.(type) {
+	case *String:
+		return lexer.Quote(obj.Value, '"')
+	case *Char:
+		return lexer.Quote(obj.Value, '\'')
+	}
+	return obj.Inspect()
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Integer
 // ------------------------------------------------------------------------------------------------------------------
@@ -57,6 +75,10 @@ func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
+func (i *Integer) KeyEquals(other Object) bool {
+	o, ok := other.(*Integer)
+	return ok && o.Value == i.Value
+}
 
 // ------------------------------------------------------------------------------------------------------------------
 // Float
@@ -67,8 +89,24 @@ type Float struct {
 
 func (f *Float) Inspect() string  { return fmt.Sprintf("%f", f.Value) }
 func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// float keys are hashed by their bits, with `0.0` and `-0.0` being the same key (as they are equal), and so are all
+// the NaNs (which aren't equal to anything, not even themselves, but would never be found again otherwise)
 func (f *Float) HashKey() HashKey {
-	return HashKey{Type: f.Type(), Value: uint64(f.Value)}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.key())}
+}
+func (f *Float) KeyEquals(other Object) bool {
+	o, ok := other.(*Float)
+	return ok && math.Float64bits(o.key()) == math.Float64bits(f.key())
+}
+func (f *Float) key() float64 {
+	switch {
+	case math.IsNaN(f.Value):
+		return math.NaN()
+	case f.Value == 0:
+		return 0
+	}
+	return f.Value
 }
 
 // ------------------------------------------------------------------------------------------------------------------
@@ -78,6 +116,19 @@ type Bool struct {
 	Value bool
 }
 
+// TRUE and FALSE are the only two bools, the interpreters tell them apart by pointer
+var (
+	TRUE  = &Bool{Value: true}
+	FALSE = &Bool{Value: false}
+)
+
+func NativeBool(b bool) *Bool {
+	if b {
+		return TRUE
+	}
+	return FALSE
+}
+
 func (b *Bool) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
 func (b *Bool) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Bool) HashKey() HashKey {
@@ -89,6 +140,10 @@ func (b *Bool) HashKey() HashKey {
 	}
 	return HashKey{Type: b.Type(), Value: value}
 }
+func (b *Bool) KeyEquals(other Object) bool {
+	o, ok := other.(*Bool)
+	return ok && o.Value == b.Value
+}
 
 // ------------------------------------------------------------------------------------------------------------------
 // String
@@ -104,6 +159,10 @@ func (s *String) HashKey() HashKey {
 	h.Write([]byte(s.Value))
 	return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
+func (s *String) KeyEquals(other Object) bool {
+	o, ok := other.(*String)
+	return ok && o.Value == s.Value
+}
 
 // ------------------------------------------------------------------------------------------------------------------
 // Char
@@ -119,6 +178,10 @@ func (c *Char) HashKey() HashKey {
 	h.Write([]byte(c.Value))
 	return HashKey{Type: c.Type(), Value: h.Sum64()}
 }
+func (c *Char) KeyEquals(other Object) bool {
+	o, ok := other.(*Char)
+	return ok && o.Value == c.Value
+}
 
 // ------------------------------------------------------------------------------------------------------------------
 // Array
@@ -131,7 +194,7 @@ func (a *Array) Inspect() string {
 	var out bytes.Buffer
 	elements := []string{}
 	for _, e := range a.Elements {
-		elements = append(elements, e.Inspect())
+		elements = append(elements, Display(e))
 	}
 	out.WriteString("[")
 	out.WriteString(strings.Join(elements, ", "))
@@ -147,19 +210,123 @@ type HashPair struct {
 	Key   Object
 	Value Object
 }
+
+// Hashable is a value that can be a key of a hashmap, keys with the same HashKey (a collision) are told apart
+// with KeyEquals
 type Hashable interface {
+	Object
 	HashKey() HashKey
+	KeyEquals(other Object) bool
 }
+
+// HashMap: keeps its pairs in the order their keys were first added. index has the positions in entries of the
+// keys with each HashKey, there's more than one only when different keys collide. deleting a pair leaves a hole
+// (nil) in entries, they are compacted once there are more holes than pairs
 type HashMap struct {
-	Pairs map[HashKey]HashPair
+	index   map[HashKey][]int
+	entries []*HashPair
+	size    int
+}
+
+func NewHashMap() *HashMap {
+	return &HashMap{index: make(map[HashKey][]int)}
+}
+
+func (h *HashMap) Len() int { return h.size }
+
+// find returns the position of key in entries, or -1
+func (h *HashMap) find(key Hashable) int {
+	for _, i := range h.index[key.HashKey()] {
+		if key.KeyEquals(h.entries[i].Key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h *HashMap) Get(key Hashable) (HashPair, bool) {
+	i := h.find(key)
+	if i == -1 {
+		return HashPair{}, false
+	}
+	return *h.entries[i], true
+}
+
+// Set adds value under key, or replaces the value already there (the key keeps its position)
+func (h *HashMap) Set(key Hashable, value Object) {
+	if i := h.find(key); i != -1 {
+		h.entries[i] = &HashPair{Key: h.entries[i].Key, Value: value}
+		return
+	}
+	if h.index == nil {
+		h.index = make(map[HashKey][]int)
+	}
+	hash := key.HashKey()
+	h.index[hash] = append(h.index[hash], len(h.entries))
+	h.entries = append(h.entries, &HashPair{Key: key, Value: value})
+	h.size++
+}
+
+func (h *HashMap) Delete(key Hashable) (HashPair, bool) {
+	i := h.find(key)
+	if i == -1 {
+		return HashPair{}, false
+	}
+	pair := *h.entries[i]
+	h.entries[i] = nil
+	h.size--
+
+	hash := key.HashKey()
+	chain := h.index[hash]
+	for j, pos := range chain {
+		if pos == i {
+			chain = append(chain[:j:j], chain[j+1:]...)
+			break
+		}
+	}
+	if len(chain) == 0 {
+		delete(h.index, hash)
+	} else {
+		h.index[hash] = chain
+	}
+
+	if holes := len(h.entries) - h.size; holes > h.size {
+		h.compact()
+	}
+	return pair, true
+}
+
+func (h *HashMap) compact() {
+	entries := make([]*HashPair, 0, h.size)
+	h.index = make(map[HashKey][]int, h.size)
+	for _, pair := range h.entries {
+		if pair == nil {
+			continue
+		}
+		hash := pair.Key.(Hashable).HashKey()
+		h.index[hash] = append(h.index[hash], len(entries))
+		entries = append(entries, pair)
+	}
+	h.entries = entries
+}
+
+// Pairs returns the pairs in order
+func (h *HashMap) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, h.size)
+	for _, pair := range h.entries {
+		if pair != nil {
+			pairs = append(pairs, *pair)
+		}
+	}
+	return pairs
 }
 
 func (h *HashMap) Inspect() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	for _, pair := range h.Pairs() {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", Display(pair.Key), Display(pair.Value)))
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -168,3 +335,49 @@ func (h *HashMap) Inspect() string {
 	return out.String()
 }
 func (h *HashMap) Type() ObjectType { return HASHMAP_OBJ }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Struct: Fields holds the field names in declaration order, shared by every value of the same struct
+// ------------------------------------------------------------------------------------------------------------------
+type Struct struct {
+	Name   string
+	Fields []string
+	Values []Object
+}
+
+func (s *Struct) Inspect() string {
+	var out bytes.Buffer
+	fields := []string{}
+	for i, v := range s.Values {
+		fields = append(fields, s.Fields[i]+": "+Display(v))
+	}
+	out.WriteString(s.Name + "{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+func (s *Struct) Type() ObjectType { return STRUCT_OBJ }
+
+// ------------------------------------------------------------------------------------------------------------------
+// EnumValue: Index is the position of the variant in the enum declaration, Values are the values it carries
+// ------------------------------------------------------------------------------------------------------------------
+type EnumValue struct {
+	Enum    string
+	Variant string
+	Index   int
+	Values  []Object
+}
+
+func (ev *EnumValue) Inspect() string {
+	var out bytes.Buffer
+	out.WriteString(ev.Enum + "." + ev.Variant)
+	if ev.Values != nil {
+		values := []string{}
+		for _, v := range ev.Values {
+			values = append(values, Display(v))
+		}
+		out.WriteString("(" + strings.Join(values, ", ") + ")")
+	}
+	return out.String()
+}
+func (ev *EnumValue) Type() ObjectType { return ENUM_OBJ }
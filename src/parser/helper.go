@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
 )
@@ -27,12 +28,12 @@ var (
 	}
 	defaultString = &ast.String{
 		Token: lexer.Token{Kind: lexer.STRING, Value: "\"\""},
-		Value: "\"\"",
+		Value: "",
 		Type:  ktype.NewBaseType("string"),
 	}
 	defaultChar = &ast.Char{
 		Token: lexer.Token{Kind: lexer.CHAR, Value: "''"},
-		Value: "''",
+		Value: "",
 		Type:  ktype.NewBaseType("char"),
 	}
 )
@@ -67,6 +68,18 @@ func (p *Parser) peekTokenIsOk(kind lexer.TokenKind) bool {
 	return p.peekToken.Kind == kind
 }
 
+// currIdentifier returns currToken as an identifier, the resolver gives it its slot once the program is checked
+func (p *Parser) currIdentifier() *ast.Identifier {
+	return &ast.Identifier{Token: p.currToken, Value: p.currToken.Value, Index: ast.Unresolved}
+}
+
+// atDeclaration reports whether currToken starts a top level declaration (`fun:`, `struct:`, `enum:`) or is EOF,
+// where recovering from an error has to stop
+func (p *Parser) atDeclaration() bool {
+	return p.currTokenIsOk(lexer.FUN) || p.currTokenIsOk(lexer.STRUCT) || p.currTokenIsOk(lexer.ENUM) ||
+		p.currTokenIsOk(lexer.EOF)
+}
+
 func (p *Parser) addPrefix(tokenKind lexer.TokenKind, fn prefixParseFn) {
 	p.prefixParseFns[tokenKind] = fn
 }
@@ -79,6 +92,10 @@ func (p *Parser) addPostfix(tokenKind lexer.TokenKind, fn postfixParseFn) {
 	p.postfixParseFns[tokenKind] = fn
 }
 
+func (p *Parser) outsideFunction() bool {
+	return !p.inFunction && !p.inTesting && !p.interactive
+}
+
 func (p *Parser) peekPrecedence() int {
 	if p, ok := precedences[p.peekToken.Kind]; ok {
 		return p
@@ -95,6 +112,7 @@ func (p *Parser) currentPrecedence() int {
 
 func (p *Parser) compareFunctionSig(f1, f2 *ast.Function) bool {
 	if !f1.Name.Equals(f2.Name) ||
+		len(f1.TypeParams) != len(f2.TypeParams) ||
 		f1.Parameters == nil && f2.Parameters != nil ||
 		f1.Parameters != nil && f2.Parameters == nil ||
 		len(f1.Parameters) != len(f2.Parameters) ||
@@ -103,6 +121,11 @@ func (p *Parser) compareFunctionSig(f1, f2 *ast.Function) bool {
 		len(f1.ReturnTypes) != len(f2.ReturnTypes) {
 		return false
 	}
+	for i := range f1.TypeParams {
+		if !f1.TypeParams[i].Equals(f2.TypeParams[i]) {
+			return false
+		}
+	}
 	for i := range f1.Parameters {
 		if !f1.Parameters[i].Equals(f2.Parameters[i]) {
 			return false
@@ -158,13 +181,16 @@ func typeCheckBoolCon(exp ast.Expression, keyword string) error {
 }
 
 func checkReturnAtTheEnd(stmt []ast.Statement) error {
+	if len(stmt) == 0 {
+		return errors.New(" must have a `return` statement at the end of all branches")
+	}
 	lastStmt := stmt[len(stmt)-1]
 	switch n := lastStmt.(type) {
 	case *ast.Return:
 		return nil
 	case *ast.If:
 		if n.Alternate == nil {
-			return errors.New("` must have a `return` statement at the end of all branches")
+			return errors.New(" must have a `return` statement at the end of all branches")
 		}
 		err := checkReturnAtTheEnd(n.Body.Statements)
 		if err != nil {
@@ -183,11 +209,31 @@ func checkReturnAtTheEnd(stmt []ast.Statement) error {
 			return err
 		}
 		return nil
+	case *ast.Match:
+		// every variant is handled by some arm, so returning from all of them returns from the function
+		for _, arm := range n.Arms {
+			err := checkReturnAtTheEnd(arm.Body.Statements)
+			if err != nil {
+				return err
+			}
+		}
+		if n.Alternate != nil {
+			return checkReturnAtTheEnd(n.Alternate.Body.Statements)
+		}
+		return nil
 	default:
-		return errors.New("` must have a `return` statement at the end of all branches")
+		return errors.New(" must have a `return` statement at the end of all branches")
+	}
+}
+
+// funcName names f in error messages, function literals don't have a name of their own
+func funcName(f *ast.Function) string {
+	if f.Name == nil {
+		return "function literal"
 	}
+	return "function `" + f.Name.Value + "`"
 }
 
 func (p *Parser) handleEOF() (ast.Expression, error) {
-	return nil, errors.New("unexpected end of file")
+	return nil, diagnostic.New(p.currToken.Start, "unexpected end of file")
 }
@@ -0,0 +1,310 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/builtins"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/environment"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Loader: Finds, parses and keeps the modules imported by a program, it's shared by the parsers of all its files
+// so a module imported by more than one of them is only parsed once
+// ------------------------------------------------------------------------------------------------------------------
+type Loader struct {
+	searchPath []string
+	modules    map[string]*loadedModule // by absolute path
+	names      map[string]string        // module name -> absolute path of the module using it
+	importing  []importingFile          // the file being parsed and the chain of files that imported it
+	builtins   *builtins.Registry       // the builtins every module can call
+}
+
+type loadedModule struct {
+	module *ast.Module
+	env    *environment.Environment
+}
+
+type importingFile struct {
+	abs  string
+	path string
+}
+
+// SearchPathEnv is the environment variable with the default search path, a list of directories separated like
+// the PATH of the os (`:` or `;` on windows)
+const SearchPathEnv = "KOLON_PATH"
+
+func newLoader() *Loader {
+	return &Loader{
+		searchPath: filepath.SplitList(os.Getenv(SearchPathEnv)),
+		modules:    make(map[string]*loadedModule),
+		names:      make(map[string]string),
+		builtins:   builtins.New(),
+	}
+}
+
+// SetFile records the path of the file being parsed, its imports are looked up relative to it
+func (p *Parser) SetFile(path string) {
+	p.file = path
+	if abs, err := filepath.Abs(path); err == nil {
+		p.loader.importing = append(p.loader.importing, importingFile{abs: abs, path: path})
+	}
+}
+
+// SetSearchPath sets the directories an import is looked up in when it isn't found next to the importing file
+func (p *Parser) SetSearchPath(dirs []string) {
+	p.loader.searchPath = dirs
+}
+
+// SetBuiltins makes the builtins of r callable by the program and the modules it imports, instead of the
+// standard ones
+func (p *Parser) SetBuiltins(r *builtins.Registry) {
+	p.loader.builtins = r
+	environment.LoadBuiltins(p.env, r)
+}
+
+// resolve returns the path of the file imported as path by the file from
+func (l *Loader) resolve(from string, path string) (string, error) {
+	if !strings.HasSuffix(path, ".kol") {
+		return "", errors.New("imported file `" + path + "` must have .kol extension")
+	}
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		candidates = []string{filepath.Join(filepath.Dir(from), path)}
+		for _, dir := range l.searchPath {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+	dirs := []string{}
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return c, nil
+		}
+		dirs = append(dirs, "`"+filepath.Dir(c)+"`")
+	}
+	return "", errors.New("module `" + path + "` not found, looked in: " + joinOr(dirs))
+}
+
+// load parses the module at path (imported by the file from), the errors in it are only returned the first time
+func (l *Loader) load(from string, path string, inTesting bool) (*loadedModule, error) {
+	file, err := l.resolve(from, path)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range l.importing {
+		if f.abs == abs {
+			chain := []string{}
+			for _, f := range l.importing[i:] {
+				chain = append(chain, f.path)
+			}
+			return nil, errors.New("import cycle: " + strings.Join(append(chain, file), " -> "))
+		}
+	}
+	if m, ok := l.modules[abs]; ok {
+		return m, nil
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file), ".kol")
+	if !validModuleName(name) {
+		return nil,
+			errors.New(
+				"module name `" + name + "` must be a valid identifier, since its names are used as `" +
+					name + ".name`. rename the file `" + file + "`",
+			)
+	}
+	if other, ok := l.names[name]; ok {
+		return nil,
+			errors.New(
+				"can't import `" + file + "`, module `" + name + "` is already `" + other +
+					"`. modules used by a program must have different file names",
+			)
+	}
+
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.New("error reading module `" + file + "`: " + err.Error())
+	}
+	m := &loadedModule{module: &ast.Module{Name: name, Path: file}, env: environment.NewEnvironment()}
+	l.names[name] = abs
+	tokens, err := lexer.Tokenizer(string(bytes))
+	if err != nil {
+		l.modules[abs] = m
+		return m, diagnostic.List{diagnostic.WithFile(err, file)}
+	}
+
+	mp := New(tokens, inTesting)
+	mp.file = file
+	mp.module = name
+	mp.loader = l
+	environment.LoadBuiltins(mp.env, l.builtins)
+	l.importing = append(l.importing, importingFile{abs: abs, path: file})
+	program, err := mp.ParseProgram()
+	l.importing = l.importing[:len(l.importing)-1]
+
+	m.module.Program = program
+	m.env = mp.env
+	l.modules[abs] = m
+	if err != nil {
+		return m, diagnostic.List(diagnostic.Errors(diagnostic.WithFile(err, file)))
+	}
+	return m, nil
+}
+
+func validModuleName(name string) bool {
+	if name == "" || lexer.LookupIdentifier(name) != lexer.IDENTIFIER {
+		return false
+	}
+	for i, ch := range name {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' || i != 0 && ch >= '0' && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Import
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseImport() (*ast.Import, error) {
+	if p.inFunction {
+		return nil, diagnostic.New(p.currToken.Start, "can't import a module inside a function")
+	}
+	if p.pastImports && !p.interactive {
+		return nil,
+			diagnostic.New(p.currToken.Start, "`import` statements must come before everything else in the file")
+	}
+	stmt := &ast.Import{Token: p.currToken}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `import` keyword, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.STRING) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected the path of a `.kol` file after the colon (`:`), got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	path, err := p.parseString()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Path = path.(*ast.String)
+	if !p.expectedPeekToken(lexer.SEMI_COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) at the end of the statement, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	m, err := p.loader.load(p.file, stmt.Path.Value, p.inTesting)
+	if m == nil {
+		return nil, diagnostic.Wrap(stmt.Path.Pos(), err)
+	}
+	name := m.module.Name
+	if existing, ok := p.imports[name]; ok && existing == m {
+		return nil, diagnostic.New(stmt.Path.Pos(), "module `"+name+"` is imported twice")
+	}
+	if sym, ok := p.env.GetType(name); ok {
+		existing := "a struct"
+		if sym.IdentType == environment.ENUM {
+			existing = "an enum"
+		}
+		return nil,
+			diagnostic.New(stmt.Path.Pos(),
+				"can't import module `"+name+"`, "+existing+" with the same name already exists",
+			)
+	}
+
+	// the errors of the module are reported as they are, but its names are still declared (as far as they were
+	// parsed) so using them doesn't add more errors
+	p.useModule(m)
+	if err != nil {
+		p.errors = append(p.errors, diagnostic.Errors(err)...)
+		return nil, nil
+	}
+	stmt.Module = m.module
+	return stmt, nil
+}
+
+// useModule declares the exported functions of m in the environment as `module.name`. all the types are declared
+// (by their full name), a value of a private type can still come out of an exported function
+func (p *Parser) useModule(m *loadedModule) {
+	name := m.module.Name
+	p.imports[name] = m
+	for key, sym := range m.env.FuncNameSpace {
+		if sym.Func.Builtin || !ast.Exported(key) {
+			continue
+		}
+		p.env.FuncNameSpace[name+"."+key] = sym
+	}
+	for _, sym := range m.env.TypeNameSpace {
+		p.env.TypeNameSpace[sym.Type.Name] = sym
+	}
+}
+
+// isModule reports if name is an imported module, and not a variable hiding it
+func (p *Parser) isModule(name string) bool {
+	if _, ok := p.imports[name]; !ok {
+		return false
+	}
+	_, isVar := p.stack.Top().GetVar(name)
+	return !isVar
+}
+
+// parseModuleMember reads the `.name` after the name of an imported module (the current token), the member is
+// returned as a single identifier, `module.name`, which is what the names of the module are declared as
+func (p *Parser) parseModuleMember() (*ast.Identifier, error) {
+	mod := p.currToken
+	p.nextToken()
+	if !p.expectedPeekToken(lexer.IDENTIFIER) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a name after `"+mod.Value+".`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	member := p.currToken
+	if !ast.Exported(member.Value) {
+		return nil,
+			diagnostic.New(member.Start,
+				"`"+member.Value+"` is private to module `"+mod.Value+
+					"`, names starting with an underscore (`_`) can't be used outside of their module",
+			)
+	}
+	name := mod.Value + "." + member.Value
+	_, isFunc := p.env.GetFunc(name)
+	_, isType := p.env.GetType(name)
+	if !isFunc && !isType {
+		return nil,
+			diagnostic.New(member.Start, "module `"+mod.Value+"` has no `"+member.Value+"`")
+	}
+	return &ast.Identifier{
+		Token: lexer.Token{Kind: lexer.IDENTIFIER, Value: name, Start: mod.Start, End: member.End},
+		Value: name,
+		Index: ast.Unresolved,
+	}, nil
+}
+
+// qualified returns the name a struct or an enum called name declared in this file has as a type, the types of a
+// module are prefixed with its name so they can't be mixed up with the types of other files
+func (p *Parser) qualified(name string) string {
+	if p.module == "" {
+		return name
+	}
+	return p.module + "." + name
+}
@@ -1,11 +1,13 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/environment"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
 )
 
@@ -69,12 +71,13 @@ var precedences = map[lexer.TokenKind]int{
 	lexer.OPEN_BRACKET: CALL,
 
 	lexer.OPEN_SQUARE_BRACKET: INDEX,
+	lexer.DOT:                 INDEX,
 }
 
 func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 	prefix := p.prefixParseFns[p.currToken.Kind]
 	if prefix == nil {
-		return nil, errors.New("no prefix parse function for: " +
+		return nil, diagnostic.New(p.currToken.Start, "no prefix parse function for: "+
 			lexer.TokenKindString(p.currToken.Kind))
 	}
 	leftExp, err := prefix()
@@ -86,7 +89,7 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 		if p.postfixParseFns[p.peekToken.Kind] != nil {
 			postfix := p.postfixParseFns[p.peekToken.Kind]
 			if postfix == nil {
-				return nil, errors.New("no postfix parse function for: " +
+				return nil, diagnostic.New(p.peekToken.Start, "no postfix parse function for: "+
 					lexer.TokenKindString(p.peekToken.Kind))
 			}
 			p.nextToken()
@@ -115,13 +118,33 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 // Identifier
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseIdentifier() (ast.Expression, error) {
-	exp := &ast.Identifier{Token: p.currToken, Value: p.currToken.Value}
+	exp := p.currIdentifier()
+	if p.isModule(exp.Value) {
+		if !p.peekTokenIsOk(lexer.DOT) {
+			return nil,
+				diagnostic.New(exp.Pos(),
+					"module `"+exp.Value+"` can't be used as a value, use one of its names as `"+
+						exp.Value+".name`",
+				)
+		}
+		member, err := p.parseModuleMember()
+		if err != nil {
+			return nil, err
+		}
+		exp = member
+	}
 	if p.peekTokenIsOk(lexer.OPEN_BRACKET) {
 		return exp, nil
 	}
+	if sym, ok := p.env.GetStruct(exp.Value); ok && p.peekTokenIsOk(lexer.OPEN_CURLY_BRACKET) {
+		return p.parseStructLiteral(exp, sym.Struct)
+	}
+	if sym, ok := p.env.GetEnum(exp.Value); ok && p.peekTokenIsOk(lexer.DOT) {
+		return p.parseEnumValue(exp, sym.Enum)
+	}
 	t, err := typeCheckIdent(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0]
 	return exp, nil
@@ -134,7 +157,7 @@ func (p *Parser) parseInteger() (ast.Expression, error) {
 	exp := &ast.Integer{Token: p.currToken}
 	val, err := strconv.ParseInt(p.currToken.Value, 0, 64)
 	if err != nil {
-		return nil, errors.New("could not parse " + p.currToken.Value + " as integer")
+		return nil, diagnostic.New(p.currToken.Start, "could not parse "+p.currToken.Value+" as integer")
 	}
 	exp.Value = val
 	t, err := typeCheckInteger()
@@ -152,7 +175,7 @@ func (p *Parser) parseFloat() (ast.Expression, error) {
 	exp := &ast.Float{Token: p.currToken}
 	val, err := strconv.ParseFloat(p.currToken.Value, 64)
 	if err != nil {
-		return nil, errors.New("could not parse " + p.currToken.Value + " as float")
+		return nil, diagnostic.New(p.currToken.Start, "could not parse "+p.currToken.Value+" as float")
 	}
 	exp.Value = val
 	t, err := typeCheckFloat()
@@ -185,7 +208,11 @@ func (p *Parser) parseBoolean() (ast.Expression, error) {
 // String
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseString() (ast.Expression, error) {
-	exp := &ast.String{Token: p.currToken, Value: p.currToken.Value}
+	value, err := lexer.Unquote(p.currToken.Value)
+	if err != nil {
+		return nil, diagnostic.New(p.currToken.Start, err.Error())
+	}
+	exp := &ast.String{Token: p.currToken, Value: value}
 	t, err := typeCheckString()
 	if err != nil {
 		return nil, err
@@ -194,11 +221,61 @@ func (p *Parser) parseString() (ast.Expression, error) {
 	return exp, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Interpolated String
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseInterpolatedString() (ast.Expression, error) {
+	exp := &ast.InterpolatedString{Token: p.currToken}
+	for {
+		part, err := lexer.Unquote(p.currToken.Value)
+		if err != nil {
+			return nil, diagnostic.New(p.currToken.Start, err.Error())
+		}
+		exp.Parts = append(exp.Parts, part)
+		if p.currTokenIsOk(lexer.INTERP_END) {
+			break
+		}
+
+		if p.peekTokenIsOk(lexer.INTERP_MIDDLE) || p.peekTokenIsOk(lexer.INTERP_END) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected an expression inside `${}` in the string interpolation",
+				)
+		}
+		p.nextToken()
+		value, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
+		}
+		exp.Expressions = append(exp.Expressions, value)
+
+		if !p.peekTokenIsOk(lexer.INTERP_MIDDLE) && !p.peekTokenIsOk(lexer.INTERP_END) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a closing curly bracket (`}`) after the expression in the string interpolation, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		p.nextToken()
+	}
+
+	t, err := typeCheckInterpolatedString(exp)
+	if err != nil {
+		return nil, diagnostic.Wrap(exp.Pos(), err)
+	}
+	exp.Type = t.Types[0]
+	return exp, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Char
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseChar() (ast.Expression, error) {
-	exp := &ast.Char{Token: p.currToken, Value: p.currToken.Value}
+	value, err := lexer.Unquote(p.currToken.Value)
+	if err != nil {
+		return nil, diagnostic.New(p.currToken.Start, err.Error())
+	}
+	exp := &ast.Char{Token: p.currToken, Value: value}
 	t, err := typeCheckChar()
 	if err != nil {
 		return nil, err
@@ -213,8 +290,8 @@ func (p *Parser) parseChar() (ast.Expression, error) {
 func (p *Parser) parseHashMap() (ast.Expression, error) {
 	if !p.currTokenIsOk(lexer.OPEN_CURLY_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open curly bracket (`{`) for a hashmap, got: " +
+			diagnostic.New(p.currToken.Start,
+				"expected an open curly bracket (`{`) for a hashmap, got: "+
 					lexer.TokenKindString(p.currToken.Kind),
 			)
 	}
@@ -222,7 +299,7 @@ func (p *Parser) parseHashMap() (ast.Expression, error) {
 		Token:     p.currToken,
 		KeyType:   nil,
 		ValueType: nil,
-		Pairs:     map[ast.BaseType]ast.Expression{},
+		Pairs:     []*ast.HashMapPair{},
 	}
 	p.nextToken()
 	if p.currTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
@@ -237,15 +314,15 @@ func (p *Parser) parseHashMap() (ast.Expression, error) {
 		k, ok := kExp.(ast.BaseType)
 		if !ok {
 			return nil,
-				errors.New(
-					"key in a hashmap can only be of `BaseType`, got: " +
+				diagnostic.New(p.currToken.Start,
+					"key in a hashmap can only be of `BaseType`, got: "+
 						fmt.Sprintf("%T", kExp),
 				)
 		}
 		if !p.expectedPeekToken(lexer.COLON) {
 			return nil,
-				errors.New(
-					"expected a colon (`:`) after the key, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a colon (`:`) after the key, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
@@ -256,14 +333,14 @@ func (p *Parser) parseHashMap() (ast.Expression, error) {
 			return nil, err
 		}
 
-		exp.Pairs[k] = vExp
+		exp.Pairs = append(exp.Pairs, &ast.HashMapPair{Key: k, Value: vExp})
 
 		if p.peekTokenIsOk(lexer.COMMA) {
 			p.nextToken()
 			if p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
 				return nil,
-					errors.New(
-						"expected a value after comma, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected a value after comma, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -273,15 +350,15 @@ func (p *Parser) parseHashMap() (ast.Expression, error) {
 			break
 		} else {
 			return nil,
-				errors.New(
-					"expected a comma (`,`) or a closing curly bracket (`}`) after the value, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a comma (`,`) or a closing curly bracket (`}`) after the value, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 	}
 	t, err := typeCheckHashMap(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.KeyType = t.Types[0].KeyType
 	exp.ValueType = t.Types[0].ValueType
@@ -294,8 +371,8 @@ func (p *Parser) parseHashMap() (ast.Expression, error) {
 func (p *Parser) parseArray() (ast.Expression, error) {
 	if !p.currTokenIsOk(lexer.OPEN_SQUARE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open square bracket (`[`) for an array, got: " +
+			diagnostic.New(p.currToken.Start,
+				"expected an open square bracket (`[`) for an array, got: "+
 					lexer.TokenKindString(p.currToken.Kind),
 			)
 	}
@@ -323,8 +400,8 @@ func (p *Parser) parseArray() (ast.Expression, error) {
 			p.nextToken()
 			if p.peekTokenIsOk(lexer.CLOSE_SQUARE_BRACKET) {
 				return nil,
-					errors.New(
-						"expected a value after comma in an array, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected a value after comma in an array, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -334,20 +411,162 @@ func (p *Parser) parseArray() (ast.Expression, error) {
 			break
 		} else {
 			return nil,
-				errors.New(
-					"expected a comma (`,`) or a closing square bracket (`]`) after the value, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a comma (`,`) or a closing square bracket (`]`) after the value, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 	}
 	t, err := typeCheckArray(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0].ElementType
 	return exp, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Struct Literal
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseStructLiteral(name *ast.Identifier, decl *ast.Struct) (ast.Expression, error) {
+	exp := &ast.StructLiteral{
+		Token:  p.currToken,
+		Name:   name,
+		Fields: make([]string, len(decl.Fields)),
+		Values: make([]ast.Expression, len(decl.Fields)),
+	}
+	for i, field := range decl.Fields {
+		exp.Fields[i] = field.FieldName.Value
+	}
+	p.nextToken()
+
+	for !p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+		if !p.expectedPeekToken(lexer.IDENTIFIER) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a field name or a closing curly bracket (`}`) in `"+
+						name.Value+"` literal, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		field := p.currToken
+		idx := decl.FieldIndex(field.Value)
+		if idx == -1 {
+			return nil,
+				diagnostic.New(field.Start,
+					"struct `"+name.Value+"` has no field `"+field.Value+"`",
+				)
+		}
+		if exp.Values[idx] != nil {
+			return nil,
+				diagnostic.New(field.Start,
+					"field `"+field.Value+"` is given twice in `"+name.Value+"` literal",
+				)
+		}
+		if !p.expectedPeekToken(lexer.COLON) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a colon (`:`) after the field `"+field.Value+"`, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		p.nextToken()
+		value, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
+		}
+		exp.Values[idx] = value
+
+		if p.peekTokenIsOk(lexer.COMMA) {
+			p.nextToken()
+			if p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+				return nil,
+					diagnostic.New(p.peekToken.Start,
+						"expected a field name after comma, got: "+
+							lexer.TokenKindString(p.peekToken.Kind),
+					)
+			}
+		} else if !p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a comma (`,`) or a closing curly bracket (`}`) after the value, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+	}
+	p.nextToken()
+
+	for i, field := range decl.Fields {
+		if exp.Values[i] == nil {
+			exp.Values[i] = p.assignDefaultValue(field.FieldType)
+		}
+	}
+	t, err := typeCheckStructLiteral(exp, p.stack.Top())
+	if err != nil {
+		return nil, diagnostic.Wrap(exp.Pos(), err)
+	}
+	exp.Type = t.Types[0]
+	return exp, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum Value
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseEnumValue(name *ast.Identifier, decl *ast.Enum) (ast.Expression, error) {
+	exp := &ast.EnumValue{Token: p.currToken, Enum: name}
+	p.nextToken()
+	if !p.expectedPeekToken(lexer.IDENTIFIER) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a variant name after the dot (`.`), got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	exp.Variant = p.currIdentifier()
+	exp.Index = decl.VariantIndex(exp.Variant.Value)
+	if exp.Index == -1 {
+		return nil,
+			diagnostic.New(exp.Variant.Pos(),
+				"enum `"+name.Value+"` has no variant `"+exp.Variant.Value+"`",
+			)
+	}
+	if p.peekTokenIsOk(lexer.OPEN_BRACKET) {
+		p.nextToken()
+		args, err := p.parseCallArgs(exp.Variant.String())
+		if err != nil {
+			return nil, err
+		}
+		exp.Args = args
+	}
+	t, err := typeCheckEnumValue(exp, p.stack.Top())
+	if err != nil {
+		return nil, diagnostic.Wrap(exp.Pos(), err)
+	}
+	exp.Type = t.Types[0]
+	return exp, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Field Access
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseFieldAccess(left ast.Expression) (ast.Expression, error) {
+	exp := &ast.FieldAccess{Token: p.currToken, Left: left}
+	if !p.expectedPeekToken(lexer.IDENTIFIER) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a field name after the dot (`.`), got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	exp.Field = p.currIdentifier()
+	t, err := typeCheckFieldAccess(exp, p.stack.Top())
+	if err != nil {
+		return nil, diagnostic.Wrap(exp.Field.Pos(), err)
+	}
+	exp.Type = t.Types[0]
+	return exp, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Prefix
 // ------------------------------------------------------------------------------------------------------------------
@@ -361,7 +580,7 @@ func (p *Parser) parsePrefix() (ast.Expression, error) {
 	exp.Right = right
 	t, err := typeCheckPrefix(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0]
 	return exp, nil
@@ -381,7 +600,7 @@ func (p *Parser) parseInfix(left ast.Expression) (ast.Expression, error) {
 	exp.Right = right
 	t, err := typeCheckInfix(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0]
 	return exp, nil
@@ -394,7 +613,7 @@ func (p *Parser) parsePostfix(left ast.Expression) (ast.Expression, error) {
 	exp := &ast.Postfix{Token: p.currToken, Operator: p.currToken.Value, Left: left}
 	t, err := typeCheckPostfix(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0]
 	return exp, nil
@@ -404,14 +623,15 @@ func (p *Parser) parsePostfix(left ast.Expression) (ast.Expression, error) {
 // Assignment
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseAssignment(left ast.Expression) (ast.Expression, error) {
-	ident, ok := left.(*ast.Identifier)
-	if !ok {
-		return nil, errors.New(
-			"left side in an assignment operation must be an identifier, got: " +
+	switch left.(type) {
+	case *ast.Identifier, *ast.FieldAccess, *ast.IndexExpression:
+	default:
+		return nil, diagnostic.New(p.currToken.Start,
+			"left side in an assignment operation must be an identifier, a struct field or an index expression, got: "+
 				fmt.Sprintf("%T", left),
 		)
 	}
-	exp := &ast.Assignment{Token: p.currToken, Left: ident, Operator: p.currToken.Value}
+	exp := &ast.Assignment{Token: p.currToken, Left: left, Operator: p.currToken.Value}
 	p.nextToken()
 	right, err := p.parseExpression(LOWEST)
 	if err != nil {
@@ -420,7 +640,7 @@ func (p *Parser) parseAssignment(left ast.Expression) (ast.Expression, error) {
 	exp.Right = right
 	t, err := typeCheckAssignment(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0]
 	return exp, nil
@@ -430,28 +650,36 @@ func (p *Parser) parseAssignment(left ast.Expression) (ast.Expression, error) {
 // CallExpression
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseCall(left ast.Expression) (ast.Expression, error) {
+	exp := &ast.CallExpression{Token: p.currToken, Args: nil}
 	ident, ok := left.(*ast.Identifier)
-	if !ok {
-		return nil, errors.New(
-			"function name in a call expression must be an identifier, got: " +
-				fmt.Sprintf("%T", left),
-		)
+	if ok {
+		// variables and functions live in different namespaces, a variable is only called when it holds a
+		// function, or when there is no function with that name (to report that it can't be called)
+		sym, isVar := p.stack.Top().GetVar(ident.Value)
+		_, isFunc := p.env.GetFunc(ident.Value)
+		if isVar && (sym.Type.Kind == ktype.TypeFunction || !isFunc) {
+			ident.Type = sym.Type
+			exp.Callee = ident
+		} else {
+			exp.Name = ident
+		}
+	} else {
+		exp.Callee = left
 	}
-	exp := &ast.CallExpression{Token: p.currToken, Name: ident, Args: nil}
-	args, err := p.parseCallArgs(ident)
+	args, err := p.parseCallArgs(left.String())
 	if err != nil {
 		return nil, err
 	}
 	exp.Args = args
 	t, err := typeCheckCallExp(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types
 	return exp, nil
 }
 
-func (p *Parser) parseCallArgs(left *ast.Identifier) ([]ast.Expression, error) {
+func (p *Parser) parseCallArgs(name string) ([]ast.Expression, error) {
 	if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
 		p.nextToken()
 		return nil, nil
@@ -477,9 +705,9 @@ func (p *Parser) parseCallArgs(left *ast.Identifier) ([]ast.Expression, error) {
 
 	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing bracket (`)`) after the arguments in call expression " +
-					left.Value + ", got: " + lexer.TokenKindString(p.peekToken.Kind),
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after the arguments in call expression "+
+					name+", got: "+lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	return args, nil
@@ -498,14 +726,14 @@ func (p *Parser) parseIndex(left ast.Expression) (ast.Expression, error) {
 	exp.Index = idx
 	if !p.expectedPeekToken(lexer.CLOSE_SQUARE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing square bracket (`]`) after the index in index expression. got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing square bracket (`]`) after the index in index expression. got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	t, err := typeCheckIndexExp(exp, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(exp.Pos(), err)
 	}
 	exp.Type = t.Types[0]
 	return exp, nil
@@ -522,10 +750,63 @@ func (p *Parser) parseGroupedExp() (ast.Expression, error) {
 	}
 	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing bracket (`)`) after the grouped expression, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after the grouped expression, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	return exp, nil
 }
+
+// ------------------------------------------------------------------------------------------------------------------
+// Function Literal
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
+	fn := &ast.Function{Token: p.currToken, Name: nil, Parameters: nil, ReturnTypes: nil, Body: nil}
+	exp := &ast.FunctionLiteral{Token: p.currToken, Function: fn}
+
+	params, err := p.parseFunctionParams()
+	if err != nil {
+		return nil, err
+	}
+	fn.Parameters = params
+
+	if p.peekTokenIsOk(lexer.COLON) {
+		ret, err := p.parseFunctionReturnTypes()
+		if err != nil {
+			return nil, err
+		}
+		fn.ReturnTypes = ret
+	}
+
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the function literal signature, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	// the body sees the variables around the literal, but `break`/`continue` of an enclosing loop
+	// and `return` of the enclosing function don't reach into it
+	inFunction, inLoop, currFunction := p.inFunction, p.inLoop, p.currFunction
+	p.inFunction, p.inLoop, p.currFunction = true, false, fn
+	p.stack.Push(environment.BootstrapFuncEnv(fn, p.stack.Top()))
+	errCount := len(p.errors)
+	body, err := p.parseBody()
+	p.stack.Pop()
+	p.inFunction, p.inLoop, p.currFunction = inFunction, inLoop, currFunction
+	if err != nil {
+		return nil, err
+	}
+	fn.Body = body
+
+	if !p.inTesting && len(p.errors) == errCount {
+		err = typeCheckFunction(fn)
+		if err != nil {
+			return nil, diagnostic.Wrap(fn.Pos(), err)
+		}
+	}
+	exp.Type = fn.FunctionType()
+	return exp, nil
+}
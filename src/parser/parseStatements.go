@@ -1,10 +1,11 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	"github.com/KhushPatibandha/Kolon/src/environment"
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
@@ -28,9 +29,24 @@ func (p *Parser) parseStatement() (ast.Statement, error) {
 			return nil, nil
 		}
 		return stmt, nil
+	case lexer.STRUCT:
+		return p.parseStruct()
+	case lexer.ENUM:
+		return p.parseEnum()
+	case lexer.MATCH:
+		return p.parseMatch()
+	case lexer.IMPORT:
+		stmt, err := p.parseImport()
+		if err != nil || stmt == nil {
+			return nil, err
+		}
+		return stmt, nil
 	case lexer.IF:
 		return p.parseIf()
 	case lexer.FOR:
+		if p.isForEach() {
+			return p.parseForEach()
+		}
 		return p.parseForLoop()
 	case lexer.WHILE:
 		return p.parseWhileLoop()
@@ -50,9 +66,13 @@ func (p *Parser) parseBody() (*ast.Body, error) {
 	body := &ast.Body{Token: p.currToken, Statements: []ast.Statement{}}
 	p.nextToken()
 	for !p.currTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+		state := p.saveState()
 		stmt, err := p.parseStatement()
 		if err != nil {
-			return nil, err
+			if !p.recover(state, err, false) {
+				return nil, errAbandoned
+			}
+			continue
 		}
 		body.Statements = append(body.Statements, stmt)
 		p.nextToken()
@@ -64,13 +84,44 @@ func (p *Parser) parseBody() (*ast.Body, error) {
 // Types
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseType() (*ktype.Type, error) {
-	if !p.expectedPeekToken(lexer.TYPE) {
+	var stmt *ktype.Type
+	start := p.peekToken.Start
+	switch {
+	case p.expectedPeekToken(lexer.TYPE):
+		stmt = ktype.NewBaseType(p.currToken.Value)
+	case p.expectedPeekToken(lexer.IDENTIFIER):
+		if param := p.lookupTypeParam(p.currToken.Value); param != nil {
+			stmt = param
+			break
+		}
+		name := p.currToken.Value
+		if p.isModule(name) && p.peekTokenIsOk(lexer.DOT) {
+			member, err := p.parseModuleMember()
+			if err != nil {
+				return nil, err
+			}
+			name = member.Value
+		}
+		sym, ok := p.env.GetType(name)
+		if !ok {
+			return nil,
+				diagnostic.New(p.currToken.Start,
+					"unknown type `"+name+"`, expected a datatype or the name of a struct or an enum",
+				)
+		}
+		stmt = sym.Type
+	case p.expectedPeekToken(lexer.FUN):
+		fnType, err := p.parseFunctionType()
+		if err != nil {
+			return nil, err
+		}
+		stmt = fnType
+	default:
 		return nil,
-			errors.New(
-				"expected a type, got: " + lexer.TokenKindString(p.peekToken.Kind),
+			diagnostic.New(p.peekToken.Start,
+				"expected a type, got: "+lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
-	stmt := ktype.NewBaseType(p.currToken.Value)
 
 	for p.peekTokenIsOk(lexer.OPEN_SQUARE_BRACKET) {
 		p.nextToken()
@@ -79,43 +130,101 @@ func (p *Parser) parseType() (*ktype.Type, error) {
 			stmt = ktype.NewArrayType(stmt)
 			p.nextToken()
 			continue
-		} else if !p.peekTokenIsOk(lexer.TYPE) {
+		} else if !p.peekTokenIsOk(lexer.TYPE) && !p.peekTokenIsOk(lexer.IDENTIFIER) {
 			return nil,
-				errors.New(
-					"expected a closing square bracket (`]`) " +
-						"for array or a datatype for hashmap, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a closing square bracket (`]`) "+
+						"for array or a datatype for hashmap, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
+		// same rule as for the keys of a hashmap literal, a type parameter is checked where it's instantiated
+		if stmt.Kind != ktype.TypeBase && stmt.Kind != ktype.TypeParam {
+			return nil,
+				diagnostic.New(start,
+					"key in a hashmap can only be of `BaseType`, got: "+stmt.TypeKindToString(),
+				)
+		}
 		val, err := p.parseType()
 		if err != nil {
 			return nil, err
 		}
 		if !p.expectedPeekToken(lexer.CLOSE_SQUARE_BRACKET) {
 			return nil,
-				errors.New(
-					"expected a closing square bracket (`]`) after value type for hashmap, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a closing square bracket (`]`) after value type for hashmap, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 		stmt = ktype.NewHashMapType(stmt, val)
 	}
-	return stmt, nil
+	return stmt.WithToken(lexer.Token{
+		Kind:  lexer.TYPE,
+		Value: stmt.String(),
+		Start: start,
+		End:   p.currToken.End,
+	}), nil
+}
+
+// parseFunctionType parses the part of a function type after the `fun` keyword, eg: `(int, string): (bool)`
+func (p *Parser) parseFunctionType() (*ktype.Type, error) {
+	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the `fun` keyword in function type, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	params := []*ktype.Type{}
+	for !p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
+		param, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+		if p.peekTokenIsOk(lexer.COMMA) {
+			p.nextToken()
+			if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
+				return nil,
+					diagnostic.New(p.peekToken.Start,
+						"expected a datatype after comma (`,`) in function type, got: "+
+							lexer.TokenKindString(p.peekToken.Kind),
+					)
+			}
+		} else if !p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a closing bracket (`)`) or a comma (`,`) after the parameter type "+
+						"in function type, got: "+lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+	}
+	p.nextToken()
+
+	var returnTypes []*ktype.Type
+	if p.peekTokenIsOk(lexer.COLON) {
+		ret, err := p.parseFunctionReturnTypes()
+		if err != nil {
+			return nil, err
+		}
+		returnTypes = ret
+	}
+	return ktype.NewFunctionType(params, returnTypes), nil
 }
 
 // ------------------------------------------------------------------------------------------------------------------
 // Expression Statements
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseExpressionStatement() (ast.Statement, error) {
-	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("everything must be inside a function")
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "everything must be inside a function")
 	}
 	stmt := &ast.ExpressionStatement{Token: p.currToken}
 
 	if p.peekTokenIsOk(lexer.COMMA) {
 		stmt.Expression = &ast.Assignment{
 			Token:    lexer.Token{Kind: lexer.EQUAL_ASSIGN, Value: "="},
-			Left:     &ast.Identifier{Token: p.currToken, Value: p.currToken.Value},
+			Left:     p.currIdentifier(),
 			Operator: "=",
 		}
 		list := []ast.Statement{}
@@ -138,19 +247,30 @@ func (p *Parser) parseExpressionStatement() (ast.Statement, error) {
 	case *ast.Assignment:
 		stmt.Expression = t
 	default:
+		if p.interactive && !p.inFunction {
+			bare := &ast.BareExpression{Token: stmt.Token, Expression: exp}
+			if !p.expectedPeekToken(lexer.SEMI_COLON) && !p.peekTokenIsOk(lexer.EOF) {
+				return nil,
+					diagnostic.New(p.peekToken.Start,
+						"expected a semicolon (`;`) or end of input after the expression, got: "+
+							lexer.TokenKindString(p.peekToken.Kind),
+					)
+			}
+			return bare, nil
+		}
 		return nil,
-			errors.New(
-				"expected a function call, postfix expression or an assignment " +
-					"expression for expressions as statements, got: " +
+			diagnostic.New(p.currToken.Start,
+				"expected a function call, postfix expression or an assignment "+
+					"expression for expressions as statements, got: "+
 					fmt.Sprintf("%T", exp),
 			)
 	}
 
 	if !p.expectedPeekToken(lexer.SEMI_COLON) {
-		if !p.inTesting {
+		if !p.inTesting && !(p.interactive && !p.inFunction && p.peekTokenIsOk(lexer.EOF)) {
 			return nil,
-				errors.New(
-					"expected a semicolon (`;`) at the end of the statement, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a semicolon (`;`) at the end of the statement, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
@@ -162,11 +282,16 @@ func (p *Parser) parseExpressionStatement() (ast.Statement, error) {
 // VarAndConst
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseVarConst() (ast.Statement, error) {
-	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("can't declare a variable outside a function")
+	if p.outsideFunction() {
+		err := diagnostic.New(p.currToken.Start, "can't declare a variable outside a function")
+		p.declareMisplacedVars()
+		return nil, err
 	}
 	stmt, err := p.parseVarConstSig()
 	if err != nil {
+		if stmt != nil {
+			p.declareFailedVar(stmt.Name, ktype.NewInvalidType())
+		}
 		return nil, err
 	}
 
@@ -184,6 +309,7 @@ func (p *Parser) parseVarConst() (ast.Statement, error) {
 		p.nextToken()
 		value, err = p.parseExpression(LOWEST)
 		if err != nil {
+			p.declareFailedVar(stmt.Name, stmt.Type)
 			return nil, err
 		}
 	} else {
@@ -192,45 +318,98 @@ func (p *Parser) parseVarConst() (ast.Statement, error) {
 	stmt.Value = value
 
 	if !p.expectedPeekToken(lexer.SEMI_COLON) {
+		p.declareFailedVar(stmt.Name, stmt.Type)
 		return nil,
-			errors.New(
-				"expected a semicolon (`;`) at the end of the statement after " +
-					"variable declaration, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) at the end of the statement after "+
+					"variable declaration, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 
 	err = typeCheckVarAndConst(stmt, p.stack.Top())
 	if err != nil {
-		return nil, err
+		p.declareFailedVar(stmt.Name, stmt.Type)
+		return nil, diagnostic.Wrap(stmt.Pos(), err)
 	}
 	return stmt, nil
 }
 
+// declareFailedVar declares the variable of a declaration that failed anyway, otherwise every later use of
+// it gets reported as undefined too. t is the invalid type if even its type couldn't be parsed
+func (p *Parser) declareFailedVar(name *ast.Identifier, t *ktype.Type) {
+	if _, ok := p.stack.Top().GetVar(name.Value); !ok && !p.interactive {
+		p.stack.Top().Set(&environment.Symbol{
+			IdentType: environment.VAR,
+			Ident:     name,
+			Type:      t,
+		})
+	}
+}
+
+// declareFailedVars declares the variables of a failed multi-assignment, like the one of a failed `var`
+func (p *Parser) declareFailedVars(list []ast.Statement) {
+	for _, s := range list {
+		if v, ok := s.(*ast.VarAndConst); ok {
+			p.declareFailedVar(v.Name, v.Type)
+		}
+	}
+}
+
+// declareMisplacedVars declares the variables of a `var` or `const` written outside a function, so the functions
+// using them don't add errors of their own. it stops at the first part of the statement that isn't a signature
+func (p *Parser) declareMisplacedVars() {
+	for {
+		sig, err := p.parseVarConstSig()
+		if sig == nil {
+			return
+		}
+		if err != nil {
+			p.declareFailedVar(sig.Name, ktype.NewInvalidType())
+			return
+		}
+		p.declareFailedVar(sig.Name, sig.Type)
+
+		// the other targets of a multi-assignment, eg: `var a: int, b, var c: int = ...`
+		if !p.expectedPeekToken(lexer.COMMA) {
+			return
+		}
+		for p.peekTokenIsOk(lexer.IDENTIFIER) {
+			p.nextToken()
+			if !p.expectedPeekToken(lexer.COMMA) {
+				return
+			}
+		}
+		if !p.expectedPeekToken(lexer.VAR) && !p.expectedPeekToken(lexer.CONST) {
+			return
+		}
+	}
+}
+
 func (p *Parser) parseVarConstSig() (*ast.VarAndConst, error) {
 	stmt := &ast.VarAndConst{Token: p.currToken}
 	if !p.expectedPeekToken(lexer.IDENTIFIER) {
 		return nil,
-			errors.New(
-				"expected an identifier after `" +
-					p.currToken.Value + "` keyword, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an identifier after `"+
+					p.currToken.Value+"` keyword, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
-	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Value}
+	stmt.Name = p.currIdentifier()
 
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the identifier `" +
-					stmt.Name.Value + "`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the identifier `"+
+					stmt.Name.Value+"`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 
 	typ, err := p.parseType()
 	if err != nil {
-		return nil, err
+		return stmt, err
 	}
 	stmt.Type = typ
 	stmt.Name.Type = typ
@@ -241,16 +420,21 @@ func (p *Parser) parseVarConstSig() (*ast.VarAndConst, error) {
 // ------------------------------------------------------------------------------------------------------------------
 // Multi-Assignment
 // ------------------------------------------------------------------------------------------------------------------
-func (p *Parser) parseMultiAssign(list []ast.Statement) (*ast.MultiAssignment, error) {
-	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("can't declare a variable outside a function")
+func (p *Parser) parseMultiAssign(list []ast.Statement) (stmt *ast.MultiAssignment, err error) {
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "can't declare a variable outside a function")
 	}
-	stmt := &ast.MultiAssignment{Token: lexer.Token{Kind: lexer.EQUAL_ASSIGN, Value: "="}}
+	defer func() {
+		if err != nil {
+			p.declareFailedVars(list)
+		}
+	}()
+	stmt = &ast.MultiAssignment{Token: lexer.Token{Kind: lexer.EQUAL_ASSIGN, Value: "="}}
 
 	if p.currTokenIsOk(lexer.EQUAL_ASSIGN) {
 		return nil,
-			errors.New(
-				"expected an identifier or a `var`/`const` keyword after the comma (`,`), got: " +
+			diagnostic.New(p.currToken.Start,
+				"expected an identifier or a `var`/`const` keyword after the comma (`,`), got: "+
 					lexer.TokenKindString(p.currToken.Kind),
 			)
 	}
@@ -265,8 +449,8 @@ func (p *Parser) parseMultiAssign(list []ast.Statement) (*ast.MultiAssignment, e
 		if p.currTokenIsOk(lexer.COMMA) {
 			if p.peekTokenIsOk(lexer.EQUAL_ASSIGN) {
 				return nil,
-					errors.New(
-						"expected an identifier or a `var`/`const` keyword after the comma (`,`), got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected an identifier or a `var`/`const` keyword after the comma (`,`), got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -275,8 +459,8 @@ func (p *Parser) parseMultiAssign(list []ast.Statement) (*ast.MultiAssignment, e
 			break
 		} else {
 			return nil,
-				errors.New(
-					"expected a comma (`,`) or an equal sign (`=`), got: " +
+				diagnostic.New(p.currToken.Start,
+					"expected a comma (`,`) or an equal sign (`=`), got: "+
 						lexer.TokenKindString(p.currToken.Kind),
 				)
 		}
@@ -298,7 +482,7 @@ func (p *Parser) parseMultiAssign(list []ast.Statement) (*ast.MultiAssignment, e
 
 	err = typeCheckMultiAssign(stmt, p.stack.Top())
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(stmt.Pos(), err)
 	}
 	return stmt, nil
 }
@@ -308,6 +492,9 @@ func (p *Parser) parseMultiAssignLHS() (ast.Statement, error) {
 	case p.currTokenIsOk(lexer.VAR), p.currTokenIsOk(lexer.CONST):
 		ele, err := p.parseVarConstSig()
 		if err != nil {
+			if ele != nil {
+				p.declareFailedVar(ele.Name, ktype.NewInvalidType())
+			}
 			return nil, err
 		}
 		p.nextToken()
@@ -317,7 +504,7 @@ func (p *Parser) parseMultiAssignLHS() (ast.Statement, error) {
 			Token: p.currToken,
 			Expression: &ast.Assignment{
 				Token:    lexer.Token{Kind: lexer.EQUAL_ASSIGN, Value: "="},
-				Left:     &ast.Identifier{Token: p.currToken, Value: p.currToken.Value},
+				Left:     p.currIdentifier(),
 				Operator: "=",
 			},
 		}
@@ -325,8 +512,8 @@ func (p *Parser) parseMultiAssignLHS() (ast.Statement, error) {
 		return ele, nil
 	default:
 		return nil,
-			errors.New(
-				"expected an identifier or a `var`/`const` keyword, got: " +
+			diagnostic.New(p.currToken.Start,
+				"expected an identifier or a `var`/`const` keyword, got: "+
 					lexer.TokenKindString(p.currToken.Kind),
 			)
 	}
@@ -346,9 +533,9 @@ func (p *Parser) parseMultiAssignRHS() ([]ast.Expression, error) {
 		if p.currTokenIsOk(lexer.COMMA) {
 			if p.peekTokenIsOk(lexer.SEMI_COLON) {
 				return nil,
-					errors.New(
-						"expected an expression after comma (`,`) in " +
-							"multi-value assignment statement, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected an expression after comma (`,`) in "+
+							"multi-value assignment statement, got: "+
 							lexer.TokenKindString(p.peekToken.Kind))
 			}
 			p.nextToken()
@@ -356,8 +543,8 @@ func (p *Parser) parseMultiAssignRHS() ([]ast.Expression, error) {
 			break
 		} else {
 			return nil,
-				errors.New(
-					"expected a comma (`,`) or a semicolon (`;`) after the expression, got: " +
+				diagnostic.New(p.currToken.Start,
+					"expected a comma (`,`) or a semicolon (`;`) after the expression, got: "+
 						lexer.TokenKindString(p.currToken.Kind))
 		}
 	}
@@ -382,10 +569,10 @@ func (p *Parser) matchAssignments(left []ast.Statement,
 	}
 
 	if len(left) != len(right) {
-		return errors.New(
-			"number of expression on the right side of multi-assignment do not " +
-				"match the number of declarations on the left, left: " +
-				fmt.Sprint(len(left)) + ", right: " + fmt.Sprint(len(right)),
+		return diagnostic.New(p.currToken.Start,
+			"number of expression on the right side of multi-assignment do not "+
+				"match the number of declarations on the left, left: "+
+				fmt.Sprint(len(left))+", right: "+fmt.Sprint(len(right)),
 		)
 	}
 
@@ -406,52 +593,105 @@ func (p *Parser) matchAssignments(left []ast.Statement,
 // ------------------------------------------------------------------------------------------------------------------
 // Function
 // ------------------------------------------------------------------------------------------------------------------
-func (p *Parser) parseFunction() (*ast.Function, error) {
+func (p *Parser) parseFunction() (_ *ast.Function, err error) {
 	if p.inFunction {
-		return nil, errors.New("can't declare a function inside a function")
+		return nil, diagnostic.New(p.currToken.Start, "can't declare a function inside a function")
 	}
 	stmt := &ast.Function{Token: p.currToken, Parameters: nil, ReturnTypes: nil, Body: nil}
 
+	// declare the name of a function that failed before it was declared, otherwise every call to it gets
+	// reported as a call to an unknown function too
+	defer func() {
+		if err == nil || stmt.Name == nil || p.interactive {
+			return
+		}
+		if _, ok := p.env.GetFunc(stmt.Name.Value); ok {
+			return
+		}
+		p.env.Set(&environment.Symbol{
+			IdentType: environment.FUNCTION,
+			Ident:     stmt.Name,
+			Func:      &environment.FuncInfo{Function: stmt, Builtin: false},
+			Type:      ktype.NewInvalidType(),
+		})
+	}()
+
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the `fun` keyword, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `fun` keyword, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.IDENTIFIER) {
 		return nil,
-			errors.New(
-				"expected an identifier(function name) after the colon (`:`), got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an identifier(function name) after the colon (`:`), got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
-	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Value}
+	stmt.Name = p.currIdentifier()
+
+	if stmt.Name.Value == "main" && p.module != "" {
+		return nil,
+			diagnostic.New(p.currToken.Start,
+				"module `"+p.module+"` can't declare a `main` function, only the file being run has one",
+			)
+	}
 
 	if existing, ok := p.env.GetFunc(stmt.Name.Value); ok &&
 		existing.Func.Builtin && !p.inTesting {
 		return nil,
-			errors.New(
-				"can't override a built-in function, function `" +
-					stmt.Name.Value + "` already exists",
+			diagnostic.New(p.currToken.Start,
+				"can't override a built-in function, function `"+
+					stmt.Name.Value+"` already exists",
 			)
 	}
 
 	if existing, ok := p.env.GetFunc(stmt.Name.Value); ok &&
 		existing.Func.Function.Body != nil && !p.inTesting {
 		return nil,
-			errors.New(
-				"can't declare a function twice, function with the same name `" +
-					stmt.Name.Value + "` already exists",
+			diagnostic.New(p.currToken.Start,
+				"can't declare a function twice, function with the same name `"+
+					stmt.Name.Value+"` already exists",
 			)
 	}
 
+	defer func() { p.typeParams = nil }()
+	if p.peekTokenIsOk(lexer.LESS_THAN) {
+		typeParams, err := p.parseTypeParams()
+		if err != nil {
+			return nil, err
+		}
+		stmt.TypeParams = typeParams
+		p.typeParams = typeParams
+	}
+
 	params, err := p.parseFunctionParams()
 	if err != nil {
 		return nil, err
 	}
 	stmt.Parameters = params
 
+	// type parameters are inferred from the arguments, so each of them has to be used by a parameter
+	paramTypes := []*ktype.Type{}
+	for _, param := range params {
+		paramTypes = append(paramTypes, param.ParameterType)
+	}
+	used := map[string]bool{}
+	for _, typeParam := range ktype.TypeParams(paramTypes) {
+		used[typeParam.Name] = true
+	}
+	for _, typeParam := range stmt.TypeParams {
+		if !used[typeParam.Name] {
+			return nil,
+				diagnostic.New(stmt.Name.Pos(),
+					"type parameter `"+typeParam.Name+"` of function `"+stmt.Name.Value+
+						"` must be used by one of its parameters, it's inferred from the arguments of each call",
+				)
+		}
+	}
+
 	if p.peekTokenIsOk(lexer.COLON) {
 		ret, err := p.parseFunctionReturnTypes()
 		if err != nil {
@@ -464,9 +704,9 @@ func (p *Parser) parseFunction() (*ast.Function, error) {
 		p.nextToken()
 		if _, ok := p.env.GetFunc(stmt.Name.Value); ok {
 			return nil,
-				errors.New(
-					"can't declare a function twice, function with the same name. `" +
-						stmt.Name.Value + "` already exists",
+				diagnostic.New(p.currToken.Start,
+					"can't declare a function twice, function with the same name. `"+
+						stmt.Name.Value+"` already exists",
 				)
 		}
 
@@ -489,9 +729,9 @@ func (p *Parser) parseFunction() (*ast.Function, error) {
 
 	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open curly bracket (`{`) after the function (`" +
-					stmt.Name.Value + "`) signature, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the function (`"+
+					stmt.Name.Value+"`) signature, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -499,8 +739,8 @@ func (p *Parser) parseFunction() (*ast.Function, error) {
 	existing, ok := p.env.GetFunc(stmt.Name.Value)
 	if ok && !p.compareFunctionSig(existing.Func.Function, stmt) {
 		return nil,
-			errors.New(
-				"function signature of " + stmt.Name.Value +
+			diagnostic.New(p.currToken.Start,
+				"function signature of "+stmt.Name.Value+
 					" doesn't match with previously declared signature",
 			)
 	}
@@ -522,6 +762,7 @@ func (p *Parser) parseFunction() (*ast.Function, error) {
 
 	p.inFunction = true
 	p.stack.Push(f.Env)
+	errCount := len(p.errors)
 	funBody, err := p.parseBody()
 	if err != nil {
 		return nil, err
@@ -532,10 +773,12 @@ func (p *Parser) parseFunction() (*ast.Function, error) {
 
 	f.Func.Function.Body = funBody
 
-	if !p.inTesting {
+	// statements that failed to parse are missing from the body, checking it for
+	// a `return` at the end would only add noise to the errors already reported
+	if !p.inTesting && len(p.errors) == errCount {
 		err = typeCheckFunction(f.Func.Function)
 		if err != nil {
-			return nil, err
+			return nil, diagnostic.Wrap(f.Func.Function.Pos(), err)
 		}
 	}
 
@@ -546,14 +789,306 @@ func (p *Parser) parseFunction() (*ast.Function, error) {
 	return f.Func.Function, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Struct
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseStruct() (*ast.Struct, error) {
+	if p.inFunction {
+		return nil, diagnostic.New(p.currToken.Start, "can't declare a struct inside a function")
+	}
+	stmt := &ast.Struct{Token: p.currToken, Fields: []*ast.StructField{}}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `struct` keyword, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.IDENTIFIER) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an identifier(struct name) after the colon (`:`), got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	stmt.Name = p.currIdentifier()
+
+	if err := p.typeNameTaken(stmt.Name, "struct"); err != nil {
+		return nil, err
+	}
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the struct name `"+
+					stmt.Name.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	// declared before reading the fields, so they can refer to the struct itself, eg: `children: Node[];`
+	structType := ktype.NewStructType(p.qualified(stmt.Name.Value))
+	p.env.Set(&environment.Symbol{
+		IdentType: environment.STRUCT,
+		Ident:     stmt.Name,
+		Struct:    stmt,
+		Type:      structType,
+	})
+	if err := p.parseStructFields(stmt, structType); err != nil {
+		if p.interactive {
+			// the repl forgets the failed input, the struct must be declarable again once it's fixed
+			delete(p.env.TypeNameSpace, stmt.Name.Value)
+		}
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseStructFields(stmt *ast.Struct, structType *ktype.Type) error {
+	for !p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+		if !p.expectedPeekToken(lexer.IDENTIFIER) {
+			return diagnostic.New(p.peekToken.Start,
+				"expected a field name or a closing curly bracket (`}`) in struct `"+
+					stmt.Name.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+		}
+		field := &ast.StructField{
+			FieldName: p.currIdentifier(),
+		}
+		if stmt.FieldIndex(field.FieldName.Value) != -1 {
+			return diagnostic.New(p.currToken.Start,
+				"field `"+field.FieldName.Value+"` is declared twice in struct `"+
+					stmt.Name.Value+"`",
+			)
+		}
+		if !p.expectedPeekToken(lexer.COLON) {
+			return diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the field `"+
+					field.FieldName.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+		}
+		fieldType, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		if fieldType.Equals(structType) {
+			return diagnostic.New(fieldType.Token.Start,
+				"struct `"+stmt.Name.Value+"` can't have a field of its own type, "+
+					"a value of it could never be created. use an array (`"+
+					stmt.Name.Value+"[]`) instead",
+			)
+		}
+		field.FieldType = fieldType
+		field.FieldName.Type = fieldType
+
+		if !p.expectedPeekToken(lexer.SEMI_COLON) {
+			return diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) after the type of the field `"+
+					field.FieldName.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+		}
+		stmt.Fields = append(stmt.Fields, field)
+	}
+	p.nextToken()
+	return nil
+}
+
+// typeNameTaken returns an error if a struct or an enum with the same name as the kind (`struct`, `enum`) being
+// declared already exists
+func (p *Parser) typeNameTaken(name *ast.Identifier, kind string) error {
+	if _, ok := p.imports[name.Value]; ok {
+		return diagnostic.New(name.Pos(),
+			"can't declare "+kind+" `"+name.Value+"`, a module with the same name is imported",
+		)
+	}
+	sym, ok := p.env.GetType(name.Value)
+	if !ok {
+		return nil
+	}
+	existing := "a struct"
+	if sym.IdentType == environment.ENUM {
+		existing = "an enum"
+	}
+	return diagnostic.New(name.Pos(),
+		"can't declare "+kind+" `"+name.Value+"`, "+existing+
+			" with the same name already exists",
+	)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseEnum() (*ast.Enum, error) {
+	if p.inFunction {
+		return nil, diagnostic.New(p.currToken.Start, "can't declare an enum inside a function")
+	}
+	stmt := &ast.Enum{Token: p.currToken, Variants: []*ast.EnumVariant{}}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `enum` keyword, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.IDENTIFIER) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an identifier(enum name) after the colon (`:`), got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	stmt.Name = p.currIdentifier()
+
+	if err := p.typeNameTaken(stmt.Name, "enum"); err != nil {
+		return nil, err
+	}
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the enum name `"+
+					stmt.Name.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	// declared before reading the variants, so they can carry values of the enum itself, eg: `Node(int, Tree);`
+	p.env.Set(&environment.Symbol{
+		IdentType: environment.ENUM,
+		Ident:     stmt.Name,
+		Enum:      stmt,
+		Type:      ktype.NewEnumType(p.qualified(stmt.Name.Value)),
+	})
+	if err := p.parseEnumVariants(stmt); err != nil {
+		if p.interactive {
+			// the repl forgets the failed input, the enum must be declarable again once it's fixed
+			delete(p.env.TypeNameSpace, stmt.Name.Value)
+		}
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseEnumVariants(stmt *ast.Enum) error {
+	for !p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+		if !p.expectedPeekToken(lexer.IDENTIFIER) {
+			return diagnostic.New(p.peekToken.Start,
+				"expected a variant name or a closing curly bracket (`}`) in enum `"+
+					stmt.Name.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+		}
+		variant := &ast.EnumVariant{
+			Name: p.currIdentifier(),
+		}
+		if stmt.VariantIndex(variant.Name.Value) != -1 {
+			return diagnostic.New(p.currToken.Start,
+				"variant `"+variant.Name.Value+"` is declared twice in enum `"+
+					stmt.Name.Value+"`",
+			)
+		}
+
+		if p.peekTokenIsOk(lexer.OPEN_BRACKET) {
+			p.nextToken()
+			variant.Types = []*ktype.Type{}
+			for {
+				t, err := p.parseType()
+				if err != nil {
+					return err
+				}
+				variant.Types = append(variant.Types, t)
+				if !p.peekTokenIsOk(lexer.COMMA) {
+					break
+				}
+				p.nextToken()
+			}
+			if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
+				return diagnostic.New(p.peekToken.Start,
+					"expected a comma (`,`) or a close bracket (`)`) after the type in variant `"+
+						variant.Name.Value+"`, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+			}
+		}
+
+		if !p.expectedPeekToken(lexer.SEMI_COLON) {
+			return diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) after the variant `"+
+					variant.Name.Value+"`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+		}
+		stmt.Variants = append(stmt.Variants, variant)
+	}
+	if len(stmt.Variants) == 0 {
+		return diagnostic.New(p.peekToken.Start,
+			"enum `"+stmt.Name.Value+"` must have at least one variant",
+		)
+	}
+	p.nextToken()
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Type Params: The type parameters of a generic function, eg: `<T>` or `<K, V>`
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseTypeParams() ([]*ktype.Type, error) {
+	p.nextToken()
+	typeParams := []*ktype.Type{}
+	for {
+		if !p.expectedPeekToken(lexer.IDENTIFIER) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected an identifier(type parameter name) in the type parameters, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		name := p.currIdentifier()
+		if err := p.typeNameTaken(name, "type parameter"); err != nil {
+			return nil, err
+		}
+		for _, existing := range typeParams {
+			if existing.Name == name.Value {
+				return nil,
+					diagnostic.New(name.Pos(), "type parameter `"+name.Value+"` is declared twice")
+			}
+		}
+		typeParams = append(typeParams, ktype.NewTypeParam(name.Value))
+
+		if p.peekTokenIsOk(lexer.GREATER_THAN) {
+			p.nextToken()
+			return typeParams, nil
+		}
+		if !p.expectedPeekToken(lexer.COMMA) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a comma (`,`) or a greater than sign (`>`) after the type parameter, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+	}
+}
+
+func (p *Parser) lookupTypeParam(name string) *ktype.Type {
+	for _, param := range p.typeParams {
+		if param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Function Params
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseFunctionParams() ([]*ast.FunctionParameter, error) {
 	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open bracket (`(`) after the function name, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the function name, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -565,21 +1100,21 @@ func (p *Parser) parseFunctionParams() ([]*ast.FunctionParameter, error) {
 	for {
 		if !p.expectedPeekToken(lexer.IDENTIFIER) {
 			return nil,
-				errors.New(
-					"expected an identifier or a close bracket (`)`) after " +
-						"the open bracket (`(`) for function parameters, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected an identifier or a close bracket (`)`) after "+
+						"the open bracket (`(`) for function parameters, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 		param := &ast.FunctionParameter{
-			ParameterName: &ast.Identifier{Token: p.currToken, Value: p.currToken.Value},
+			ParameterName: p.currIdentifier(),
 		}
 		if !p.expectedPeekToken(lexer.COLON) {
 			return nil,
-				errors.New(
-					"expected a colon (`:`) after the parameter " +
-						param.ParameterName.Value +
-						", got: " + lexer.TokenKindString(p.peekToken.Kind),
+				diagnostic.New(p.peekToken.Start,
+					"expected a colon (`:`) after the parameter "+
+						param.ParameterName.Value+
+						", got: "+lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 		paramType, err := p.parseType()
@@ -593,8 +1128,8 @@ func (p *Parser) parseFunctionParams() ([]*ast.FunctionParameter, error) {
 			p.nextToken()
 			if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
 				return nil,
-					errors.New(
-						"expected an identifier after comma (`,`) for function parameters, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected an identifier after comma (`,`) for function parameters, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -602,16 +1137,16 @@ func (p *Parser) parseFunctionParams() ([]*ast.FunctionParameter, error) {
 			break
 		} else {
 			return nil,
-				errors.New(
-					"expected a closing bracket (`)`) or a comma (`,`) after the parameter type, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a closing bracket (`)`) or a comma (`,`) after the parameter type, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 	}
 	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing bracket (`)`) after function parameters, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after function parameters, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -624,21 +1159,21 @@ func (p *Parser) parseFunctionParams() ([]*ast.FunctionParameter, error) {
 func (p *Parser) parseFunctionReturnTypes() ([]*ktype.Type, error) {
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after function parameters, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after function parameters, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open bracket (`(`) after the colon (`:`), got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the colon (`:`), got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
+			diagnostic.New(p.currToken.Start,
 				"expected at least one return type after open bracket (`(`), got: CLOSE_BRACKET",
 			)
 	}
@@ -656,8 +1191,8 @@ func (p *Parser) parseFunctionReturnTypes() ([]*ktype.Type, error) {
 			p.nextToken()
 			if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
 				return nil,
-					errors.New(
-						"expected a datatype after comma (`,`), got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected a datatype after comma (`,`), got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -665,17 +1200,17 @@ func (p *Parser) parseFunctionReturnTypes() ([]*ktype.Type, error) {
 			break
 		} else {
 			return nil,
-				errors.New(
-					"expected a closing bracket (`)`) or a comma (`,`) " +
-						"after the parameter datatype, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a closing bracket (`)`) or a comma (`,`) "+
+						"after the parameter datatype, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 	}
 	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing bracket (`)`) after the return types, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after the return types, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -686,21 +1221,21 @@ func (p *Parser) parseFunctionReturnTypes() ([]*ktype.Type, error) {
 // If
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseIf() (*ast.If, error) {
-	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("if statement can only be used inside a function")
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "if statement can only be used inside a function")
 	}
 	stmt := &ast.If{Token: p.currToken}
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after `if` keyword, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after `if` keyword, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open bracket (`(`) after the colon (`:`) in `if` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the colon (`:`) in `if` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -712,20 +1247,20 @@ func (p *Parser) parseIf() (*ast.If, error) {
 
 	err = typeCheckBoolCon(stmt.Condition, "if")
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(stmt.Condition.Pos(), err)
 	}
 
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after grouped expression for `if` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after grouped expression for `if` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
 		return nil,
-			errors.New("expected an open curly bracket (`{`) after the " +
-				"colon (`:`) in `if` statement, got: " +
+			diagnostic.New(p.peekToken.Start, "expected an open curly bracket (`{`) after the "+
+				"colon (`:`) in `if` statement, got: "+
 				lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -752,15 +1287,15 @@ func (p *Parser) parseIf() (*ast.If, error) {
 			elseIfStmt := &ast.ElseIf{Token: p.currToken}
 			if !p.expectedPeekToken(lexer.COLON) {
 				return nil,
-					errors.New(
-						"expected a colon (`:`) after the `else if` keyword, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected a colon (`:`) after the `else if` keyword, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
 			if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
 				return nil,
-					errors.New(
-						"expected an open bracket (`(`) after the colon (`:`) in `else if` statement, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected an open bracket (`(`) after the colon (`:`) in `else if` statement, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -771,22 +1306,22 @@ func (p *Parser) parseIf() (*ast.If, error) {
 			elseIfStmt.Condition = elseIfCondition
 			err = typeCheckBoolCon(elseIfStmt.Condition, "else if")
 			if err != nil {
-				return nil, err
+				return nil, diagnostic.Wrap(elseIfStmt.Condition.Pos(), err)
 			}
 
 			if !p.expectedPeekToken(lexer.COLON) {
 				return nil,
-					errors.New(
-						"expected a colon (`:`) after grouped " +
-							"expression for `else if` statement, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected a colon (`:`) after grouped "+
+							"expression for `else if` statement, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
 			if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
 				return nil,
-					errors.New(
-						"expected an open curly bracket (`{`) after " +
-							"the colon (`:`) in `else if` statement, got: " +
+					diagnostic.New(p.peekToken.Start,
+						"expected an open curly bracket (`{`) after "+
+							"the colon (`:`) in `else if` statement, got: "+
 							lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -817,16 +1352,16 @@ func (p *Parser) parseIf() (*ast.If, error) {
 
 		if !p.expectedPeekToken(lexer.COLON) {
 			return nil,
-				errors.New(
-					"expected a colon (`:`) after the `else` keyword, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected a colon (`:`) after the `else` keyword, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
 		if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
 			return nil,
-				errors.New(
-					"expected an open curly bracket (`{`) after the " +
-						"colon (`:`) in `else` statement, got: " +
+				diagnostic.New(p.peekToken.Start,
+					"expected an open curly bracket (`{`) after the "+
+						"colon (`:`) in `else` statement, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 				)
 		}
@@ -850,12 +1385,227 @@ func (p *Parser) parseIf() (*ast.If, error) {
 	return stmt, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Match
+// ------------------------------------------------------------------------------------------------------------------
+func (p *Parser) parseMatch() (*ast.Match, error) {
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "match statement can only be used inside a function")
+	}
+	stmt := &ast.Match{Token: p.currToken, Arms: []*ast.MatchArm{}}
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after `match` keyword, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the colon (`:`) in `match` statement, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	value, err := p.parseGroupedExp()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Value = value
+
+	enum, err := typeCheckMatchValue(stmt.Value, p.stack.Top())
+	if err != nil {
+		return nil, diagnostic.Wrap(stmt.Value.Pos(), err)
+	}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after grouped expression for `match` statement, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start, "expected an open curly bracket (`{`) after the "+
+				"colon (`:`) in `match` statement, got: "+
+				lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	for !p.peekTokenIsOk(lexer.CLOSE_CURLY_BRACKET) {
+		if stmt.Alternate != nil {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"`else` arm must be the last arm in `match` statement, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		if p.peekTokenIsOk(lexer.ELSE) {
+			p.nextToken()
+			alternate, err := p.parseMatchElse()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Alternate = alternate
+			continue
+		}
+		if !p.expectedPeekToken(lexer.IDENTIFIER) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a variant of enum `"+enum.Name.Value+"`, an `else` arm or a "+
+						"closing curly bracket (`}`) in `match` statement, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		arm, err := p.parseMatchArm(stmt, enum)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Arms = append(stmt.Arms, arm)
+	}
+	p.nextToken()
+
+	if err := typeCheckMatch(stmt, enum); err != nil {
+		return nil, diagnostic.Wrap(stmt.Pos(), err)
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseMatchArm(stmt *ast.Match, enum *ast.Enum) (*ast.MatchArm, error) {
+	arm := &ast.MatchArm{
+		Variant: p.currIdentifier(),
+		Index:   enum.VariantIndex(p.currToken.Value),
+	}
+	if arm.Index == -1 {
+		return nil,
+			diagnostic.New(arm.Pos(),
+				"enum `"+enum.Name.Value+"` has no variant `"+arm.Variant.Value+"`",
+			)
+	}
+	for _, other := range stmt.Arms {
+		if other.Index == arm.Index {
+			return nil,
+				diagnostic.New(arm.Pos(),
+					"variant `"+arm.Variant.Value+"` is matched twice in `match` statement",
+				)
+		}
+	}
+	variant := enum.Variants[arm.Index]
+
+	if p.peekTokenIsOk(lexer.OPEN_BRACKET) {
+		p.nextToken()
+		arm.Bindings = []*ast.Identifier{}
+		for {
+			if !p.expectedPeekToken(lexer.IDENTIFIER) {
+				return nil,
+					diagnostic.New(p.peekToken.Start,
+						"expected an identifier for the value of variant `"+arm.Variant.Value+
+							"`, got: "+lexer.TokenKindString(p.peekToken.Kind),
+					)
+			}
+			arm.Bindings = append(arm.Bindings, p.currIdentifier())
+			if !p.peekTokenIsOk(lexer.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+		if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a comma (`,`) or a close bracket (`)`) after the identifier in "+
+						"`match` arm, got: "+lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		if len(arm.Bindings) != len(variant.Types) {
+			return nil,
+				diagnostic.New(arm.Pos(),
+					"variant `"+arm.Variant.Value+"` carries "+strconv.Itoa(len(variant.Types))+
+						" value(s), got: "+strconv.Itoa(len(arm.Bindings))+" identifier(s)",
+				)
+		}
+	}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the variant `"+arm.Variant.Value+
+					"` in `match` statement, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the colon (`:`) in `match` arm, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	armLocalEnv := environment.NewEnclosedEnvironment(p.stack.Top())
+	for i, binding := range arm.Bindings {
+		if _, ok := armLocalEnv.VariableNameSpace[binding.Value]; ok {
+			return nil,
+				diagnostic.New(binding.Pos(),
+					"identifier `"+binding.Value+"` is used twice in `match` arm",
+				)
+		}
+		binding.Type = variant.Types[i]
+		armLocalEnv.Set(&environment.Symbol{
+			IdentType: environment.VAR,
+			Ident:     binding,
+			Type:      variant.Types[i],
+		})
+	}
+	p.stack.Push(armLocalEnv)
+
+	body, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+
+	p.stack.Pop()
+	arm.Body = body
+	return arm, nil
+}
+
+func (p *Parser) parseMatchElse() (*ast.Else, error) {
+	elseStmt := &ast.Else{Token: p.currToken}
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `else` keyword, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the "+
+					"colon (`:`) in `else` arm, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	elseLocalEnv := environment.NewEnclosedEnvironment(p.stack.Top())
+	p.stack.Push(elseLocalEnv)
+
+	body, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+
+	p.stack.Pop()
+	elseStmt.Body = body
+	return elseStmt, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Return
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseReturn() (*ast.Return, error) {
 	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("return statement can only be used inside a function")
+		return nil, diagnostic.New(p.currToken.Start, "return statement can only be used inside a function")
 	}
 	stmt := &ast.Return{Token: p.currToken, Value: []ast.Expression{}}
 	if p.peekTokenIsOk(lexer.SEMI_COLON) {
@@ -865,8 +1615,8 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 	}
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the `return` keyword, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `return` keyword, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -874,7 +1624,7 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 	if p.peekTokenIsOk(lexer.OPEN_BRACKET) {
 		p.nextToken()
 		if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
-			return nil, errors.New("expected values after open bracket (`(`) in `return` statement")
+			return nil, diagnostic.New(p.currToken.Start, "expected values after open bracket (`(`) in `return` statement")
 		}
 		p.nextToken()
 		for {
@@ -887,8 +1637,8 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 				p.nextToken()
 				if p.peekTokenIsOk(lexer.CLOSE_BRACKET) {
 					return nil,
-						errors.New(
-							"expected an expression after comma (`,`) in `return` statement, got: " +
+						diagnostic.New(p.peekToken.Start,
+							"expected an expression after comma (`,`) in `return` statement, got: "+
 								lexer.TokenKindString(p.peekToken.Kind),
 						)
 				}
@@ -898,7 +1648,7 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 				break
 			} else {
 				return nil,
-					errors.New("expected a comma (`,`) or a closing bracket (`)`) after the expression, got: " +
+					diagnostic.New(p.peekToken.Start, "expected a comma (`,`) or a closing bracket (`)`) after the expression, got: "+
 						lexer.TokenKindString(p.peekToken.Kind),
 					)
 			}
@@ -912,9 +1662,9 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 		stmt.Value = append(stmt.Value, exp)
 		if p.peekTokenIsOk(lexer.COMMA) {
 			return nil,
-				errors.New(
-					"expected a semicolon (`;`), got: " +
-						lexer.TokenKindString(p.peekToken.Kind) +
+				diagnostic.New(p.peekToken.Start,
+					"expected a semicolon (`;`), got: "+
+						lexer.TokenKindString(p.peekToken.Kind)+
 						". to return multiple values, use `return: (val1, val2, ...)`",
 				)
 		}
@@ -922,13 +1672,13 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 
 	err := typeCheckReturn(stmt, p.currFunction)
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(stmt.Pos(), err)
 	}
 
 	if !p.expectedPeekToken(lexer.SEMI_COLON) {
 		return nil,
-			errors.New(
-				"expected a semicolon (`;`) at the end of `return` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) at the end of `return` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -939,23 +1689,23 @@ func (p *Parser) parseReturn() (*ast.Return, error) {
 // ForLoop
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseForLoop() (*ast.ForLoop, error) {
-	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("for loop can only be used inside a function")
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "for loop can only be used inside a function")
 	}
 	p.inLoop = true
 	stmt := &ast.ForLoop{Token: p.currToken}
 
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the `for` keyword, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `for` keyword, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open bracket (`(`) after the colon (`:`) in `for loop` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the colon (`:`) in `for loop` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -977,16 +1727,16 @@ func (p *Parser) parseForLoop() (*ast.ForLoop, error) {
 	}
 	if _, ok := middle.(*ast.Infix); !ok {
 		return nil,
-			errors.New(
-				"expected an infix expression after the variable declaration in `for loop`, got: " +
+			diagnostic.New(p.currToken.Start,
+				"expected an infix expression after the variable declaration in `for loop`, got: "+
 					fmt.Sprintf("%T", middle),
 			)
 	}
 	stmt.Middle = middle.(*ast.Infix)
 	if !p.expectedPeekToken(lexer.SEMI_COLON) {
 		return nil,
-			errors.New(
-				"expected a semicolon (`;`) after infix expression in `for loop` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) after infix expression in `for loop` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -999,9 +1749,9 @@ func (p *Parser) parseForLoop() (*ast.ForLoop, error) {
 	if _, ok := right.(*ast.Postfix); !ok {
 		if _, ok := right.(*ast.Assignment); !ok {
 			return nil,
-				errors.New(
-					"expected a postfix expression or an assignment expression " +
-						"after the infix expression in `for loop`, got: " +
+				diagnostic.New(p.currToken.Start,
+					"expected a postfix expression or an assignment expression "+
+						"after the infix expression in `for loop`, got: "+
 						fmt.Sprintf("%T", right),
 				)
 		}
@@ -1011,28 +1761,28 @@ func (p *Parser) parseForLoop() (*ast.ForLoop, error) {
 	}
 	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing bracket (`)`) after the postfix expression in `for loop`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after the postfix expression in `for loop`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 
 	err = typeCheckForLoop(stmt, forLoopLocalEnv)
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(stmt.Pos(), err)
 	}
 
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the closing bracket (`)`) in `for loop`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the closing bracket (`)`) in `for loop`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open curly bracket (`{`) after the colon (`:`) in `for loop`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the colon (`:`) in `for loop`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -1047,27 +1797,147 @@ func (p *Parser) parseForLoop() (*ast.ForLoop, error) {
 	return stmt, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// ForEach
+// ------------------------------------------------------------------------------------------------------------------
+
+// isForEach reports if the `for` keyword (the current token) starts a for-each loop, which has an `in` where the
+// other one has the `=` or the `;` of its first statement
+func (p *Parser) isForEach() bool {
+	for i := p.tokenPtr; i < len(p.tokens); i++ {
+		switch p.tokens[i].Kind {
+		case lexer.IN:
+			return true
+		case lexer.EQUAL_ASSIGN, lexer.SEMI_COLON, lexer.OPEN_CURLY_BRACKET, lexer.EOF:
+			return false
+		}
+	}
+	return false
+}
+
+func (p *Parser) parseForEach() (*ast.ForEach, error) {
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "for loop can only be used inside a function")
+	}
+	inLoop := p.inLoop
+	p.inLoop = true
+	stmt := &ast.ForEach{Token: p.currToken}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `for` keyword, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the colon (`:`) in `for loop` statement, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	forEachLocalEnv := environment.NewEnclosedEnvironment(p.stack.Top())
+	p.stack.Push(forEachLocalEnv)
+
+	for {
+		if !p.expectedPeekToken(lexer.VAR) {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"expected a `var` statement before `in` in `for-each` loop, got: "+
+						lexer.TokenKindString(p.peekToken.Kind),
+				)
+		}
+		v, err := p.parseVarConstSig()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Vars = append(stmt.Vars, v)
+		if !p.peekTokenIsOk(lexer.COMMA) {
+			break
+		}
+		if len(stmt.Vars) == 2 {
+			return nil,
+				diagnostic.New(p.peekToken.Start,
+					"`for-each` loop can declare at most 2 variables, the index (or key) and the element (or value)",
+				)
+		}
+		p.nextToken()
+	}
+	if !p.expectedPeekToken(lexer.IN) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected `in` after the variables in `for-each` loop, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	p.nextToken()
+	iterable, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+	stmt.Iterable = iterable
+	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after the expression in `for-each` loop, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+
+	err = typeCheckForEach(stmt, forEachLocalEnv)
+	if err != nil {
+		return nil, diagnostic.Wrap(stmt.Iterable.Pos(), err)
+	}
+
+	if !p.expectedPeekToken(lexer.COLON) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the closing bracket (`)`) in `for loop`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the colon (`:`) in `for loop`, got: "+
+					lexer.TokenKindString(p.peekToken.Kind),
+			)
+	}
+	body, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Body = body
+
+	p.stack.Pop()
+	p.inLoop = inLoop
+	return stmt, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // WhileLoop
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseWhileLoop() (*ast.WhileLoop, error) {
-	if !p.inFunction && !p.inTesting {
-		return nil, errors.New("while loop can only be used inside a function")
+	if p.outsideFunction() {
+		return nil, diagnostic.New(p.currToken.Start, "while loop can only be used inside a function")
 	}
 	p.inLoop = true
 	stmt := &ast.WhileLoop{Token: p.currToken}
 
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the `while` keyword, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the `while` keyword, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open bracket (`(`) after the colon (`:`) in `while loop` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open bracket (`(`) after the colon (`:`) in `while loop` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -1084,27 +1954,27 @@ func (p *Parser) parseWhileLoop() (*ast.WhileLoop, error) {
 
 	err = typeCheckBoolCon(stmt.Condition, "while")
 	if err != nil {
-		return nil, err
+		return nil, diagnostic.Wrap(stmt.Condition.Pos(), err)
 	}
 
 	if !p.expectedPeekToken(lexer.CLOSE_BRACKET) {
 		return nil,
-			errors.New(
-				"expected a closing bracket (`)`) after break condition in `while loop`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a closing bracket (`)`) after break condition in `while loop`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.COLON) {
 		return nil,
-			errors.New(
-				"expected a colon (`:`) after the closing bracket (`)`) in `while loop`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a colon (`:`) after the closing bracket (`)`) in `while loop`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
 	if !p.expectedPeekToken(lexer.OPEN_CURLY_BRACKET) {
 		return nil,
-			errors.New(
-				"expected an open curly bracket (`{`) after the colon (`:`) in `while loop`, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected an open curly bracket (`{`) after the colon (`:`) in `while loop`, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -1124,13 +1994,13 @@ func (p *Parser) parseWhileLoop() (*ast.WhileLoop, error) {
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseContinue() (*ast.Continue, error) {
 	if !p.inLoop {
-		return nil, errors.New("continue statement can only be used inside a `for loop`")
+		return nil, diagnostic.New(p.currToken.Start, "continue statement can only be used inside a `for loop`")
 	}
 	stmt := &ast.Continue{Token: p.currToken}
 	if !p.expectedPeekToken(lexer.SEMI_COLON) {
 		return nil,
-			errors.New(
-				"expected a semicolon (`;`) at the end of `continue` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) at the end of `continue` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -1142,13 +2012,13 @@ func (p *Parser) parseContinue() (*ast.Continue, error) {
 // ------------------------------------------------------------------------------------------------------------------
 func (p *Parser) parseBreak() (*ast.Break, error) {
 	if !p.inLoop {
-		return nil, errors.New("break statement can only be used inside a `for loop`")
+		return nil, diagnostic.New(p.currToken.Start, "break statement can only be used inside a `for loop`")
 	}
 	stmt := &ast.Break{Token: p.currToken}
 	if !p.expectedPeekToken(lexer.SEMI_COLON) {
 		return nil,
-			errors.New(
-				"expected a semicolon (`;`) at the end of `break` statement, got: " +
+			diagnostic.New(p.peekToken.Start,
+				"expected a semicolon (`;`) at the end of `break` statement, got: "+
 					lexer.TokenKindString(p.peekToken.Kind),
 			)
 	}
@@ -4,8 +4,11 @@ import (
 	"errors"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	"github.com/KhushPatibandha/Kolon/src/environment"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/resolver"
 )
 
 type Parser struct {
@@ -17,7 +20,8 @@ type Parser struct {
 	inLoop     bool
 	inFunction bool
 
-	inTesting bool
+	inTesting   bool
+	interactive bool
 
 	prefixParseFns  map[lexer.TokenKind]prefixParseFn
 	infixParseFns   map[lexer.TokenKind]infixParseFn
@@ -26,6 +30,15 @@ type Parser struct {
 	env          *environment.Environment
 	stack        *environment.Stack
 	currFunction *ast.Function
+	typeParams   []*ktype.Type // type parameters of the generic function being parsed
+
+	file        string                   // path of the file being parsed, imports are relative to it
+	module      string                   // name of the module being parsed, empty for the file being run
+	loader      *Loader                  // shared with the parsers of the modules imported by this file
+	imports     map[string]*loadedModule // modules imported by this file, by name
+	pastImports bool                     // a statement other than `import` was already parsed
+
+	errors []error
 }
 
 func New(tokens []lexer.Token, inTesting bool) *Parser {
@@ -41,22 +54,26 @@ func New(tokens []lexer.Token, inTesting bool) *Parser {
 		postfixParseFns: make(map[lexer.TokenKind]postfixParseFn),
 		env:             environment.NewEnvironment(),
 		stack:           environment.NewStack(),
+		loader:          newLoader(),
+		imports:         make(map[string]*loadedModule),
 	}
 	p.nextToken()
 	p.stack.Push(p.env)
-	environment.LoadBuiltins(p.env)
+	environment.LoadBuiltins(p.env, p.loader.builtins)
 
 	p.addPrefix(lexer.IDENTIFIER, p.parseIdentifier)
 	p.addPrefix(lexer.INT, p.parseInteger)
 	p.addPrefix(lexer.FLOAT, p.parseFloat)
 	p.addPrefix(lexer.BOOL, p.parseBoolean)
 	p.addPrefix(lexer.STRING, p.parseString)
+	p.addPrefix(lexer.INTERP_START, p.parseInterpolatedString)
 	p.addPrefix(lexer.CHAR, p.parseChar)
 	p.addPrefix(lexer.NOT, p.parsePrefix)
 	p.addPrefix(lexer.DASH, p.parsePrefix)
 	p.addPrefix(lexer.OPEN_BRACKET, p.parseGroupedExp)
 	p.addPrefix(lexer.OPEN_SQUARE_BRACKET, p.parseArray)
 	p.addPrefix(lexer.OPEN_CURLY_BRACKET, p.parseHashMap)
+	p.addPrefix(lexer.FUN, p.parseFunctionLiteral)
 	p.addPrefix(lexer.EOF, p.handleEOF)
 
 	p.addInfix(lexer.PLUS, p.parseInfix)
@@ -82,6 +99,7 @@ func New(tokens []lexer.Token, inTesting bool) *Parser {
 	p.addInfix(lexer.SLASH_EQUAL, p.parseAssignment)
 	p.addInfix(lexer.PERCENT_EQUAL, p.parseAssignment)
 	p.addInfix(lexer.OPEN_SQUARE_BRACKET, p.parseIndex)
+	p.addInfix(lexer.DOT, p.parseFieldAccess)
 
 	p.addPostfix(lexer.PLUS_PLUS, p.parsePostfix)
 	p.addPostfix(lexer.MINUS_MINUS, p.parsePostfix)
@@ -89,13 +107,46 @@ func New(tokens []lexer.Token, inTesting bool) *Parser {
 	return p
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Interactive: a parser that is fed one input at a time (repl), keeping its environment between inputs
+// ------------------------------------------------------------------------------------------------------------------
+func NewInteractive() *Parser {
+	p := New([]lexer.Token{lexer.GetNewToken(lexer.EOF, "EOF")}, false)
+	p.interactive = true
+	return p
+}
+
+func (p *Parser) ParseInteractive(tokens []lexer.Token) (*ast.Program, error) {
+	p.tokens = tokens
+	p.tokenPtr = 0
+	p.nextToken()
+	p.nextToken()
+
+	program, err := p.ParseProgram()
+	if err != nil {
+		p.stack = environment.NewStack()
+		p.stack.Push(p.env)
+		p.inLoop = false
+		p.inFunction = false
+		p.currFunction = nil
+		return nil, err
+	}
+	return program, nil
+}
+
 func (p *Parser) ParseProgram() (*ast.Program, error) {
+	p.errors = nil
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 	for !p.currTokenIsOk(lexer.EOF) {
+		if !p.currTokenIsOk(lexer.IMPORT) {
+			p.pastImports = true
+		}
+		state := p.saveState()
 		stmt, err := p.parseStatement()
 		if err != nil {
-			return nil, err
+			p.recover(state, err, true)
+			continue
 		}
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
@@ -103,15 +154,229 @@ func (p *Parser) ParseProgram() (*ast.Program, error) {
 		p.nextToken()
 	}
 
+	if len(p.errors) != 0 {
+		return nil, p.errorList()
+	}
+	if p.interactive {
+		resolver.Resolve(program)
+		return program, nil
+	}
+
 	for _, v := range p.env.FuncNameSpace {
 		if !v.Func.Builtin && v.Func.Function.Body == nil {
-			return nil,
-				errors.New(
-					"function `" + v.Func.Function.Name.Value + "` is declared but not initilized." +
+			p.errors = append(p.errors,
+				diagnostic.New(v.Func.Function.Pos(),
+					"function `"+v.Func.Function.Name.Value+"` is declared but not initilized."+
 						" make sure to write the body for all the declared functions.",
-				)
+				),
+			)
 		}
 	}
+	if len(p.errors) != 0 {
+		return nil, p.errorList()
+	}
 
+	resolver.Resolve(program)
 	return program, nil
 }
+
+// ParseType parses a type written like it's written in a program, eg: `int[]` or `int[string]`. outside a program
+// there are no structs or enums, only the datatypes and what's made of them
+func ParseType(src string) (*ktype.Type, error) {
+	tokens, err := lexer.Tokenizer(src)
+	if err != nil {
+		return nil, err
+	}
+	p := New(tokens, false)
+	p.peekToken = tokens[0]
+	p.tokenPtr = 1
+	t, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if !p.peekTokenIsOk(lexer.EOF) {
+		return nil,
+			diagnostic.New(p.peekToken.Start,
+				"unexpected "+lexer.TokenKindString(p.peekToken.Kind)+" after the type `"+t.String()+"`",
+			)
+	}
+	return t, nil
+}
+
+func (p *Parser) errorList() diagnostic.List {
+	list := diagnostic.List(p.errors)
+	list.Sort()
+	return list
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Error Recovery: after a statement fails, skip to where the next one starts so the rest of the file is still checked
+// ------------------------------------------------------------------------------------------------------------------
+
+// errAbandoned is returned by a body that ran into the next `fun:` (or EOF) while recovering, its error is
+// already recorded, the statements around it just have to give up until the top level is reached
+var errAbandoned = errors.New("statement abandoned after an earlier error")
+
+// errInvalidName is returned when a statement uses a variable or a function whose declaration failed, the
+// declaration's error is the one that gets reported
+var errInvalidName = errors.New("use of a name whose declaration failed")
+
+type parseState struct {
+	tokenPtr     int
+	stackLen     int
+	inLoop       bool
+	inFunction   bool
+	currFunction *ast.Function
+}
+
+func (p *Parser) saveState() parseState {
+	return parseState{
+		tokenPtr:     p.tokenPtr,
+		stackLen:     p.stack.Len(),
+		inLoop:       p.inLoop,
+		inFunction:   p.inFunction,
+		currFunction: p.currFunction,
+	}
+}
+
+// recover records err, puts the parser back into the state it was in before the failed statement and moves
+// currToken to the start of the next statement (or to the `}` closing the current body). it returns false
+// if it had to stop at a `fun:`, `struct:`, `enum:` or EOF instead
+func (p *Parser) recover(state parseState, err error, topLevel bool) bool {
+	p.stack.Stk = p.stack.Stk[:state.stackLen]
+	p.inLoop = state.inLoop
+	p.inFunction = state.inFunction
+	p.currFunction = state.currFunction
+
+	if err == errAbandoned {
+		return false
+	}
+	if !errors.Is(err, errInvalidName) {
+		p.errors = append(p.errors, err)
+	}
+
+	// the bodies of an `if` or a `while` whose condition failed are still checked
+	if start := p.tokens[state.tokenPtr-2].Kind; state.inFunction && (start == lexer.IF || start == lexer.WHILE) {
+		if ok, found := p.recoverBodies(state, start == lexer.WHILE); found {
+			return ok
+		}
+	}
+
+	// account for the brackets of the failed statement that were already consumed
+	braces, brackets := 0, 0
+	for i := state.tokenPtr - 2; i <= p.tokenPtr-2 && i < len(p.tokens); i++ {
+		switch p.tokens[i].Kind {
+		case lexer.OPEN_CURLY_BRACKET:
+			braces++
+		case lexer.CLOSE_CURLY_BRACKET:
+			braces--
+		case lexer.OPEN_BRACKET:
+			brackets++
+		case lexer.CLOSE_BRACKET:
+			brackets--
+		}
+	}
+
+	if braces < 0 {
+		if !topLevel {
+			return true
+		}
+		braces = 0
+	} else if braces == 0 && brackets <= 0 {
+		if p.currTokenIsOk(lexer.SEMI_COLON) ||
+			(p.currTokenIsOk(lexer.CLOSE_CURLY_BRACKET) && !p.peekTokenIsOk(lexer.ELSE) && !p.peekTokenIsOk(lexer.ELSE_IF)) {
+			if p.currTokenIsOk(lexer.CLOSE_CURLY_BRACKET) && p.peekTokenIsOk(lexer.SEMI_COLON) {
+				p.nextToken()
+			}
+			p.nextToken()
+			return !p.atDeclaration()
+		}
+	}
+
+	for {
+		p.nextToken()
+		switch p.currToken.Kind {
+		case lexer.EOF, lexer.FUN, lexer.STRUCT, lexer.ENUM:
+			return false
+		case lexer.OPEN_CURLY_BRACKET:
+			braces++
+		case lexer.OPEN_BRACKET:
+			brackets++
+		case lexer.CLOSE_BRACKET:
+			brackets--
+		case lexer.SEMI_COLON:
+			if braces == 0 && brackets <= 0 {
+				p.nextToken()
+				return !p.atDeclaration()
+			}
+		case lexer.CLOSE_CURLY_BRACKET:
+			if braces == 0 {
+				if topLevel {
+					continue
+				}
+				return true
+			}
+			braces--
+			brackets = 0
+			if braces == 0 && !p.peekTokenIsOk(lexer.ELSE) && !p.peekTokenIsOk(lexer.ELSE_IF) {
+				// the `}` closed a literal (hashmap, struct) in the middle of the statement
+				if p.peekTokenIsOk(lexer.SEMI_COLON) {
+					p.nextToken()
+				}
+				p.nextToken()
+				return !p.atDeclaration()
+			}
+		}
+	}
+}
+
+// recoverBodies parses the body (and the `else if` and `else` bodies after it) of an `if` or a `while` that
+// failed in front of it. found is false if currToken isn't in front of one, ok is what recover returns
+func (p *Parser) recoverBodies(state parseState, loop bool) (ok bool, found bool) {
+	for {
+		// the condition of an `else if` after the failed one is checked like it would have been
+		if p.currTokenIsOk(lexer.ELSE_IF) && p.expectedPeekToken(lexer.COLON) && p.expectedPeekToken(lexer.OPEN_BRACKET) {
+			condition, err := p.parseGroupedExp()
+			if err == nil {
+				err = diagnostic.Wrap(condition.Pos(), typeCheckBoolCon(condition, "else if"))
+			}
+			if err != nil && !errors.Is(err, errInvalidName) {
+				p.errors = append(p.errors, err)
+			}
+		}
+
+		i := p.tokenPtr - 2
+		for ; i < len(p.tokens); i++ {
+			kind := p.tokens[i].Kind
+			if kind == lexer.OPEN_CURLY_BRACKET || kind == lexer.CLOSE_CURLY_BRACKET || kind == lexer.SEMI_COLON ||
+				kind == lexer.FUN || kind == lexer.STRUCT || kind == lexer.ENUM || kind == lexer.EOF {
+				break
+			}
+		}
+		// a literal in the condition stops the search too, it's skipped like any other statement then
+		if i < 2 || i >= len(p.tokens) || p.tokens[i].Kind != lexer.OPEN_CURLY_BRACKET ||
+			p.tokens[i-1].Kind != lexer.COLON ||
+			(p.tokens[i-2].Kind != lexer.CLOSE_BRACKET && p.tokens[i-2].Kind != lexer.ELSE) {
+			return false, found
+		}
+		for p.tokenPtr-2 < i {
+			p.nextToken()
+		}
+		found = true
+
+		p.stack.Push(environment.NewEnclosedEnvironment(p.stack.Top()))
+		p.inLoop = state.inLoop || loop
+		_, err := p.parseBody()
+		p.stack.Stk = p.stack.Stk[:state.stackLen]
+		p.inLoop = state.inLoop
+		if err != nil {
+			return false, true
+		}
+
+		if !p.peekTokenIsOk(lexer.ELSE_IF) && !p.peekTokenIsOk(lexer.ELSE) {
+			p.nextToken()
+			return !p.atDeclaration(), true
+		}
+		p.nextToken()
+	}
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/builtins"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Builtin: The builtins are described like generic functions, a builtin can have more than one signature (eg: `len`
+// of an array, a hashmap or a string), a call is checked against the ones that take as many arguments as it passes
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckBuiltin(b *builtins.Builtin, argTypes []*ktype.Type) (*ktype.TypeCheckResult, error) {
+	var candidates []*builtins.Signature
+	arities := []string{}
+	for _, s := range b.Signatures {
+		if s.Accepts(len(argTypes)) {
+			candidates = append(candidates, s)
+		}
+		arity := strconv.Itoa(len(s.Params))
+		if s.Variadic != nil {
+			arity += " or more"
+		}
+		if len(arities) == 0 || arities[len(arities)-1] != arity {
+			arities = append(arities, arity)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil,
+			errors.New(
+				"wrong number of arguments for `" + b.Name + "`, got: " +
+					strconv.Itoa(len(argTypes)) + ", want: " + joinOr(arities),
+			)
+	}
+
+	var returns []*ktype.Type
+	var err error
+	for _, s := range candidates {
+		typeParams := ktype.TypeParams(append(append([]*ktype.Type{}, s.Params...), s.Variadic))
+		returns, err = typeCheckSignature(b.Name, typeParams, s.ParamsFor(len(argTypes)), s.Returns, argTypes)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil && len(candidates) > 1 {
+		got := []string{}
+		for _, t := range argTypes {
+			got = append(got, t.String())
+		}
+		want := []string{}
+		for _, s := range candidates {
+			want = append(want, "`"+signatureString(b.Name, s)+"`")
+		}
+		err = errors.New(
+			"arguments for `" + b.Name + "` not supported, got: (" + strings.Join(got, ", ") +
+				"), want: " + joinOr(want),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Check != nil {
+		if err := b.Check(argTypes); err != nil {
+			return nil, err
+		}
+	}
+	return &ktype.TypeCheckResult{Types: returns, TypeLen: len(returns)}, nil
+}
+
+func signatureString(name string, s *builtins.Signature) string {
+	params := []string{}
+	for _, t := range s.Params {
+		params = append(params, t.String())
+	}
+	if s.Variadic != nil {
+		params = append(params, "..."+s.Variadic.String())
+	}
+	return name + "(" + strings.Join(params, ", ") + ")"
+}
+
+// joinOr joins items as `a, b or c`
+func joinOr(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
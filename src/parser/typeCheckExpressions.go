@@ -2,10 +2,12 @@ package parser
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	"github.com/KhushPatibandha/Kolon/src/environment"
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 )
@@ -21,15 +23,32 @@ func typeCheckIdent(ident *ast.Identifier,
 	env *environment.Environment,
 ) (*ktype.TypeCheckResult, error) {
 	if sym, ok := env.GetVar(ident.Value); ok {
+		if sym.Type != nil && sym.Type.Kind == ktype.TypeInvalid {
+			return nil, errInvalidName
+		}
 		t := ktype.InternType(sym.Type)
 		return &ktype.TypeCheckResult{Types: []*ktype.Type{t}, TypeLen: 1}, nil
 	}
-	if _, ok := env.GetFunc(ident.Value); ok {
-		return nil,
-			errors.New(
-				"`" + ident.Value + "` is a function, did you mean to " +
-					"call it as `" + ident.Value + "(...)`?",
-			)
+	if sym, ok := env.GetFunc(ident.Value); ok {
+		if sym.Type != nil && sym.Type.Kind == ktype.TypeInvalid {
+			return nil, errInvalidName
+		}
+		if sym.Func.Builtin {
+			return nil,
+				errors.New(
+					"builtin function `" + ident.Value + "` can't be used as a value, " +
+						"did you mean to call it as `" + ident.Value + "(...)`?",
+				)
+		}
+		if sym.Func.Function.TypeParams != nil {
+			return nil,
+				errors.New(
+					"generic function `" + ident.Value + "` can't be used as a value, " +
+						"did you mean to call it as `" + ident.Value + "(...)`?",
+				)
+		}
+		t := sym.Func.Function.FunctionType()
+		return &ktype.TypeCheckResult{Types: []*ktype.Type{t}, TypeLen: 1}, nil
 	}
 	return nil, errors.New("variable `" + ident.Value + "` is undefined/not found")
 }
@@ -78,6 +97,23 @@ func typeCheckString() (*ktype.TypeCheckResult, error) {
 	}, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Interpolated String
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckInterpolatedString(exp *ast.InterpolatedString) (*ktype.TypeCheckResult, error) {
+	for _, e := range exp.Expressions {
+		t := e.GetType()
+		if t.TypeLen != 1 {
+			return nil,
+				diagnostic.New(e.Pos(),
+					"expression `"+e.String()+"` in the string interpolation must result in a single "+
+						"printable value, got: "+strconv.Itoa(t.TypeLen)+" values",
+				)
+		}
+	}
+	return typeCheckString()
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Char
 // ------------------------------------------------------------------------------------------------------------------
@@ -105,12 +141,12 @@ func typeCheckHashMap(exp *ast.HashMap,
 	var keyType *ktype.Type = nil
 	var valueType *ktype.Type = nil
 
-	for k, v := range exp.Pairs {
-		key, err := typeCheckExp(k, env)
+	for _, pair := range exp.Pairs {
+		key, err := typeCheckExp(pair.Key, env)
 		if err != nil {
 			return nil, err
 		}
-		value, err := typeCheckExp(v, env)
+		value, err := typeCheckExp(pair.Value, env)
 		if err != nil {
 			return nil, err
 		}
@@ -304,6 +340,38 @@ func typeCheckInfixWithRightType(exp *ast.Infix,
 	if left.Types[0].Kind == ktype.TypeHashMap || right.Kind == ktype.TypeHashMap {
 		return nil, errors.New("hashmap can't be used with infix operations")
 	}
+	if left.Types[0].Kind == ktype.TypeStruct || right.Kind == ktype.TypeStruct {
+		return nil, errors.New("struct can't be used with infix operations, use `equals` to compare structs")
+	}
+	if left.Types[0].Kind == ktype.TypeFunction || right.Kind == ktype.TypeFunction {
+		return nil, errors.New("function can't be used with infix operations")
+	}
+	if left.Types[0].Kind == ktype.TypeParam || right.Kind == ktype.TypeParam {
+		return nil,
+			errors.New(
+				"values of a generic type can't be used with infix operations, got: `" +
+					left.Types[0].String() + "` and `" + right.String() + "`",
+			)
+	}
+	if left.Types[0].Kind == ktype.TypeEnum || right.Kind == ktype.TypeEnum {
+		if !left.Types[0].Equals(right) {
+			return nil,
+				errors.New(
+					"can only compare enums of same type, got: `" +
+						left.Types[0].String() + "` and `" + right.String() + "`",
+				)
+		}
+		if exp.Operator != "==" && exp.Operator != "!=" {
+			return nil,
+				errors.New(
+					"only `==` and `!=` operators are supported with enums, got: " + exp.Operator,
+				)
+		}
+		return &ktype.TypeCheckResult{
+			Types:   []*ktype.Type{ktype.NewBaseType("bool")},
+			TypeLen: 1,
+		}, nil
+	}
 
 	switch {
 	case left.Types[0].Kind == ktype.TypeArray && right.Kind == ktype.TypeArray:
@@ -473,9 +541,141 @@ func typeCheckPostfix(exp *ast.Postfix,
 					exp.Operator,
 			)
 	}
+	switch exp.Left.(type) {
+	case *ast.Identifier, *ast.FieldAccess, *ast.IndexExpression:
+		if err := typeCheckAssignable(exp.Left, env); err != nil {
+			return nil, err
+		}
+	}
 	return left, nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Struct Literal
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckStructLiteral(exp *ast.StructLiteral,
+	env *environment.Environment,
+) (*ktype.TypeCheckResult, error) {
+	sym, ok := env.GetStruct(exp.Name.Value)
+	if !ok {
+		return nil, errors.New("struct `" + exp.Name.Value + "` is undefined/not found")
+	}
+	for i, field := range sym.Struct.Fields {
+		if exp.Values[i] == nil {
+			return nil,
+				errors.New(
+					"missing field `" + field.FieldName.Value + "` in `" + exp.Name.Value +
+						"` literal, only `int`, `float`, `bool`, `string` and `char` fields can be left out",
+				)
+		}
+		value, err := typeCheckExp(exp.Values[i], env)
+		if err != nil {
+			return nil, err
+		}
+		if value.TypeLen != 1 {
+			return nil,
+				errors.New(
+					"field `" + field.FieldName.Value + "` must be given a single value, got: " +
+						strconv.Itoa(value.TypeLen) +
+						". in case of call expression, it must return a single value",
+				)
+		}
+		if !field.FieldType.Equals(value.Types[0]) {
+			return nil,
+				errors.New(
+					"type mismatch for field `" + field.FieldName.Value + "` in `" + exp.Name.Value +
+						"` literal, expected: `" + field.FieldType.String() +
+						"`, got: `" + value.Types[0].String() + "`",
+				)
+		}
+	}
+	return &ktype.TypeCheckResult{Types: []*ktype.Type{sym.Type}, TypeLen: 1}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Enum Value
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckEnumValue(exp *ast.EnumValue,
+	env *environment.Environment,
+) (*ktype.TypeCheckResult, error) {
+	sym, ok := env.GetEnum(exp.Enum.Value)
+	if !ok {
+		return nil, errors.New("enum `" + exp.Enum.Value + "` is undefined/not found")
+	}
+	variant := sym.Enum.Variants[exp.Index]
+	if len(exp.Args) != len(variant.Types) {
+		return nil,
+			errors.New(
+				"wrong number of values for variant `" + exp.Enum.Value + "." + exp.Variant.Value +
+					"`, got: " + strconv.Itoa(len(exp.Args)) + ", want: " + strconv.Itoa(len(variant.Types)),
+			)
+	}
+	for i, arg := range exp.Args {
+		argType, err := typeCheckExp(arg, env)
+		if err != nil {
+			return nil, err
+		}
+		if argType.TypeLen != 1 {
+			return nil,
+				errors.New(
+					"value of variant `" + exp.Enum.Value + "." + exp.Variant.Value +
+						"` must be a single value, got: " + strconv.Itoa(argType.TypeLen) +
+						". in case of call expression, it must return a single value",
+				)
+		}
+		if !variant.Types[i].Equals(argType.Types[0]) {
+			return nil,
+				errors.New(
+					"type mismatch for value " + strconv.Itoa(i+1) + " of variant `" +
+						exp.Enum.Value + "." + exp.Variant.Value + "`, expected: `" +
+						variant.Types[i].String() + "`, got: `" + argType.Types[0].String() + "`",
+				)
+		}
+	}
+	return &ktype.TypeCheckResult{Types: []*ktype.Type{sym.Type}, TypeLen: 1}, nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Field Access
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckFieldAccess(exp *ast.FieldAccess,
+	env *environment.Environment,
+) (*ktype.TypeCheckResult, error) {
+	left, err := typeCheckExp(exp.Left, env)
+	if err != nil {
+		return nil, err
+	}
+	if left.TypeLen != 1 {
+		return nil,
+			errors.New(
+				"field access can only be applied to a single value, got: " +
+					strconv.Itoa(left.TypeLen) +
+					". in case of call expression, it must return a single value",
+			)
+	}
+	if left.Types[0].Kind != ktype.TypeStruct {
+		return nil,
+			errors.New(
+				"field access (`.`) can only be used with a struct, got: " + left.Types[0].String(),
+			)
+	}
+	sym, ok := env.GetStruct(left.Types[0].Name)
+	if !ok {
+		return nil, errors.New("struct `" + left.Types[0].Name + "` is undefined/not found")
+	}
+	exp.Index = sym.Struct.FieldIndex(exp.Field.Value)
+	if exp.Index == -1 {
+		return nil,
+			errors.New(
+				"struct `" + left.Types[0].Name + "` has no field `" + exp.Field.Value + "`",
+			)
+	}
+	return &ktype.TypeCheckResult{
+		Types:   []*ktype.Type{sym.Struct.Fields[exp.Index].FieldType},
+		TypeLen: 1,
+	}, nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // Assignment
 // ------------------------------------------------------------------------------------------------------------------
@@ -502,7 +702,7 @@ func typeCheckAssignmentWithRightType(exp *ast.Assignment, right *ktype.Type,
 	env *environment.Environment,
 	passInfixExp bool,
 ) (*ktype.TypeCheckResult, error) {
-	left, err := typeCheckIdent(exp.Left, env)
+	left, err := typeCheckExp(exp.Left, env)
 	if err != nil {
 		return nil, err
 	}
@@ -514,13 +714,8 @@ func typeCheckAssignmentWithRightType(exp *ast.Assignment, right *ktype.Type,
 					". in case of call expression, it must return a single value",
 			)
 	}
-	leftSym, _ := env.GetVar(exp.Left.Value)
-	if leftSym.IdentType == environment.CONST {
-		return nil,
-			errors.New(
-				"variable `" + leftSym.Ident.Value +
-					"` is a constant, can't re-assign value to a constant variable",
-			)
+	if err := typeCheckAssignable(exp.Left, env); err != nil {
+		return nil, err
 	}
 
 	switch exp.Operator {
@@ -656,6 +851,9 @@ func typeCheckIndexExp(exp *ast.IndexExpression,
 func typeCheckCallExp(exp *ast.CallExpression,
 	env *environment.Environment,
 ) (*ktype.TypeCheckResult, error) {
+	if exp.Name == nil {
+		return typeCheckCallValue(exp, env)
+	}
 	funcSym, ok := env.GetFunc(exp.Name.Value)
 	if !ok {
 		return nil,
@@ -664,676 +862,187 @@ func typeCheckCallExp(exp *ast.CallExpression,
 					"` not found",
 			)
 	}
-	if funcSym.Func.Builtin {
-		return typeCheckBuiltin(exp, env)
+	if funcSym.Type != nil && funcSym.Type.Kind == ktype.TypeInvalid {
+		return nil, errInvalidName
 	}
-	if len(funcSym.Func.Function.Parameters) != len(exp.Args) {
-		return nil,
-			errors.New(
-				"number of arguments does not match the number of parameters for function `" +
-					funcSym.Ident.Value + "`, got: " + strconv.Itoa(len(exp.Args)) +
-					", expected: " + strconv.Itoa(len(funcSym.Func.Function.Parameters)),
-			)
+	argTypes, err := typeCheckArgs(exp.Args, env)
+	if err != nil {
+		return nil, err
+	}
+	if funcSym.Func.Builtin {
+		return typeCheckBuiltin(funcSym.Func.Native, argTypes)
 	}
 
-	if exp.Args != nil {
-		for i, arg := range exp.Args {
-			argType, err := typeCheckExp(arg, env)
-			if err != nil {
-				return nil, err
-			}
-			if argType.TypeLen != 1 {
-				return nil,
-					errors.New(
-						"argument at position " + strconv.Itoa(i+1) +
-							" must be of a single type, got: " +
-							strconv.Itoa(argType.TypeLen) +
-							". in case of call expression, it must return a single value",
-					)
-			}
-			paramType := funcSym.Func.Function.Parameters[i].ParameterType
-			if !paramType.Equals(argType.Types[0]) {
-				return nil,
-					errors.New(
-						"type mismatch for argument at position " + strconv.Itoa(i+1) +
-							" for function call `" + exp.Name.Value + "`, expected: `" +
-							paramType.String() + "`, got: `" +
-							argType.Types[0].String() + "`",
-					)
-			}
-		}
+	fn := funcSym.Func.Function
+	params := []*ktype.Type{}
+	for _, param := range fn.Parameters {
+		params = append(params, param.ParameterType)
 	}
-	if funcSym.Func.Function.ReturnTypes == nil {
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{},
-			TypeLen: 0,
-		}, nil
+	returns, err := typeCheckSignature(exp.Name.Value, fn.TypeParams, params, fn.ReturnTypes, argTypes)
+	if err != nil {
+		return nil, err
 	}
-	return &ktype.TypeCheckResult{
-		Types:   funcSym.Func.Function.ReturnTypes,
-		TypeLen: len(funcSym.Func.Function.ReturnTypes),
-	}, nil
+	return &ktype.TypeCheckResult{Types: returns, TypeLen: len(returns)}, nil
 }
 
-// ------------------------------------------------------------------------------------------------------------------
-// Builtin
-// ------------------------------------------------------------------------------------------------------------------
-func typeCheckBuiltin(exp *ast.CallExpression,
+// typeCheckCallValue checks a call through a value of function type, eg: `callbacks[0](1)` or `f(1)(2)`
+func typeCheckCallValue(exp *ast.CallExpression,
 	env *environment.Environment,
 ) (*ktype.TypeCheckResult, error) {
+	callee, err := typeCheckExp(exp.Callee, env)
+	if err != nil {
+		return nil, err
+	}
+	if callee.TypeLen != 1 || callee.Types[0].Kind != ktype.TypeFunction {
+		got := "nothing"
+		if callee.TypeLen == 1 {
+			got = callee.Types[0].String()
+		} else if callee.TypeLen > 1 {
+			got = strconv.Itoa(callee.TypeLen) + " values"
+		}
+		return nil, errors.New("only functions can be called, `" + exp.Callee.String() + "` is of type: " + got)
+	}
+	argTypes, err := typeCheckArgs(exp.Args, env)
+	if err != nil {
+		return nil, err
+	}
+	fn := callee.Types[0]
+	returns, err := typeCheckSignature(exp.Callee.String(), nil, fn.ParamTypes, fn.ReturnTypes, argTypes)
+	if err != nil {
+		return nil, err
+	}
+	return &ktype.TypeCheckResult{Types: returns, TypeLen: len(returns)}, nil
+}
+
+func typeCheckArgs(args []ast.Expression, env *environment.Environment) ([]*ktype.Type, error) {
 	var argTypes []*ktype.Type
-	for _, arg := range exp.Args {
-		t, err := typeCheckExp(arg, env)
+	for i, arg := range args {
+		argType, err := typeCheckExp(arg, env)
 		if err != nil {
 			return nil, err
 		}
-		if t.TypeLen != 1 {
+		if argType.TypeLen != 1 {
 			return nil,
 				errors.New(
-					"builtin function arguments must be of a single type, got: " +
-						strconv.Itoa(t.TypeLen) +
+					"argument at position " + strconv.Itoa(i+1) +
+						" must be of a single type, got: " +
+						strconv.Itoa(argType.TypeLen) +
 						". in case of call expression, it must return a single value",
 				)
 		}
-		argTypes = append(argTypes, t.Types[0])
+		argTypes = append(argTypes, argType.Types[0])
 	}
+	return argTypes, nil
+}
 
-	switch exp.Name.Value {
-	case "len":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `len`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray &&
-			argTypes[0].Kind != ktype.TypeHashMap &&
-			(argTypes[0].Kind != ktype.TypeBase || argTypes[0].Name != "string") {
-			return nil,
-				errors.New(
-					"argument for `len` not supported, got: " +
-						argTypes[0].String() + ", want: array, hashmap or `string`",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("int")},
-			TypeLen: 1,
-		}, nil
-	case "toString":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `toString`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("string")},
-			TypeLen: 1,
-		}, nil
-	case "toInt":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `toInt`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		switch argTypes[0].Kind {
-		case ktype.TypeArray:
-			return nil,
-				errors.New(
-					"argument for `toInt` not supported, got: array, " +
-						"want: `int`, `float`, `string`, `char`",
-				)
-		case ktype.TypeHashMap:
-			return nil,
-				errors.New(
-					"argument for `toInt` not supported, " +
-						"got: hashmap, want: `int`, `float`, `string`, `char`",
-				)
-		case ktype.TypeBase:
-			if argTypes[0].Name == "bool" {
-				return nil,
-					errors.New(
-						"argument for `toInt` not supported, got: bool" +
-							", want: `int`, `float`, `string`, `char`",
-					)
-			}
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("int")},
-			TypeLen: 1,
-		}, nil
-	case "toFloat":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `toFloat`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		switch argTypes[0].Kind {
-		case ktype.TypeArray:
-			return nil,
-				errors.New(
-					"argument for `toFloat` not supported, " +
-						"got: array, want: `int` or `float` or `string`",
-				)
-		case ktype.TypeHashMap:
-			return nil,
-				errors.New(
-					"argument for `toFloat` not supported, " +
-						"got: hashmap, want: `int` or `float` or `string`",
-				)
-		case ktype.TypeBase:
-			if argTypes[0].Name == "bool" || argTypes[0].Name == "char" {
-				return nil,
-					errors.New(
-						"argument for `toFloat` not supported, got: " +
-							argTypes[0].String() + ", want: `int` or `float` or `string`",
-					)
-			}
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("float")},
-			TypeLen: 1,
-		}, nil
-	case "print":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `print`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		return &ktype.TypeCheckResult{Types: []*ktype.Type{}, TypeLen: 0}, nil
-	case "println":
-		if exp.Args != nil && len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `println`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 0 or 1",
-				)
-		}
-		return &ktype.TypeCheckResult{Types: []*ktype.Type{}, TypeLen: 0}, nil
-	case "scan", "scanln":
-		fncName := exp.Name.Value
-		if exp.Args != nil && len(exp.Args) != 1 && len(exp.Args) != 2 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `" +
-						fncName + "`, got: " + strconv.Itoa(len(exp.Args)) +
-						", want: 0 or 1 or 2. " + fncName +
-						"() or " + fncName + "(prompt) or " +
-						fncName + "(prompt, newline[true/false])",
-				)
-		}
-		if exp.Args != nil {
-			if argTypes[0].Kind != ktype.TypeBase || argTypes[0].Name != "string" {
-				return nil,
-					errors.New(
-						"argument for `" + fncName +
-							"` not supported, got: " + argTypes[0].String() + ", want: `string`",
-					)
-			}
-			if len(exp.Args) == 2 {
-				if argTypes[1].Kind != ktype.TypeBase || argTypes[1].Name != "bool" {
-					return nil,
-						errors.New(
-							"2nd argument for `" + fncName +
-								"` not supported, got: " + argTypes[1].String() + ", want: `bool`",
-						)
-				}
-			}
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("string")},
-			TypeLen: 1,
-		}, nil
-	case "push":
-		if exp.Args == nil || (len(exp.Args) != 2 && len(exp.Args) != 3) {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `push`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 2 or 3. for array: " +
-						"`push(array, element)` and for hashmap: `push(map, key, value)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray && argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `push`, got: " +
-						argTypes[0].String() + ", want: array or hashmap",
-				)
-		}
-		if argTypes[0].Kind == ktype.TypeArray {
-			if len(exp.Args) != 2 {
-				return nil,
-					errors.New(
-						"wrong number of arguments for `push` for array, got: " +
-							strconv.Itoa(len(exp.Args)) + ", want: 2. `push(array, element)`",
-					)
-			}
-			if !argTypes[0].ElementType.Equals(argTypes[1]) {
-				return nil,
-					errors.New(
-						"argument type mismatch for `push`, expected element to be " +
-							argTypes[0].ElementType.String() + ", got: " + argTypes[1].String(),
-					)
-			}
-		} else {
-			if len(exp.Args) != 3 {
-				return nil,
-					errors.New(
-						"wrong number of arguments for `push` for hashmap, got: " +
-							strconv.Itoa(len(exp.Args)) + ", want: 3. `push(map, key, value)`",
-					)
-			}
-			if !argTypes[0].KeyType.Equals(argTypes[1]) {
-				return nil,
-					errors.New(
-						"key type mismatch for `push`, expected key to be " +
-							argTypes[0].KeyType.String() + ", got: " + argTypes[1].String(),
-					)
-			}
-			if !argTypes[0].ValueType.Equals(argTypes[2]) {
-				return nil,
-					errors.New(
-						"value type mismatch for `push`, expected value to be " +
-							argTypes[0].ValueType.String() + ", got: " + argTypes[2].String(),
-					)
-			}
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{argTypes[0]},
-			TypeLen: 1,
-		}, nil
-	case "pop":
-		if exp.Args == nil || (len(exp.Args) != 1 && len(exp.Args) != 2) {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `pop` for array, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1 or 2. `pop(array)` or `pop(array, index)`",
-				)
+// typeCheckSignature checks the types of the arguments of a call to the function `name` against the types of its
+// parameters, and returns its return types. the type parameters of a generic function are inferred from the
+// arguments and substituted into the return types
+func typeCheckSignature(name string,
+	typeParams []*ktype.Type,
+	params []*ktype.Type,
+	returns []*ktype.Type,
+	argTypes []*ktype.Type,
+) ([]*ktype.Type, error) {
+	if len(params) != len(argTypes) {
+		return nil,
+			errors.New(
+				"number of arguments does not match the number of parameters for function `" +
+					name + "`, got: " + strconv.Itoa(len(argTypes)) +
+					", expected: " + strconv.Itoa(len(params)),
+			)
+	}
+	bindings := ktype.NewBindings(typeParams)
+	for i, param := range params {
+		if !ktype.Unify(param, argTypes[i], bindings) {
+			return nil, argMismatch(name, i, ktype.Substitute(param, bindings), argTypes[i])
 		}
-		if argTypes[0].Kind != ktype.TypeArray {
-			return nil,
-				errors.New(
-					"data structure not supported by `pop`, got: " +
-						argTypes[0].String() + ", want: array",
-				)
+	}
+	// checked again now that the type parameters are known, this also gives the empty array and hashmap
+	// literals passed in their element types
+	for i, param := range params {
+		expected := ktype.Substitute(param, bindings)
+		if !expected.HasTypeParams() && !expected.Equals(argTypes[i]) {
+			return nil, argMismatch(name, i, expected, argTypes[i])
 		}
-		if len(exp.Args) == 2 {
-			if argTypes[1].Kind != ktype.TypeBase || argTypes[1].Name != "int" {
+	}
+
+	out := []*ktype.Type{}
+	for _, ret := range returns {
+		for _, param := range ktype.TypeParams([]*ktype.Type{ret}) {
+			if bound, ok := bindings[param.Name]; ok && bound == nil {
 				return nil,
 					errors.New(
-						"index must be an integer for `pop`, got: " + argTypes[1].String(),
+						"can't infer type parameter `" + param.Name + "` for function call `" + name +
+							"`, the arguments for it are empty arrays or hashmaps",
 					)
 			}
 		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{argTypes[0].ElementType},
-			TypeLen: 1,
-		}, nil
-	case "insert":
-		if exp.Args == nil || len(exp.Args) != 3 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `insert` for array, got: " +
-						strconv.Itoa(len(exp.Args)) +
-						", want: 3. `insert(array, index, element)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray {
-			return nil,
-				errors.New(
-					"data structure not supported by `insert`, got: " +
-						argTypes[0].String() + ", want: array",
-				)
-		}
-		if argTypes[1].Kind != ktype.TypeBase || argTypes[1].Name != "int" {
-			return nil,
-				errors.New(
-					"index must be an `int` for `insert`, got: " + argTypes[1].String(),
-				)
-		}
-		if !argTypes[0].ElementType.Equals(argTypes[2]) {
-			return nil,
-				errors.New(
-					"argument type mismatch for `insert`, expected element to be " +
-						argTypes[0].ElementType.String() + ", got: " + argTypes[2].String(),
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{argTypes[0]},
-			TypeLen: 1,
-		}, nil
-	case "delete":
-		if exp.Args == nil || len(exp.Args) != 2 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `delete`, got: " +
-						strconv.Itoa(len(exp.Args)) +
-						", want: 2. for array: `remove(array, element)`, " +
-						"for hashmap: `remove(map, key)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray && argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `delete`, got: " +
-						argTypes[0].String() + ", want: array or hashmap",
-				)
+		out = append(out, ktype.Substitute(ret, bindings))
+	}
+	return out, nil
+}
+
+func argMismatch(name string, i int, expected *ktype.Type, got *ktype.Type) error {
+	return errors.New(
+		"type mismatch for argument at position " + strconv.Itoa(i+1) +
+			" for function call `" + name + "`, expected: `" +
+			expected.String() + "`, got: `" +
+			got.String() + "`",
+	)
+}
+
+// typeCheckAssignable checks that the left side of an assignment can be assigned to, a variable, or a field of a
+// struct or an element of an array or a hashmap (or any chain of them, eg: `l.points[0].x`) held by a variable
+func typeCheckAssignable(left ast.Expression, env *environment.Environment) error {
+	switch left := left.(type) {
+	case *ast.Identifier:
+		sym, ok := env.GetVar(left.Value)
+		if !ok {
+			return errors.New("function `" + left.Value + "` can't be re-assigned, only variables can")
+		}
+		if sym.IdentType == environment.CONST {
+			return errors.New(
+				"variable `" + sym.Ident.Value +
+					"` is a constant, can't re-assign value to a constant variable",
+			)
 		}
-		if argTypes[0].Kind == ktype.TypeArray {
-			if !argTypes[0].ElementType.Equals(argTypes[1]) {
-				return nil,
-					errors.New(
-						"argument type mismatch for `delete`, expected element to be " +
-							argTypes[0].ElementType.String() + ", got: " + argTypes[1].String(),
-					)
-			}
-			return &ktype.TypeCheckResult{
-				Types:   []*ktype.Type{argTypes[0].ElementType},
-				TypeLen: 1,
-			}, nil
-		} else {
-			if !argTypes[0].KeyType.Equals(argTypes[1]) {
-				return nil,
-					errors.New(
-						"key type mismatch for `delete`, expected key to be " +
-							argTypes[0].KeyType.String() + ", got: " + argTypes[1].String(),
-					)
+		return nil
+	case *ast.FieldAccess, *ast.IndexExpression:
+		what, constant := "fields of a struct", "fields of a constant struct"
+		if idx, ok := left.(*ast.IndexExpression); ok {
+			what, constant = "elements of an array or a hashmap", "elements of a constant array or hashmap"
+			if t := idx.Left.GetType().Types[0]; t.Kind == ktype.TypeBase && t.Name == "string" {
+				return errors.New("can't assign to a character of a string, strings can't be changed in place")
 			}
-			return &ktype.TypeCheckResult{
-				Types:   []*ktype.Type{argTypes[0].ValueType},
-				TypeLen: 1,
-			}, nil
-		}
-	case "remove":
-		if exp.Args == nil || len(exp.Args) != 2 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `remove`, got: " +
-						strconv.Itoa(len(exp.Args)) +
-						", want: 2. for array: `remove(array, element)`, " +
-						"for hashmap: `remove(map, key)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray && argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `remove`, got: " +
-						argTypes[0].String() + ", want: array or hashmap",
-				)
 		}
-		if argTypes[0].Kind == ktype.TypeArray {
-			if !argTypes[0].ElementType.Equals(argTypes[1]) {
-				return nil,
-					errors.New(
-						"argument type mismatch for `remove`, expected element to be " +
-							argTypes[0].ElementType.String() + ", got: " + argTypes[1].String(),
-					)
-			}
-		} else {
-			if !argTypes[0].KeyType.Equals(argTypes[1]) {
-				return nil,
-					errors.New(
-						"key type mismatch for `remove`, expected key to be " +
-							argTypes[0].KeyType.String() + ", got: " + argTypes[1].String(),
-					)
+		root := left
+		for {
+			if access, ok := root.(*ast.FieldAccess); ok {
+				root = access.Left
+			} else if idx, ok := root.(*ast.IndexExpression); ok {
+				root = idx.Left
+			} else {
+				break
 			}
 		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{argTypes[0]},
-			TypeLen: 1,
-		}, nil
-	case "getIndex":
-		if exp.Args == nil || len(exp.Args) != 2 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `getIndex` for array, got: " +
-						strconv.Itoa(len(exp.Args)) +
-						", want: 2. `getIndex(array, element)`",
-				)
+		ident, ok := root.(*ast.Identifier)
+		if !ok {
+			return errors.New("can only assign to " + what + " held by a variable, got: " + root.String())
 		}
-		if argTypes[0].Kind != ktype.TypeArray {
-			return nil,
-				errors.New(
-					"data structure not supported by `getIndex`, got: " +
-						argTypes[0].String() + ", want: array",
-				)
+		sym, ok := env.GetVar(ident.Value)
+		if !ok {
+			return errors.New("can only assign to " + what + " held by a variable, got: " + root.String())
 		}
-		if !argTypes[0].ElementType.Equals(argTypes[1]) {
-			return nil,
-				errors.New(
-					"argument type mismatch for `getIndex`, expected element to be " +
-						argTypes[0].ElementType.String() + ", got: " + argTypes[1].String(),
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("int")},
-			TypeLen: 1,
-		}, nil
-	case "keys":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `keys` for hashmap, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1. `keys(map)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `keys`, got: " +
-						argTypes[0].String() + ", want: hashmap",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewArrayType(argTypes[0].KeyType)},
-			TypeLen: 1,
-		}, nil
-	case "values":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `values` for hashmap, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1. `values(map)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `values`, got: " +
-						argTypes[0].String() + ", want: hashmap",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewArrayType(argTypes[0].ValueType)},
-			TypeLen: 1,
-		}, nil
-	case "containsKey":
-		if exp.Args == nil || len(exp.Args) != 2 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `containsKey` for hashmap, got: " +
-						strconv.Itoa(len(exp.Args)) +
-						", want: 2. `containsKey(map, key)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `containsKey`, got: " +
-						argTypes[0].String() + ", want: hashmap",
-				)
-		}
-		if !argTypes[0].KeyType.Equals(argTypes[1]) {
-			return nil,
-				errors.New(
-					"key type mismatch for `containsKey`, expected key to be " +
-						argTypes[0].KeyType.String() + ", got: " + argTypes[1].String(),
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("bool")},
-			TypeLen: 1,
-		}, nil
-	case "typeOf":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `typeOf`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("string")},
-			TypeLen: 1,
-		}, nil
-	case "slice":
-		if exp.Args == nil || (len(exp.Args) != 3 && len(exp.Args) != 4) {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `slice`, got: " +
-						strconv.Itoa(len(exp.Args)) +
-						", want: 3 or 4. `slice(array/string, " +
-						"start, end)` or `slice(array/string, start, end, step)`",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray &&
-			(argTypes[0].Kind != ktype.TypeBase || argTypes[0].Name != "string") {
-			return nil,
-				errors.New(
-					"data structure not supported by `slice`, got: " +
-						argTypes[0].String() + ", want: array or string",
-				)
-		}
-		if argTypes[1].Kind != ktype.TypeBase || argTypes[1].Name != "int" {
-			return nil,
-				errors.New(
-					"start index must be an `int` for `slice`, got: " +
-						argTypes[1].String(),
-				)
-		}
-		if argTypes[2].Kind != ktype.TypeBase || argTypes[2].Name != "int" {
-			return nil,
-				errors.New(
-					"end index must be an `int` for `slice`, got: " +
-						argTypes[2].String(),
-				)
-		}
-		if len(exp.Args) == 4 {
-			if argTypes[3].Kind != ktype.TypeBase || argTypes[3].Name != "int" {
-				return nil,
-					errors.New(
-						"step must be an `int` for `slice`, got: " +
-							argTypes[3].String(),
-					)
-			}
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{argTypes[0]},
-			TypeLen: 1,
-		}, nil
-	case "copy":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `copy`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeArray &&
-			argTypes[0].Kind != ktype.TypeHashMap {
-			return nil,
-				errors.New(
-					"data structure not supported by `copy`, got: " +
-						argTypes[0].String() + ", want: array or hashmap",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{argTypes[0]},
-			TypeLen: 1,
-		}, nil
-	case "equals":
-		if exp.Args == nil || len(exp.Args) != 2 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `equals`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 2",
-				)
-		}
-		if !argTypes[0].Equals(argTypes[1]) {
-			return nil,
-				errors.New(
-					"type mismatch for arguments of `equals`, got: `" +
-						argTypes[0].String() + "` and `" +
-						argTypes[1].String() + "`",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("bool")},
-			TypeLen: 1,
-		}, nil
-	case "ceil", "floor":
-		if exp.Args == nil || len(exp.Args) != 1 {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `" + exp.Name.Value + "`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeBase ||
-			argTypes[0].Name != "float" {
-			return nil,
-				errors.New(
-					"type mismatch for argument of `" + exp.Name.Value + "`, got: `" +
-						argTypes[0].String() + "`, want: `float`",
-				)
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("float")},
-			TypeLen: 1,
-		}, nil
-	case "round":
-		if exp.Args == nil || (len(exp.Args) != 1 && len(exp.Args) != 2) {
-			return nil,
-				errors.New(
-					"wrong number of arguments for `round`, got: " +
-						strconv.Itoa(len(exp.Args)) + ", want: 1 or 2",
-				)
-		}
-		if argTypes[0].Kind != ktype.TypeBase ||
-			argTypes[0].Name != "float" {
-			return nil,
-				errors.New(
-					"type mismatch for 1st argument of `round`, got: `" +
-						argTypes[0].String() + "`, want: `float`",
-				)
-		}
-		if len(exp.Args) == 2 {
-			if argTypes[1].Kind != ktype.TypeBase ||
-				argTypes[1].Name != "int" {
-				return nil,
-					errors.New(
-						"type mismatch for 2nd argument of `round`, got: `" +
-							argTypes[1].String() + "`, want: `int`",
-					)
-			}
-		}
-		return &ktype.TypeCheckResult{
-			Types:   []*ktype.Type{ktype.NewBaseType("float")},
-			TypeLen: 1,
-		}, nil
-	default:
-		return nil,
-			errors.New(
-				"unknown builtin function `" +
-					exp.Name.Value + "`",
+		if sym.IdentType == environment.CONST {
+			return errors.New(
+				"variable `" + sym.Ident.Value + "` is a constant, can't re-assign " + constant,
 			)
+		}
+		return nil
 	}
+	return errors.New(
+		"left side in an assignment operation must be an identifier, a struct field or an index expression, got: " +
+			fmt.Sprintf("%T", left),
+	)
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
 	"github.com/KhushPatibandha/Kolon/src/environment"
@@ -19,7 +20,7 @@ import (
 // Function
 // ------------------------------------------------------------------------------------------------------------------
 func typeCheckFunction(f *ast.Function) error {
-	if f.Name.Value == "main" && f.Parameters != nil && f.ReturnTypes != nil {
+	if f.Name != nil && f.Name.Value == "main" && f.Parameters != nil && f.ReturnTypes != nil {
 		return errors.New("`main` function must not take in any parameters and " +
 			"must not return anything, since it is the starting point of the program")
 	}
@@ -27,7 +28,7 @@ func typeCheckFunction(f *ast.Function) error {
 	if f.ReturnTypes != nil {
 		err := checkReturnAtTheEnd(f.Body.Statements)
 		if err != nil {
-			return errors.New("function `" + f.Name.Value + err.Error())
+			return errors.New(funcName(f) + err.Error())
 		}
 	}
 
@@ -48,6 +49,19 @@ func typeCheckVarAndConst(stmt *ast.VarAndConst, env *environment.Environment) e
 			return errors.New(
 				"hashmap `" + stmt.Name.Value +
 					"` must always be initialized while declaring, for empty hashmap use `{}`")
+		case ktype.TypeStruct:
+			return errors.New(
+				"struct `" + stmt.Name.Value + "` must always be initialized while declaring")
+		case ktype.TypeEnum:
+			return errors.New(
+				"enum `" + stmt.Name.Value + "` must always be initialized while declaring")
+		case ktype.TypeFunction:
+			return errors.New(
+				"function variable `" + stmt.Name.Value + "` must always be initialized while declaring")
+		case ktype.TypeParam:
+			return errors.New(
+				"variable `" + stmt.Name.Value + "` of generic type `" + stmt.Type.String() +
+					"` must always be initialized while declaring")
 		default:
 			if stmt.Token.Kind == lexer.CONST {
 				return errors.New(
@@ -193,12 +207,10 @@ func typeCheckReturn(stmt *ast.Return, fun *ast.Function) error {
 		return nil
 	}
 	if stmt.Value == nil && fun.ReturnTypes != nil {
-		return errors.New("not enough return values for function `" +
-			fun.Name.Value + "`")
+		return errors.New("not enough return values for " + funcName(fun))
 	}
 	if stmt.Value != nil && fun.ReturnTypes == nil {
-		return errors.New("too many return values for function `" +
-			fun.Name.Value + "`, expected none")
+		return errors.New("too many return values for " + funcName(fun) + ", expected none")
 	}
 	if len(stmt.Value) != len(fun.ReturnTypes) {
 		return errors.New(
@@ -227,6 +239,111 @@ func typeCheckReturn(stmt *ast.Return, fun *ast.Function) error {
 	return nil
 }
 
+// ------------------------------------------------------------------------------------------------------------------
+// Match
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckMatchValue(value ast.Expression, env *environment.Environment) (*ast.Enum, error) {
+	t, err := typeCheckExp(value, env)
+	if err != nil {
+		return nil, err
+	}
+	if t.TypeLen != 1 {
+		return nil,
+			errors.New(
+				"value for `match` statement must be a single enum value, got: " +
+					strconv.Itoa(t.TypeLen) + " values",
+			)
+	}
+	if t.Types[0].Kind != ktype.TypeEnum {
+		return nil,
+			errors.New(
+				"value for `match` statement must be an enum, got: " + t.Types[0].String(),
+			)
+	}
+	sym, ok := env.GetEnum(t.Types[0].Name)
+	if !ok {
+		return nil, errors.New("enum `" + t.Types[0].Name + "` is undefined/not found")
+	}
+	return sym.Enum, nil
+}
+
+// typeCheckMatch makes sure every variant of the enum is handled, by its own arm or by the `else` arm
+func typeCheckMatch(stmt *ast.Match, enum *ast.Enum) error {
+	if stmt.Alternate != nil {
+		if len(stmt.Arms) == len(enum.Variants) {
+			return errors.New(
+				"`else` arm in `match` statement is unreachable, " +
+					"all variants of enum `" + enum.Name.Value + "` are already handled",
+			)
+		}
+		return nil
+	}
+	handled := make([]bool, len(enum.Variants))
+	for _, arm := range stmt.Arms {
+		handled[arm.Index] = true
+	}
+	missing := []string{}
+	for i, variant := range enum.Variants {
+		if !handled[i] {
+			missing = append(missing, "`"+variant.Name.Value+"`")
+		}
+	}
+	if len(missing) != 0 {
+		return errors.New(
+			"`match` statement on enum `" + enum.Name.Value + "` doesn't handle the variant(s): " +
+				strings.Join(missing, ", ") + ". add an arm for each of them or an `else` arm",
+		)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// ForEach
+// ------------------------------------------------------------------------------------------------------------------
+func typeCheckForEach(stmt *ast.ForEach, env *environment.Environment) error {
+	res := stmt.Iterable.GetType()
+	if res.TypeLen != 1 {
+		return errors.New(
+			"expected a single value to loop over in `for-each` loop, got: " + strconv.Itoa(res.TypeLen),
+		)
+	}
+	t := res.Types[0]
+
+	// with a single variable it gets the elements of an array (or string) and the keys of a hashmap, with two the
+	// first one gets the index (or key) and the second one the element (or value)
+	var types []*ktype.Type
+	intType := ktype.NewBaseType("int")
+	switch {
+	case t.Kind == ktype.TypeArray:
+		types = []*ktype.Type{intType, t.ElementType}
+	case t.Kind == ktype.TypeBase && t.Name == "string":
+		types = []*ktype.Type{intType, ktype.NewBaseType("char")}
+	case t.Kind == ktype.TypeHashMap:
+		types = []*ktype.Type{t.KeyType, t.ValueType}
+		if len(stmt.Vars) == 1 {
+			types = types[:1]
+		}
+	default:
+		return errors.New(
+			"can only loop over an array, a hashmap or a string in `for-each` loop, got: " + t.String(),
+		)
+	}
+	types = types[len(types)-len(stmt.Vars):]
+
+	for i, v := range stmt.Vars {
+		if !v.Type.Equals(types[i]) {
+			return errors.New(
+				"type mismatch in `for-each` loop variable `" + v.Name.Value + "`, expected: " +
+					types[i].String() + ", got: " + v.Type.String(),
+			)
+		}
+		if err := typeCheckVarAndConstWithRightType(v, types[i], env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ------------------------------------------------------------------------------------------------------------------
 // ForLoop
 // ------------------------------------------------------------------------------------------------------------------
@@ -257,7 +374,14 @@ func typeCheckForLoop(stmt *ast.ForLoop, env *environment.Environment) error {
 							assign.Operator,
 					)
 				}
-				sym, _ = env.GetVar(assign.Left.Value)
+				ident, ok := assign.Left.(*ast.Identifier)
+				if !ok {
+					return errors.New(
+						"expected a variable on the left side of the assignment " +
+							"in `for loop` statement, got: " + assign.Left.String(),
+					)
+				}
+				sym, _ = env.GetVar(ident.Value)
 			}
 		}
 	} else {
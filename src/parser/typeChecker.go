@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
 	"github.com/KhushPatibandha/Kolon/src/environment"
 	ktype "github.com/KhushPatibandha/Kolon/src/kType"
 )
@@ -16,36 +17,53 @@ import (
 // Expressions
 // ------------------------------------------------------------------------------------------------------------------
 func typeCheckExp(exp ast.Expression, env *environment.Environment) (*ktype.TypeCheckResult, error) {
+	var res *ktype.TypeCheckResult
+	var err error
+
 	switch exp := exp.(type) {
 	case *ast.Identifier:
-		return typeCheckIdent(exp, env)
+		res, err = typeCheckIdent(exp, env)
 	case *ast.Integer:
-		return typeCheckInteger()
+		res, err = typeCheckInteger()
 	case *ast.Float:
-		return typeCheckFloat()
+		res, err = typeCheckFloat()
 	case *ast.String:
-		return typeCheckString()
+		res, err = typeCheckString()
+	case *ast.InterpolatedString:
+		res, err = typeCheckInterpolatedString(exp)
 	case *ast.Char:
-		return typeCheckChar()
+		res, err = typeCheckChar()
 	case *ast.Bool:
-		return typeCheckBool()
+		res, err = typeCheckBool()
 	case *ast.HashMap:
-		return typeCheckHashMap(exp, env)
+		res, err = typeCheckHashMap(exp, env)
 	case *ast.Array:
-		return typeCheckArray(exp, env)
+		res, err = typeCheckArray(exp, env)
 	case *ast.Prefix:
-		return typeCheckPrefix(exp, env)
+		res, err = typeCheckPrefix(exp, env)
 	case *ast.Postfix:
-		return typeCheckPostfix(exp, env)
+		res, err = typeCheckPostfix(exp, env)
 	case *ast.Infix:
-		return typeCheckInfix(exp, env)
+		res, err = typeCheckInfix(exp, env)
 	case *ast.Assignment:
-		return typeCheckAssignment(exp, env)
+		res, err = typeCheckAssignment(exp, env)
 	case *ast.IndexExpression:
-		return typeCheckIndexExp(exp, env)
+		res, err = typeCheckIndexExp(exp, env)
 	case *ast.CallExpression:
-		return typeCheckCallExp(exp, env)
+		res, err = typeCheckCallExp(exp, env)
+	case *ast.StructLiteral:
+		res, err = typeCheckStructLiteral(exp, env)
+	case *ast.FieldAccess:
+		res, err = typeCheckFieldAccess(exp, env)
+	case *ast.EnumValue:
+		res, err = typeCheckEnumValue(exp, env)
+	case *ast.FunctionLiteral:
+		res, err = exp.GetType(), nil
 	default:
 		return nil, fmt.Errorf("unknown expression type, got: %T", exp)
 	}
+	if err != nil {
+		return nil, diagnostic.Wrap(exp.Pos(), err)
+	}
+	return res, nil
 }
@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/diagnostic"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+	"github.com/KhushPatibandha/Kolon/src/parser"
+)
+
+const (
+	PROMPT          = ">> "
+	CONTINUE_PROMPT = ".. "
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Repl
+// ------------------------------------------------------------------------------------------------------------------
+func Start(in io.Reader, out io.Writer) {
+	scanner := bufio.NewScanner(in)
+	p := parser.NewInteractive()
+	e := evaluator.New(false)
+
+	var input strings.Builder
+	fmt.Fprint(out, PROMPT)
+
+	for scanner.Scan() {
+		input.WriteString(scanner.Text())
+		input.WriteString("\n")
+
+		tokens, err := lexer.Tokenizer(input.String())
+		if (err == nil && isIncomplete(tokens)) || inRawString(err) {
+			fmt.Fprint(out, CONTINUE_PROMPT)
+			continue
+		}
+		source := input.String()
+		input.Reset()
+
+		if err != nil {
+			fmt.Fprintln(out, "Error parsing input:", err)
+		} else if strings.TrimSpace(source) != "" {
+			run(p, e, tokens, out)
+		}
+		fmt.Fprint(out, PROMPT)
+	}
+	fmt.Fprintln(out)
+}
+
+func run(p *parser.Parser, e *evaluator.Evaluator, tokens []lexer.Token, out io.Writer) {
+	program, err := p.ParseInteractive(tokens)
+	if err != nil {
+		for _, err := range diagnostic.Errors(err) {
+			fmt.Fprintln(out, "Error parsing input:", err)
+		}
+		return
+	}
+
+	for _, stmt := range program.Statements {
+		val, err := e.EvaluateInteractive(stmt)
+		if err != nil {
+			fmt.Fprintln(out, "Error evaluating input:", err)
+			return
+		}
+
+		var exp ast.Expression
+		switch stmt := stmt.(type) {
+		case *ast.BareExpression:
+			exp = stmt.Expression
+		case *ast.ExpressionStatement:
+			if call, ok := stmt.Expression.(*ast.CallExpression); ok {
+				exp = call
+			}
+		}
+		if exp == nil || val == nil {
+			continue
+		}
+		fmt.Fprintln(out, echo(val, exp))
+	}
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Helper Methods
+// ------------------------------------------------------------------------------------------------------------------
+// inRawString reports whether the input ended inside a raw string, which can span multiple lines
+func inRawString(err error) bool {
+	lexErr, ok := err.(*lexer.LexError)
+	return ok && lexErr.Text == "`"
+}
+
+func isIncomplete(tokens []lexer.Token) bool {
+	depth := 0
+	for _, token := range tokens {
+		switch token.Kind {
+		case lexer.OPEN_BRACKET, lexer.OPEN_CURLY_BRACKET, lexer.OPEN_SQUARE_BRACKET:
+			depth++
+		case lexer.CLOSE_BRACKET, lexer.CLOSE_CURLY_BRACKET, lexer.CLOSE_SQUARE_BRACKET:
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+func echo(val object.Object, exp ast.Expression) string {
+	t := exp.GetType()
+	if t.TypeLen == 1 {
+		return object.Display(val) + " (" + t.Types[0].String() + ")"
+	}
+
+	values := []string{}
+	types := []string{}
+	if arr, ok := val.(*object.Array); ok {
+		for _, ele := range arr.Elements {
+			values = append(values, object.Display(ele))
+		}
+	}
+	for _, typ := range t.Types {
+		types = append(types, typ.String())
+	}
+	return "(" + strings.Join(values, ", ") + ") (" + strings.Join(types, ", ") + ")"
+}
@@ -0,0 +1,216 @@
+package resolver
+
+import (
+	"github.com/KhushPatibandha/Kolon/src/ast"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// Resolver: goes over a checked program once and gives each variable a slot in the scope declaring it, every use
+// of a variable gets how many scopes out it was declared and its slot. the scopes are the ones the evaluator
+// creates, so it can keep the variables of a scope in an array instead of looking them up by name
+// ------------------------------------------------------------------------------------------------------------------
+type Resolver struct {
+	scopes []map[string]int // name to slot, the innermost scope is last. the top level isn't one of them
+}
+
+// Resolve annotates the identifiers of program, the globals and the functions are left to be looked up by name
+func Resolve(program *ast.Program) {
+	r := &Resolver{}
+	for _, stmt := range program.Statements {
+		r.statement(stmt)
+	}
+}
+
+func (r *Resolver) push() { r.scopes = append(r.scopes, map[string]int{}) }
+func (r *Resolver) pop()  { r.scopes = r.scopes[:len(r.scopes)-1] }
+
+// declare gives name the next slot of the innermost scope, a name declared again in the same scope keeps its slot
+func (r *Resolver) declare(name *ast.Identifier) {
+	if len(r.scopes) == 0 {
+		name.Depth, name.Index = ast.Global, 0
+		return
+	}
+	scope := r.scopes[len(r.scopes)-1]
+	idx, ok := scope[name.Value]
+	if !ok {
+		idx = len(scope)
+		scope[name.Value] = idx
+	}
+	name.Depth, name.Index = 0, idx
+}
+
+func (r *Resolver) resolve(name *ast.Identifier) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if idx, ok := r.scopes[i][name.Value]; ok {
+			name.Depth, name.Index = len(r.scopes)-1-i, idx
+			return
+		}
+	}
+	name.Depth, name.Index = ast.Global, 0
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Statements
+// ------------------------------------------------------------------------------------------------------------------
+func (r *Resolver) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.ExpressionStatement:
+		r.expression(stmt.Expression)
+	case *ast.BareExpression:
+		r.expression(stmt.Expression)
+	case *ast.VarAndConst:
+		if stmt.Value != nil {
+			r.expression(stmt.Value)
+		}
+		r.declare(stmt.Name)
+	case *ast.MultiAssignment:
+		r.multiAssign(stmt)
+	case *ast.Return:
+		r.expressions(stmt.Value)
+	case *ast.Function:
+		r.function(stmt)
+	case *ast.If:
+		r.expression(stmt.Condition)
+		r.body(stmt.Body)
+		for _, ei := range stmt.MultiConditionals {
+			r.expression(ei.Condition)
+			r.body(ei.Body)
+		}
+		if stmt.Alternate != nil {
+			r.body(stmt.Alternate.Body)
+		}
+	case *ast.Match:
+		r.expression(stmt.Value)
+		for _, arm := range stmt.Arms {
+			r.push()
+			for _, binding := range arm.Bindings {
+				r.declare(binding)
+			}
+			r.statements(arm.Body)
+			r.pop()
+		}
+		if stmt.Alternate != nil {
+			r.body(stmt.Alternate.Body)
+		}
+	case *ast.ForLoop:
+		r.push()
+		r.statement(stmt.Left)
+		r.expression(stmt.Middle)
+		r.body(stmt.Body)
+		r.expression(stmt.Right)
+		r.pop()
+	case *ast.ForEach:
+		r.expression(stmt.Iterable)
+		r.push()
+		for _, v := range stmt.Vars {
+			r.declare(v.Name)
+		}
+		r.statements(stmt.Body)
+		r.pop()
+	case *ast.WhileLoop:
+		r.expression(stmt.Condition)
+		r.body(stmt.Body)
+	}
+}
+
+// multiAssign resolves the call giving all the values first when there is one, like the evaluator runs it
+func (r *Resolver) multiAssign(ma *ast.MultiAssignment) {
+	if !ma.SingleFunctionCall {
+		for _, obj := range ma.Objects {
+			r.statement(obj)
+		}
+		return
+	}
+
+	switch v := ma.Objects[0].(type) {
+	case *ast.VarAndConst:
+		r.expression(v.Value)
+	case *ast.ExpressionStatement:
+		r.expression(v.Expression.(*ast.Assignment).Right)
+	}
+	for _, obj := range ma.Objects {
+		switch obj := obj.(type) {
+		case *ast.VarAndConst:
+			r.declare(obj.Name)
+		case *ast.ExpressionStatement:
+			r.expression(obj.Expression.(*ast.Assignment).Left)
+		}
+	}
+}
+
+// function resolves the body of f in a scope of its own, its parameters take the first slots
+func (r *Resolver) function(f *ast.Function) {
+	if f.Body == nil {
+		return
+	}
+	r.push()
+	for _, param := range f.Parameters {
+		r.declare(param.ParameterName)
+	}
+	r.statements(f.Body)
+	r.pop()
+}
+
+// body resolves b in a new scope
+func (r *Resolver) body(b *ast.Body) {
+	r.push()
+	r.statements(b)
+	r.pop()
+}
+
+func (r *Resolver) statements(b *ast.Body) {
+	for _, stmt := range b.Statements {
+		r.statement(stmt)
+	}
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Expressions
+// ------------------------------------------------------------------------------------------------------------------
+func (r *Resolver) expression(e ast.Expression) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		r.resolve(e)
+	case *ast.InterpolatedString:
+		r.expressions(e.Expressions)
+	case *ast.HashMap:
+		for _, p := range e.Pairs {
+			r.expression(p.Key)
+			r.expression(p.Value)
+		}
+	case *ast.Array:
+		r.expressions(e.Values)
+	case *ast.StructLiteral:
+		r.expressions(e.Values)
+	case *ast.EnumValue:
+		r.expressions(e.Args)
+	case *ast.FieldAccess:
+		r.expression(e.Left)
+	case *ast.FunctionLiteral:
+		r.function(e.Function)
+	case *ast.Prefix:
+		r.expression(e.Right)
+	case *ast.Infix:
+		r.expression(e.Left)
+		r.expression(e.Right)
+	case *ast.Postfix:
+		r.expression(e.Left)
+	case *ast.Assignment:
+		r.expression(e.Left)
+		r.expression(e.Right)
+	case *ast.IndexExpression:
+		r.expression(e.Left)
+		r.expression(e.Index)
+	case *ast.CallExpression:
+		if e.Name == nil {
+			r.expression(e.Callee)
+		}
+		r.expressions(e.Args)
+	}
+}
+
+func (r *Resolver) expressions(es []ast.Expression) {
+	for _, e := range es {
+		r.expression(e)
+	}
+}
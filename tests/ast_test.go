@@ -1714,20 +1714,26 @@ func Test19(t *testing.T) {
 						KeyType:     nil,
 						ValueType:   nil,
 					},
-					Pairs: map[ast.BaseType]ast.Expression{
-						&ast.Integer{
-							Token: lexer.Token{Kind: lexer.INT, Value: "1"},
-							Value: 1,
-						}: &ast.Bool{
-							Token: lexer.Token{Kind: lexer.BOOL, Value: "true"},
-							Value: true,
+					Pairs: []*ast.HashMapPair{
+						{
+							Key: &ast.Integer{
+								Token: lexer.Token{Kind: lexer.INT, Value: "1"},
+								Value: 1,
+							},
+							Value: &ast.Bool{
+								Token: lexer.Token{Kind: lexer.BOOL, Value: "true"},
+								Value: true,
+							},
 						},
-						&ast.Integer{
-							Token: lexer.Token{Kind: lexer.INT, Value: "2"},
-							Value: 2,
-						}: &ast.Bool{
-							Token: lexer.Token{Kind: lexer.BOOL, Value: "false"},
-							Value: false,
+						{
+							Key: &ast.Integer{
+								Token: lexer.Token{Kind: lexer.INT, Value: "2"},
+								Value: 2,
+							},
+							Value: &ast.Bool{
+								Token: lexer.Token{Kind: lexer.BOOL, Value: "false"},
+								Value: false,
+							},
 						},
 					},
 				},
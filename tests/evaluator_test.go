@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+	"github.com/KhushPatibandha/Kolon/src/parser"
+)
+
+// loops runs n iterations of each kind of loop, leaving them and the functions they call every way there is
+func loops(n int) string {
+	return "enum: E {A(int); B;}" +
+		"fun: f(i: int): (int) {while: (true): {if: (i % 2 == 0): {return: i;} break;} return: -i;}" +
+		"fun: g(e: E): (int) {match: (e): {A(x): {return: x;} B: {return: 0;}}}" +
+		"var total: int = 0; var n: int = " + strconv.Itoa(n) + ";" +
+		"for: (var i: int = 0; i < n; i++): {if: (i % 3 == 0): {continue;} total += f(i) + g(E.A(i));}" +
+		"var j: int = 0; while: (j < n): {j++; if: (j == n): {break;} var e: E = E.B; total += g(e);}" +
+		"var a: int[] = [1, 2, 3]; for: (var k: int in a): {for: (var m: int = 0; m < n / 3; m++): {total += k;}}"
+}
+
+// evalLoops evaluates loops(n), returning the evaluator and the heap in use once it's done
+func evalLoops(t *testing.T, n int) (*evaluator.Evaluator, uint64) {
+	tokens, err := lexer.Tokenizer(loops(n))
+	assert.NoError(t, err)
+	program, err := parser.New(tokens, true).ParseProgram()
+	ktype.ResetTypePool()
+	if !assert.NoError(t, err) {
+		return nil, 0
+	}
+	e := evaluator.New(true)
+	_, err = e.Evaluate(program)
+	assert.NoError(t, err)
+
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return e, stats.HeapAlloc
+}
+
+func Test47(t *testing.T) {
+	small, before := evalLoops(t, 100)
+	if small != nil {
+		assert.Equal(t, 1, small.Depth())
+	}
+	large, after := evalLoops(t, 300000)
+	if large != nil {
+		assert.Equal(t, 1, large.Depth())
+	}
+	// a scope left on the stack in each iteration would keep about a million of them alive
+	assert.True(t, int64(after)-int64(before) < 4<<20, "heap grew from %d to %d bytes", before, after)
+	runtime.KeepAlive(small)
+	runtime.KeepAlive(large)
+
+	// an error deep inside loops and calls still takes all their scopes off the stack
+	src := "fun: h(a: int[], i: int): (int) {for: (var k: int = 0; k < 1; k++): {return: a[i];} return: 0;}" +
+		"var a: int[] = [1]; var i: int = 0; while: (true): {if: (true): {h(a, i); i++;}}"
+	tokens, err := lexer.Tokenizer(src)
+	assert.NoError(t, err)
+	program, err := parser.New(tokens, true).ParseProgram()
+	ktype.ResetTypePool()
+	if assert.NoError(t, err) {
+		e := evaluator.New(true)
+		_, err = e.Evaluate(program)
+		assert.Error(t, err)
+		assert.Equal(t, 1, e.Depth())
+	}
+}
+
+func Test57(t *testing.T) {
+	// the elements are taken when the loop starts, what the body does to the array doesn't change them
+	arr := &object.Array{Elements: []object.Object{&object.Integer{Value: 10}, &object.Integer{Value: 20}}}
+	it := evaluator.NewForEachIter(arr)
+	arr.Elements[1] = &object.Integer{Value: 99}
+	arr.Elements = append(arr.Elements, &object.Integer{Value: 30})
+	got := []string{}
+	vars := make([]object.Object, 2)
+	for it.Next(vars) {
+		got = append(got, object.Display(vars[0])+":"+object.Display(vars[1]))
+	}
+	assert.Equal(t, []string{"0:10", "1:20"}, got)
+
+	// an index counts chars, not bytes
+	it = evaluator.NewForEachIter(&object.String{Value: "hé!"})
+	got = []string{}
+	for it.Next(vars) {
+		got = append(got, object.Display(vars[0])+":"+object.Display(vars[1]))
+	}
+	assert.Equal(t, []string{"0:'h'", "1:'é'", "2:'!'"}, got)
+
+	// a single variable gets the keys of a hashmap
+	m := object.NewHashMap()
+	m.Set(&object.String{Value: "a"}, &object.Integer{Value: 1})
+	m.Set(&object.String{Value: "b"}, &object.Integer{Value: 2})
+	it = evaluator.NewForEachIter(m)
+	got = []string{}
+	key := make([]object.Object, 1)
+	for it.Next(key) {
+		got = append(got, object.Display(key[0]))
+	}
+	assert.Equal(t, []string{`"a"`, `"b"`}, got)
+}
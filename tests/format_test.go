@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KhushPatibandha/Kolon/src/format"
+)
+
+func Test52(t *testing.T) {
+	src := "// shapes\n" +
+		"struct: Point { x: int; y: int; }\n" +
+		"enum: Shape { Circle(float); Empty; }\n\n\n" +
+		"fun: first<T>( a : T[] ) : (T) { return : a[0]; }\n" +
+		"fun: area(s: Shape): (float) {\n" +
+		"match: (s): { Circle(r): { return: 3.0*r*r; }\n" +
+		"else: {} }\n" +
+		"}\n" +
+		"fun: main() {\n" +
+		"\tvar p: Point = Point{ x: 1, y: -2 }; // a point\n" +
+		"\tif:(p.x>0&&!false):{println( \"x: ${ p.x+1 }\" );}\n" +
+		"\telse if: (true): {\n\n" +
+		"\t\t// nothing\n" +
+		"\t}\n" +
+		"    else: { p.y = - -p.y; }\n" +
+		"  var xs: int[] = [1,\n" +
+		"2,\n" +
+		"3];\n" +
+		"println(apply(xs, fun(n: int): (int) { return: n*2; }));\n" +
+		"    // done\n" +
+		"}\n" +
+		"// end"
+	want := "// shapes\n" +
+		"struct: Point {\n" +
+		"    x: int;\n" +
+		"    y: int;\n" +
+		"}\n" +
+		"enum: Shape {\n" +
+		"    Circle(float);\n" +
+		"    Empty;\n" +
+		"}\n\n" +
+		"fun: first<T>(a: T[]): (T) {\n" +
+		"    return: a[0];\n" +
+		"}\n" +
+		"fun: area(s: Shape): (float) {\n" +
+		"    match: (s): {\n" +
+		"        Circle(r): {\n" +
+		"            return: 3.0 * r * r;\n" +
+		"        }\n" +
+		"        else: {}\n" +
+		"    }\n" +
+		"}\n" +
+		"fun: main() {\n" +
+		"    var p: Point = Point{x: 1, y: -2}; // a point\n" +
+		"    if: (p.x > 0 && !false): {\n" +
+		"        println(\"x: ${p.x + 1}\");\n" +
+		"    } else if: (true): {\n" +
+		"        // nothing\n" +
+		"    } else: {\n" +
+		"        p.y = - -p.y;\n" +
+		"    }\n" +
+		"    var xs: int[] = [1,\n" +
+		"        2,\n" +
+		"        3];\n" +
+		"    println(apply(xs, fun(n: int): (int) {\n" +
+		"        return: n * 2;\n" +
+		"    }));\n" +
+		"    // done\n" +
+		"}\n" +
+		"// end\n"
+
+	formatted, err := format.Source(src)
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, formatted)
+	}
+
+	_, err = format.Source("fun: main() {\n    println(1;\n}")
+	assert.EqualError(t, err, "3:1: unexpected `}`")
+	_, err = format.Source("fun: main() {\n    println(1);\n")
+	assert.EqualError(t, err, "1:13: `{` is never closed")
+}
+
+func Test53(t *testing.T) {
+	// test55 and test56 are programs that can't be split into tokens or have a bracket that isn't closed
+	broken := map[string]string{
+		"test55.kol": "10:12: `(` is never closed",
+		"test56.kol": "3:15: unrecognized token `#`",
+	}
+	files := 0
+	err := filepath.WalkDir("./testKolFiles", func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".kol") {
+			return err
+		}
+		files++
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		once, err := format.Source(string(src))
+		if want, ok := broken[filepath.Base(path)]; ok {
+			assert.EqualError(t, err, want, path)
+			return nil
+		}
+		if !assert.NoError(t, err, path) {
+			return nil
+		}
+		twice, err := format.Source(once)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, once, twice, path)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, files > len(broken))
+}
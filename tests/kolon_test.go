@@ -0,0 +1,278 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	kolon "github.com/KhushPatibandha/Kolon"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+)
+
+func Test48(t *testing.T) {
+	src := "enum: Shape {Circle(float); Empty;}" +
+		"fun: stats(a: int[], names: int[string]): (int, string, Shape) {" +
+		"var sum: int = 0; for: (var n: int in a): {sum += n;} return: (sum, names[sum], Shape.Circle(1.5));}" +
+		"fun: main() {var name: string = scanln(\"name? \"); println(\"hello \" + name);}"
+	program, diags := kolon.Compile(src)
+	if !assert.Nil(t, diags) {
+		return
+	}
+
+	for _, vm := range []bool{false, true} {
+		var out bytes.Buffer
+		results, err := program.Run(context.Background(), kolon.Options{
+			Stdout: &out,
+			Stdin:  strings.NewReader("kolon\n"),
+			VM:     vm,
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, results)
+		assert.Equal(t, "name? hello kolon\n", out.String())
+
+		results, err = program.Run(context.Background(), kolon.Options{
+			Entry: "stats",
+			Args:  []any{[]int{1, 2}, map[int64]string{3: "three", 4: "four"}},
+			VM:    vm,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []any{int64(3), "three", kolon.Variant{Enum: "Shape", Name: "Circle", Values: []any{1.5}}},
+			results)
+
+		_, err = program.Run(context.Background(), kolon.Options{
+			Entry: "stats",
+			Args:  []any{[]string{"1"}, map[int]string{}},
+			VM:    vm,
+		})
+		if assert.Error(t, err) {
+			assert.Equal(t, "argument 1 of `stats`: can't use a Go value of type `string` as `int`", err.Error())
+		}
+
+		_, err = program.Run(context.Background(), kolon.Options{
+			Entry: "stats",
+			Args:  []any{[]int{1}, map[int]string{}},
+			VM:    vm,
+		})
+		if assert.Error(t, err) {
+			assert.Equal(t, "1:168: key not found: 1", err.Error())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := program.Run(ctx, kolon.Options{})
+	var limitErr *kolon.LimitError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, evaluator.Stopped, limitErr.Kind)
+		assert.True(t, errors.Is(err, context.Canceled))
+	}
+
+	_, diags = kolon.Compile("fun: main() {\nvar x: int = \"one\";\nprintln(y);\n}")
+	if assert.Len(t, diags, 2) {
+		assert.Equal(t, 2, diags[0].Line)
+		assert.Equal(t, "3:9: variable `y` is undefined/not found", diags[1].Error())
+	}
+
+}
+
+func Test49(t *testing.T) {
+	funcs := []kolon.Func{
+		{
+			Name:     "sum",
+			Params:   []string{"string"},
+			Variadic: "int",
+			Returns:  []string{"string", "int"},
+			Fn: func(args []any) ([]any, error) {
+				var total int64
+				for _, n := range args[1:] {
+					total += n.(int64)
+				}
+				return []any{args[0].(string), total}, nil
+			},
+		},
+		{
+			Name:    "lookup",
+			Params:  []string{"int[string]", "int"},
+			Returns: []string{"string"},
+			Fn: func(args []any) ([]any, error) {
+				v, ok := args[0].(map[any]any)[args[1]]
+				if !ok {
+					return nil, errors.New("no such id")
+				}
+				return []any{v}, nil
+			},
+		},
+	}
+	src := "fun: main() {" +
+		"var label: string, var n: int = sum(\"total\", 1, 2, 3); println(label + \": \" + toString(n));" +
+		"var a: string, var b: int = sum(\"none\"); println(b);" +
+		"println(lookup({1: \"one\"}, 1)); println(lookup({1: \"one\"}, 2));}"
+	program, diags := kolon.Compile(src, funcs...)
+	if !assert.Nil(t, diags) {
+		return
+	}
+	for _, vm := range []bool{false, true} {
+		var out bytes.Buffer
+		_, err := program.Run(context.Background(), kolon.Options{Stdout: &out, VM: vm})
+		assert.Equal(t, "total: 6\n0\none\n", out.String())
+		if assert.Error(t, err) {
+			assert.Equal(t, "1:197: no such id", err.Error())
+		}
+	}
+
+	_, diags = kolon.Compile("fun: main() {\nsum(\"x\", 1, \"2\");\nsum();\n}", funcs...)
+	if assert.Len(t, diags, 2) {
+		assert.Equal(t, "2:1: type mismatch for argument at position 3 for function call `sum`, "+
+			"expected: `int`, got: `string`", diags[0].Error())
+		assert.Equal(t, "3:1: wrong number of arguments for `sum`, got: 0, want: 1 or more", diags[1].Error())
+	}
+
+	// the standard builtins can't be called without the funcs registered
+	_, diags = kolon.Compile("fun: main() {lookup({1: \"one\"}, 1);}")
+	assert.Len(t, diags, 1)
+
+	_, diags = kolon.Compile("", kolon.Func{Name: "len", Params: []string{"int"}, Fn: funcs[0].Fn})
+	assert.Equal(t, []kolon.Diagnostic{{Message: "func `len`: builtin function `len` already exists"}}, diags)
+	_, diags = kolon.Compile("", kolon.Func{Name: "f", Params: []string{"Point"}, Fn: funcs[0].Fn})
+	assert.Equal(t, []kolon.Diagnostic{{Message: "func `f`: type `Point`: unknown type `Point`, " +
+		"expected a datatype or the name of a struct or an enum"}}, diags)
+}
+
+func Test50(t *testing.T) {
+	src := "fun: spin(n: int): (int) {while: (true): {n++;} return: n;}" +
+		"fun: down(n: int): (int) {return: down(n + 1);}" +
+		"fun: grow(): (int[]) {var a: int[] = []; while: (true): {a = a + [1];} return: a;}" +
+		"fun: build(): (string) {var s: string = \"\"; while: (true): {s += \"ab\";} return: s;}" +
+		"fun: fill(): (int[int]) {var m: int[int] = {}; var i: int = 0; while: (true): {m[i] = i; i++;} return: m;}"
+	program, diags := kolon.Compile(src)
+	if !assert.Nil(t, diags) {
+		return
+	}
+	for _, vm := range []bool{false, true} {
+		var limitErr *kolon.LimitError
+		var runtimeErr *kolon.RuntimeError
+
+		_, err := program.Run(context.Background(), kolon.Options{
+			Entry: "spin", Args: []any{0}, VM: vm, Limits: kolon.Limits{MaxSteps: 1000},
+		})
+		if assert.True(t, errors.As(err, &limitErr)) {
+			assert.Equal(t, evaluator.StepLimit, limitErr.Kind)
+			assert.Equal(t, "step limit of 1000 exceeded", limitErr.Error())
+		}
+		if assert.True(t, errors.As(err, &runtimeErr)) && assert.Len(t, runtimeErr.Stack, 1) {
+			assert.Equal(t, "spin", runtimeErr.Stack[0].Function)
+		}
+
+		_, err = program.Run(context.Background(), kolon.Options{
+			Entry: "down", Args: []any{0}, VM: vm, Limits: kolon.Limits{MaxDepth: 3},
+		})
+		if assert.True(t, errors.As(err, &limitErr)) {
+			assert.Equal(t, evaluator.DepthLimit, limitErr.Kind)
+		}
+		if assert.True(t, errors.As(err, &runtimeErr)) {
+			down := kolon.Frame{Function: "down", Pos: lexer.Position{Line: 1, Column: 94}}
+			assert.Equal(t, []kolon.Frame{down, down, down}, runtimeErr.Stack)
+		}
+		assert.Equal(t, "1:94: call depth limit of 3 exceeded", err.Error())
+
+		// without a limit, the default one stops the recursion before it runs out of Go stack
+		_, err = program.Run(context.Background(), kolon.Options{Entry: "down", Args: []any{0}, VM: vm})
+		if assert.True(t, errors.As(err, &runtimeErr)) {
+			assert.Len(t, runtimeErr.Stack, evaluator.DefaultMaxDepth)
+		}
+
+		for _, entry := range []string{"grow", "build", "fill"} {
+			_, err = program.Run(context.Background(), kolon.Options{
+				Entry: entry, VM: vm, Limits: kolon.Limits{MaxAlloc: 100},
+			})
+			if assert.True(t, errors.As(err, &limitErr)) {
+				assert.Equal(t, evaluator.AllocLimit, limitErr.Kind)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		_, err = program.Run(ctx, kolon.Options{Entry: "spin", Args: []any{0}, VM: vm})
+		cancel()
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		if assert.True(t, errors.As(err, &limitErr)) {
+			assert.Equal(t, evaluator.Stopped, limitErr.Kind)
+		}
+	}
+}
+
+func Test51(t *testing.T) {
+	src := "fun: down(n: int): (int) {\nif: (n == 0): {\nvar a: int[] = [];\nreturn: a[n];\n}\nreturn: down(n - 1);\n}"
+	program, diags := kolon.CompileSource("prog.kol", src)
+	if !assert.Nil(t, diags) {
+		return
+	}
+	for _, vm := range []bool{false, true} {
+		var runtimeErr *kolon.RuntimeError
+		_, err := program.Run(context.Background(), kolon.Options{Entry: "down", Args: []any{2}, VM: vm})
+		assert.Equal(t, "prog.kol:4:9: index out of range, index: 0, max index: -1, min index: 0", err.Error())
+		if assert.True(t, errors.As(err, &runtimeErr)) {
+			assert.Equal(t, []kolon.Frame{
+				{Function: "down", File: "prog.kol", Pos: lexer.Position{Line: 6, Column: 9}},
+				{Function: "down", File: "prog.kol", Pos: lexer.Position{Line: 6, Column: 9}},
+				{Function: "down", File: "prog.kol", Pos: lexer.Position{Line: 4, Column: 9}},
+			}, runtimeErr.Stack)
+		}
+
+		_, err = program.Run(context.Background(), kolon.Options{Entry: "down", Args: []any{6}, VM: vm})
+		if assert.True(t, errors.As(err, &runtimeErr)) {
+			assert.Equal(t, "Traceback (most recent call last):\n"+
+				"    prog.kol:6:9 in `down`\n"+
+				"    prog.kol:6:9 in `down`\n"+
+				"    prog.kol:6:9 in `down`\n"+
+				"    ... the frame above 3 more times\n"+
+				"    prog.kol:4:9 in `down`", runtimeErr.Traceback())
+		}
+	}
+}
+
+// run with -race, programs are compiled and run on more than one goroutine at a time
+func Test54(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "Point" + strconv.Itoa(i)
+			src := "struct: " + name + " {x: int; tags: string[];}" +
+				"fun: make(n: int): (int[" + name + "]) {" +
+				"var m: int[" + name + "] = {}; m[n] = " + name + "{x: n, tags: [\"a\"]}; return: m;}" +
+				"fun: main() {println(make(" + strconv.Itoa(i) + "));}"
+			program, diags := kolon.Compile(src)
+			if diags != nil {
+				errs <- diags[0]
+				return
+			}
+			for _, vm := range []bool{false, true} {
+				var out bytes.Buffer
+				if _, err := program.Run(context.Background(), kolon.Options{Stdout: &out, VM: vm}); err != nil {
+					errs <- err
+					return
+				}
+				want := "{" + strconv.Itoa(i) + ": " + name + "{x: " + strconv.Itoa(i) + ", tags: [\"a\"]}}\n"
+				if out.String() != want {
+					errs <- errors.New("got: " + out.String() + ", want: " + want)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,252 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+)
+
+// ------------------------------------------------------------------------------------------------------------------
+// The regex driven lexer the scanner replaced, kept as the baseline for the benchmarks and to check that both
+// produce the same tokens
+// ------------------------------------------------------------------------------------------------------------------
+type regexLexer struct {
+	tokens   []lexer.Token
+	source   string
+	position int
+	line     int
+	column   int
+}
+
+type regexPattern struct {
+	regex *regexp.Regexp
+	kind  lexer.TokenKind
+}
+
+const skipToken lexer.TokenKind = -1
+
+var regexPatterns = []regexPattern{
+	{regexp.MustCompile(`\t+`), skipToken},
+	{regexp.MustCompile(`\s+`), skipToken},
+	{regexp.MustCompile(`\/\/.*`), skipToken},
+	{regexp.MustCompile(`else\s+if`), lexer.IDENTIFIER},
+	{regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*`), lexer.IDENTIFIER},
+	{regexp.MustCompile(`\d+\.\d+`), lexer.FLOAT},
+	{regexp.MustCompile(`\d+`), lexer.INT},
+	{regexp.MustCompile(`"[^"]*"`), lexer.STRING},
+	{regexp.MustCompile(`'[^']'`), lexer.CHAR},
+	{regexp.MustCompile(`\[`), lexer.OPEN_SQUARE_BRACKET},
+	{regexp.MustCompile(`\]`), lexer.CLOSE_SQUARE_BRACKET},
+	{regexp.MustCompile(`\{`), lexer.OPEN_CURLY_BRACKET},
+	{regexp.MustCompile(`\}`), lexer.CLOSE_CURLY_BRACKET},
+	{regexp.MustCompile(`\(`), lexer.OPEN_BRACKET},
+	{regexp.MustCompile(`\)`), lexer.CLOSE_BRACKET},
+	{regexp.MustCompile(`==`), lexer.DOUBLE_EQUAL},
+	{regexp.MustCompile(`!=`), lexer.NOT_EQUAL},
+	{regexp.MustCompile(`<=`), lexer.LESS_THAN_EQUAL},
+	{regexp.MustCompile(`>=`), lexer.GREATER_THAN_EQUAL},
+	{regexp.MustCompile(`<`), lexer.LESS_THAN},
+	{regexp.MustCompile(`>`), lexer.GREATER_THAN},
+	{regexp.MustCompile(`\+\+`), lexer.PLUS_PLUS},
+	{regexp.MustCompile(`\+=`), lexer.PLUS_EQUAL},
+	{regexp.MustCompile(`\+`), lexer.PLUS},
+	{regexp.MustCompile(`--`), lexer.MINUS_MINUS},
+	{regexp.MustCompile(`-=`), lexer.MINUS_EQUAL},
+	{regexp.MustCompile(`-`), lexer.DASH},
+	{regexp.MustCompile(`\*=`), lexer.STAR_EQUAL},
+	{regexp.MustCompile(`\*`), lexer.STAR},
+	{regexp.MustCompile(`/=`), lexer.SLASH_EQUAL},
+	{regexp.MustCompile(`/`), lexer.SLASH},
+	{regexp.MustCompile(`%=`), lexer.PERCENT_EQUAL},
+	{regexp.MustCompile(`%`), lexer.PERCENT},
+	{regexp.MustCompile(`&&`), lexer.AND_AND},
+	{regexp.MustCompile(`\|\|`), lexer.OR_OR},
+	{regexp.MustCompile(`&`), lexer.AND},
+	{regexp.MustCompile(`\|`), lexer.OR},
+	{regexp.MustCompile(`=`), lexer.EQUAL_ASSIGN},
+	{regexp.MustCompile(`!`), lexer.NOT},
+	{regexp.MustCompile(`:`), lexer.COLON},
+	{regexp.MustCompile(`;`), lexer.SEMI_COLON},
+	{regexp.MustCompile(`,`), lexer.COMMA},
+	{regexp.MustCompile(`\.`), lexer.DOT},
+}
+
+func regexTokenizer(source string) ([]lexer.Token, bool) {
+	lex := &regexLexer{source: source, line: 1, column: 1}
+	for lex.position < len(lex.source) {
+		matched := false
+		for _, pattern := range regexPatterns {
+			loc := pattern.regex.FindStringIndex(lex.source[lex.position:])
+			if loc == nil || loc[0] != 0 {
+				continue
+			}
+			value := lex.source[lex.position : lex.position+loc[1]]
+			switch pattern.kind {
+			case skipToken:
+				lex.advanceN(loc[1])
+			case lexer.IDENTIFIER:
+				lex.push(lexer.GetNewToken(lexer.LookupIdentifier(value), value), loc[1])
+			case lexer.INT:
+				_, err := strconv.ParseInt(value, 10, 64)
+				if _, uerr := strconv.ParseUint(value, 10, 64); err != nil && uerr != nil {
+					return nil, false
+				}
+				lex.push(lexer.GetNewToken(pattern.kind, value), loc[1])
+			case lexer.FLOAT:
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return nil, false
+				}
+				lex.push(lexer.GetNewToken(pattern.kind, value), loc[1])
+			default:
+				lex.push(lexer.GetNewToken(pattern.kind, value), loc[1])
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+	lex.push(lexer.GetNewToken(lexer.EOF, "EOF"), 0)
+	return lex.tokens, true
+}
+
+func (lex *regexLexer) advanceN(n int) {
+	for _, r := range lex.source[lex.position : lex.position+n] {
+		if r == '\n' {
+			lex.line++
+			lex.column = 1
+		} else {
+			lex.column++
+		}
+	}
+	lex.position += n
+}
+
+func (lex *regexLexer) push(token lexer.Token, n int) {
+	token.Start = lexer.Position{Line: lex.line, Column: lex.column}
+	lex.advanceN(n)
+	token.End = lexer.Position{Line: lex.line, Column: lex.column}
+	lex.tokens = append(lex.tokens, token)
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Sources
+// ------------------------------------------------------------------------------------------------------------------
+func corpus(tb testing.TB) []string {
+	paths, err := filepath.Glob("./testKolFiles/*.kol")
+	if err != nil || len(paths) == 0 {
+		tb.Fatalf("Failed to find test files: %v", err)
+	}
+	sources := []string{}
+	for _, path := range paths {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("Failed to read file: %s", err)
+		}
+		sources = append(sources, string(bytes))
+	}
+	return sources
+}
+
+// syntheticSource repeats a function using most of the syntax until the source is `lines` lines long
+func syntheticSource(lines int) string {
+	const fn = `// generated function number %d
+fun: generated%d(a: int, b: float[], c: string[int]): (int, bool) {
+    var total: int = a * 2 + 10 % 3;
+    const name: string = "function %d";
+    var letter: char = 'k';
+    for: (var i: int = 0; i < len(b); i++): {
+        if: (b[i] >= 1.5 && total != 0): {
+            total += toInt(b[i]);
+        } else if: (b[i] <= 0.25 || !(total == 1)): {
+            total -= 1;
+        } else: {
+            continue;
+        }
+    }
+    while: (total > 100): {
+        total /= 2;
+        break;
+    }
+    return: (total, c[total] == name);
+}
+`
+	var out strings.Builder
+	for i := 0; i*strings.Count(fn, "\n") < lines; i++ {
+		out.WriteString(strings.ReplaceAll(fn, "%d", strconv.Itoa(i)))
+	}
+	return out.String()
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Tests and Benchmarks
+// ------------------------------------------------------------------------------------------------------------------
+func Test34(t *testing.T) {
+	sources := append(corpus(t), syntheticSource(1_000),
+		"else   if else\nif elseif else ifx 'a''é' \"multi\nline\" 1.5 2. 18446744073709551615",
+		"var x: int = 1 @ 2;", "'ab'", "\"unterminated", "99999999999999999999",
+	)
+	for i, src := range sources {
+		// the regex lexer didn't know about escape sequences, raw strings and interpolation
+		if strings.ContainsAny(src, "\\`") || strings.Contains(src, "${") {
+			continue
+		}
+		expected, ok := regexTokenizer(src)
+		got, err := lexer.Tokenizer(src)
+		if ok != (err == nil) {
+			t.Fatalf("sources[%d] - expected ok=%v, got error=%v", i, ok, err)
+		}
+		// nor about the comments kept on the tokens
+		for j := range got {
+			got[j].Trivia = nil
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("sources[%d] - token streams differ", i)
+		}
+	}
+}
+
+func BenchmarkRegexLexerCorpus(b *testing.B) {
+	sources := corpus(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, src := range sources {
+			regexTokenizer(src)
+		}
+	}
+}
+
+func BenchmarkLexerCorpus(b *testing.B) {
+	sources := corpus(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, src := range sources {
+			lexer.Tokenizer(src)
+		}
+	}
+}
+
+func BenchmarkRegexLexerSynthetic(b *testing.B) {
+	synthetic := syntheticSource(100_000)
+	b.SetBytes(int64(len(synthetic)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		regexTokenizer(synthetic)
+	}
+}
+
+func BenchmarkLexerSynthetic(b *testing.B) {
+	synthetic := syntheticSource(100_000)
+	b.SetBytes(int64(len(synthetic)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lexer.Tokenizer(synthetic)
+	}
+}
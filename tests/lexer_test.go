@@ -89,7 +89,10 @@ func Test1(t *testing.T) {
 		{lexer.EOF, "EOF"},
 	}
 
-	tokens := lexer.Tokenizer(input)
+	tokens, err := lexer.Tokenizer(input)
+	if err != nil {
+		t.Fatalf("unexpected lexer error: %v", err)
+	}
 	for i, tt := range tests {
 		if tokens[i].Kind != tt.expectedType {
 			t.Fatalf("tests[%d] - token type wrong. expected=%q, got=%q", i, tt.expectedType, tokens[i].Kind)
@@ -191,7 +194,10 @@ func Test2(t *testing.T) {
 		{lexer.EOF, "EOF"},
 	}
 
-	tokens := lexer.Tokenizer(input)
+	tokens, err := lexer.Tokenizer(input)
+	if err != nil {
+		t.Fatalf("unexpected lexer error: %v", err)
+	}
 	for i, tt := range tests {
 		if tokens[i].Kind != tt.expectedType {
 			t.Fatalf("tests[%d] - token type wrong. expected=%q, got=%q", i, tt.expectedType, tokens[i].Kind)
@@ -441,7 +447,10 @@ func Test3(t *testing.T) {
 		{lexer.EOF, "EOF"},
 	}
 
-	tokens := lexer.Tokenizer(input)
+	tokens, err := lexer.Tokenizer(input)
+	if err != nil {
+		t.Fatalf("unexpected lexer error: %v", err)
+	}
 	for i, tt := range tests {
 		if tokens[i].Kind != tt.expectedType {
 			t.Fatalf("tests[%d] - token type wrong. expected=%q, got=%q", i, tt.expectedType, tokens[i].Kind)
@@ -451,3 +460,192 @@ func Test3(t *testing.T) {
 		}
 	}
 }
+
+func Test31(t *testing.T) {
+	input := "fun: main() {\n    var a: string = \"hi\";\n}"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedStart   lexer.Position
+		expectedEnd     lexer.Position
+	}{
+		{"fun", lexer.Position{Line: 1, Column: 1}, lexer.Position{Line: 1, Column: 4}},
+		{":", lexer.Position{Line: 1, Column: 4}, lexer.Position{Line: 1, Column: 5}},
+		{"main", lexer.Position{Line: 1, Column: 6}, lexer.Position{Line: 1, Column: 10}},
+		{"(", lexer.Position{Line: 1, Column: 10}, lexer.Position{Line: 1, Column: 11}},
+		{")", lexer.Position{Line: 1, Column: 11}, lexer.Position{Line: 1, Column: 12}},
+		{"{", lexer.Position{Line: 1, Column: 13}, lexer.Position{Line: 1, Column: 14}},
+		{"var", lexer.Position{Line: 2, Column: 5}, lexer.Position{Line: 2, Column: 8}},
+		{"a", lexer.Position{Line: 2, Column: 9}, lexer.Position{Line: 2, Column: 10}},
+		{":", lexer.Position{Line: 2, Column: 10}, lexer.Position{Line: 2, Column: 11}},
+		{"string", lexer.Position{Line: 2, Column: 12}, lexer.Position{Line: 2, Column: 18}},
+		{"=", lexer.Position{Line: 2, Column: 19}, lexer.Position{Line: 2, Column: 20}},
+		{"\"hi\"", lexer.Position{Line: 2, Column: 21}, lexer.Position{Line: 2, Column: 25}},
+		{";", lexer.Position{Line: 2, Column: 25}, lexer.Position{Line: 2, Column: 26}},
+		{"}", lexer.Position{Line: 3, Column: 1}, lexer.Position{Line: 3, Column: 2}},
+		{"EOF", lexer.Position{Line: 3, Column: 2}, lexer.Position{Line: 3, Column: 2}},
+	}
+
+	tokens, err := lexer.Tokenizer(input)
+	if err != nil {
+		t.Fatalf("unexpected lexer error: %v", err)
+	}
+	for i, tt := range tests {
+		if tokens[i].Value != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tokens[i].Value)
+		}
+		if tokens[i].Start != tt.expectedStart {
+			t.Fatalf("tests[%d] - start wrong. expected=%v, got=%v", i, tt.expectedStart, tokens[i].Start)
+		}
+		if tokens[i].End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - end wrong. expected=%v, got=%v", i, tt.expectedEnd, tokens[i].End)
+		}
+	}
+}
+
+func Test33(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedText string
+		expectedPos  lexer.Position
+		expectedMsg  string
+	}{
+		{"fun: main() {\n    var a: int = 1 $ 2;\n}", "$", lexer.Position{Line: 2, Column: 20}, "2:20: unrecognized token `$`"},
+		{"var a: int = 99999999999999999999999;", "99999999999999999999999", lexer.Position{Line: 1, Column: 14}, "1:14: invalid int literal `99999999999999999999999`, value out of range"},
+		{"println(\"é\");\n  ¬", "¬", lexer.Position{Line: 2, Column: 3}, "2:3: unrecognized token `¬`"},
+	}
+
+	for i, tt := range tests {
+		tokens, err := lexer.Tokenizer(tt.input)
+		if tokens != nil {
+			t.Fatalf("tests[%d] - expected no tokens, got=%v", i, tokens)
+		}
+		lexErr, ok := err.(*lexer.LexError)
+		if !ok {
+			t.Fatalf("tests[%d] - expected a *lexer.LexError, got=%T (%v)", i, err, err)
+		}
+		if lexErr.Text != tt.expectedText {
+			t.Fatalf("tests[%d] - text wrong. expected=%q, got=%q", i, tt.expectedText, lexErr.Text)
+		}
+		if lexErr.Pos != tt.expectedPos {
+			t.Fatalf("tests[%d] - position wrong. expected=%v, got=%v", i, tt.expectedPos, lexErr.Pos)
+		}
+		if lexErr.Error() != tt.expectedMsg {
+			t.Fatalf("tests[%d] - message wrong. expected=%q, got=%q", i, tt.expectedMsg, lexErr.Error())
+		}
+	}
+}
+
+func Test35(t *testing.T) {
+	input := "\"a\\tb\\\"c\\\\\" '\\n' '\\'' 'é' \"\\u{1F600}!\" `raw \\n\n\"x\"`"
+
+	tests := []struct {
+		expectedType  lexer.TokenKind
+		expectedRaw   string
+		expectedValue string
+	}{
+		{lexer.STRING, "\"a\\tb\\\"c\\\\\"", "a\tb\"c\\"},
+		{lexer.CHAR, "'\\n'", "\n"},
+		{lexer.CHAR, "'\\''", "'"},
+		{lexer.CHAR, "'é'", "é"},
+		{lexer.STRING, "\"\\u{1F600}!\"", "😀!"},
+		{lexer.STRING, "`raw \\n\n\"x\"`", "raw \\n\n\"x\""},
+	}
+
+	tokens, err := lexer.Tokenizer(input)
+	if err != nil {
+		t.Fatalf("unexpected lexer error: %v", err)
+	}
+	for i, tt := range tests {
+		if tokens[i].Kind != tt.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected=%q, got=%q", i, tt.expectedType, tokens[i].Kind)
+		}
+		if tokens[i].Value != tt.expectedRaw {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedRaw, tokens[i].Value)
+		}
+		value, err := lexer.Unquote(tokens[i].Value)
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected unquote error: %v", i, err)
+		}
+		if value != tt.expectedValue {
+			t.Fatalf("tests[%d] - value wrong. expected=%q, got=%q", i, tt.expectedValue, value)
+		}
+	}
+
+	errs := map[string]string{
+		"\"bad \\q escape\"":       "1:6: invalid escape sequence `\\q`",
+		"\"\\u{110000}\"":          "1:2: invalid escape sequence `\\u{110000}`",
+		"\"\\u{}\"":                "1:2: invalid escape sequence `\\u{}`",
+		"'ab'":                     "1:1: invalid char literal, expected a single character between single quotes (`'`)",
+		"''":                       "1:1: invalid char literal, expected a single character between single quotes (`'`)",
+		"println(\"unterminated);": "1:9: unterminated string literal",
+		"x = `unterminated\nraw;":  "1:5: unterminated raw string literal",
+	}
+	for src, expected := range errs {
+		_, err := lexer.Tokenizer(src)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("%q - expected error=%q, got=%v", src, expected, err)
+		}
+	}
+}
+
+func Test36(t *testing.T) {
+	input := "\"a ${x + {\"k\": 1}[\"k\"]} b ${\"in ${y}\"}!\" \"\\${z}\""
+
+	tests := []struct {
+		expectedType  lexer.TokenKind
+		expectedValue string
+	}{
+		{lexer.INTERP_START, "\"a ${"},
+		{lexer.IDENTIFIER, "x"},
+		{lexer.PLUS, "+"},
+		{lexer.OPEN_CURLY_BRACKET, "{"},
+		{lexer.STRING, "\"k\""},
+		{lexer.COLON, ":"},
+		{lexer.INT, "1"},
+		{lexer.CLOSE_CURLY_BRACKET, "}"},
+		{lexer.OPEN_SQUARE_BRACKET, "["},
+		{lexer.STRING, "\"k\""},
+		{lexer.CLOSE_SQUARE_BRACKET, "]"},
+		{lexer.INTERP_MIDDLE, "} b ${"},
+		{lexer.INTERP_START, "\"in ${"},
+		{lexer.IDENTIFIER, "y"},
+		{lexer.INTERP_END, "}\""},
+		{lexer.INTERP_END, "}!\""},
+		{lexer.STRING, "\"\\${z}\""},
+		{lexer.EOF, "EOF"},
+	}
+
+	tokens, err := lexer.Tokenizer(input)
+	if err != nil {
+		t.Fatalf("unexpected lexer error: %v", err)
+	}
+	for i, tt := range tests {
+		if tokens[i].Kind != tt.expectedType {
+			t.Fatalf("tests[%d] - token type wrong. expected=%q, got=%q", i, tt.expectedType, tokens[i].Kind)
+		}
+		if tokens[i].Value != tt.expectedValue {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedValue, tokens[i].Value)
+		}
+	}
+
+	parts := map[string]string{"\"a ${": "a ", "} b ${": " b ", "}!\"": "!", "\"\\${z}\"": "${z}"}
+	for raw, expected := range parts {
+		value, err := lexer.Unquote(raw)
+		if err != nil || value != expected {
+			t.Fatalf("%q - expected value=%q, got=%q (error=%v)", raw, expected, value, err)
+		}
+	}
+
+	errs := map[string]string{
+		"\"a ${x":     "1:4: unterminated string interpolation, expected a closing curly bracket (`}`)",
+		"\"a ${ {x} ": "1:4: unterminated string interpolation, expected a closing curly bracket (`}`)",
+		"\"a ${x} b":  "1:7: unterminated string literal",
+	}
+	for src, expected := range errs {
+		_, err := lexer.Tokenizer(src)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("%q - expected error=%q, got=%v", src, expected, err)
+		}
+	}
+}
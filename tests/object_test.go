@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// collidingKey has the same HashKey as every other collidingKey, so all of them end up in the same chain
+type collidingKey struct {
+	value int
+}
+
+func (c *collidingKey) Inspect() string         { return "collidingKey" }
+func (c *collidingKey) Type() object.ObjectType { return "COLLIDING_KEY" }
+func (c *collidingKey) HashKey() object.HashKey { return object.HashKey{Type: c.Type(), Value: 42} }
+func (c *collidingKey) KeyEquals(other object.Object) bool {
+	o, ok := other.(*collidingKey)
+	return ok && o.value == c.value
+}
+
+// distinctKeys reports if every key can be found with its own value, after setting all of them in a hashmap
+func distinctKeys(keys []object.Hashable) bool {
+	h := object.NewHashMap()
+	for i, k := range keys {
+		h.Set(k, &object.Integer{Value: int64(i)})
+	}
+	if h.Len() != len(keys) {
+		return false
+	}
+	for i, k := range keys {
+		pair, ok := h.Get(k)
+		if !ok || pair.Value.(*object.Integer).Value != int64(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func Test44(t *testing.T) {
+	floats := func(a, b float64) bool {
+		if a == b || math.IsNaN(a) && math.IsNaN(b) {
+			return true
+		}
+		return distinctKeys([]object.Hashable{&object.Float{Value: a}, &object.Float{Value: b}})
+	}
+	assert.NoError(t, quick.Check(floats, nil))
+
+	strs := func(a, b string) bool {
+		if a == b {
+			return true
+		}
+		return distinctKeys([]object.Hashable{&object.String{Value: a}, &object.String{Value: b}})
+	}
+	assert.NoError(t, quick.Check(strs, nil))
+
+	colliding := func(values []int) bool {
+		seen := map[int]bool{}
+		keys := []object.Hashable{}
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				keys = append(keys, &collidingKey{value: v})
+			}
+		}
+		if !distinctKeys(keys) {
+			return false
+		}
+
+		// deleting every other key leaves the rest in place, in order
+		h := object.NewHashMap()
+		for _, k := range keys {
+			h.Set(k, k)
+		}
+		for i := 0; i < len(keys); i += 2 {
+			if _, ok := h.Delete(keys[i]); !ok {
+				return false
+			}
+		}
+		pairs := h.Pairs()
+		if len(pairs) != len(keys)/2 {
+			return false
+		}
+		for i, k := range keys {
+			_, ok := h.Get(k)
+			if ok != (i%2 == 1) || ok && pairs[i/2].Key != k {
+				return false
+			}
+		}
+		return true
+	}
+	assert.NoError(t, quick.Check(colliding, nil))
+
+	h := object.NewHashMap()
+	h.Set(&object.Float{Value: 1.1}, &object.String{Value: "a"})
+	h.Set(&object.Float{Value: 1.9}, &object.String{Value: "b"})
+	h.Set(&object.Float{Value: math.Copysign(0, -1)}, &object.String{Value: "c"})
+	h.Set(&object.Float{Value: 0}, &object.String{Value: "d"})
+	h.Set(&object.Float{Value: math.NaN()}, &object.String{Value: "e"})
+	h.Set(&object.Float{Value: -math.NaN()}, &object.String{Value: "f"})
+	assert.Equal(t, 4, h.Len())
+	assert.Equal(t, "{1.100000: \"a\", 1.900000: \"b\", -0.000000: \"d\", NaN: \"f\"}", h.Inspect())
+}
@@ -232,7 +232,8 @@ func Test29(t *testing.T) {
 func helper(t *testing.T, input []map[string]bool, inTesting bool) {
 	for _, test := range input {
 		for key, val := range test {
-			tokens := lexer.Tokenizer(key)
+			tokens, err := lexer.Tokenizer(key)
+			assert.NoError(t, err)
 			parser := parser.New(tokens, inTesting)
 			program, err := parser.ParseProgram()
 			if val {
@@ -251,7 +252,8 @@ func helper(t *testing.T, input []map[string]bool, inTesting bool) {
 func helper1(t *testing.T, test []map[string]string, inTesting bool) {
 	for _, pair := range test {
 		for input, expected := range pair {
-			tokens := lexer.Tokenizer(input)
+			tokens, err := lexer.Tokenizer(input)
+			assert.NoError(t, err)
 			parser := parser.New(tokens, inTesting)
 			program, err := parser.ParseProgram()
 			if err != nil {
@@ -262,6 +264,20 @@ func helper1(t *testing.T, test []map[string]string, inTesting bool) {
 	}
 }
 
+// helperErr checks that each input fails to parse with its expected error
+func helperErr(t *testing.T, input map[string]string, inTesting bool) {
+	for src, expected := range input {
+		tokens, err := lexer.Tokenizer(src)
+		assert.NoError(t, err)
+		p := parser.New(tokens, inTesting)
+		_, err = p.ParseProgram()
+		if assert.Error(t, err, src) {
+			assert.Equal(t, expected, err.Error())
+		}
+		ktype.ResetTypePool()
+	}
+}
+
 func fileToString(t *testing.T, filePath string) string {
 	rawString, err := os.ReadFile(filePath)
 	if err != nil {
@@ -269,3 +285,190 @@ func fileToString(t *testing.T, filePath string) string {
 	}
 	return strings.TrimSuffix(string(rawString), "\n")
 }
+
+func Test32(t *testing.T) {
+	input := map[string]string{
+		"fun: main() {\n    var a: int = b;\n}":                 "2:18: variable `b` is undefined/not found",
+		"fun: main() {\n    var a: int = \"hi\";\n}":            "2:5: type mismatch in variable/constant declaration, expected: int, got: string",
+		"fun: main() {\n    var a: int = 1 + true;\n}":          "2:18: invalid `infix` operation with variable types on left and right, got: `int` and `bool`",
+		"fun: main() {\n    if: (1): {\n        println(1);\n}": "2:10: condition for `if` statement must always result in a boolean value, got: int",
+		"fun: main() {\n    println(1)\n}":                      "3:1: expected a semicolon (`;`) at the end of the statement, got: CLOSE_CURLY_BRACKET",
+	}
+	helperErr(t, input, false)
+}
+
+func Test37(t *testing.T) {
+	test := map[string]string{
+		"struct: Point {x: int; y: int;} var p: Point = Point{y: 2, x: 1}; p.x = p.y + 1;": "struct: Point {x: int;y: int;}var p: Point = Point{x: 1, y: 2};p.x = (p.y + 1);",
+
+		"struct: Point {x: int; y: int;} struct: Line {from: Point; tags: string[];} " +
+			"var l: Line = Line{from: Point{y: 3}, tags: []}; l.from.x += 1;": "struct: Point {x: int;y: int;}struct: Line {from: Point;tags: string[];}" +
+			"var l: Line = Line{from: Point{x: 0, y: 3}, tags: []};l.from.x += 1;",
+	}
+	helper1(t, []map[string]string{test}, true)
+
+	input := map[string]string{
+		"struct: P {x: int;}\nvar p: P = P{z: 1};":            "2:14: struct `P` has no field `z`",
+		"struct: P {x: int[];}\nvar p: P = P{};":              "2:12: missing field `x` in `P` literal, only `int`, `float`, `bool`, `string` and `char` fields can be left out",
+		"struct: P {x: int;}\nconst p: P = P{};\np.x = 1;":    "3:1: variable `p` is a constant, can't re-assign fields of a constant struct",
+		"struct: P {x: int;}\nvar p: P = P{};\nprintln(p.y);": "3:11: struct `P` has no field `y`",
+		"struct: P {x: int;}\nvar p: P = P{x: true};":         "2:12: type mismatch for field `x` in `P` literal, expected: `int`, got: `bool`",
+		"var x: Q = 1;": "1:8: unknown type `Q`, expected a datatype or the name of a struct or an enum",
+	}
+	helperErr(t, input, true)
+}
+
+func Test38(t *testing.T) {
+	test := map[string]string{
+		"enum: Shape {Circle(float); Empty;} var s: Shape = Shape.Circle(1.5); s = Shape.Empty;": "enum: Shape {Circle(float);Empty;}var s: Shape = Shape.Circle(1.5);s = Shape.Empty;",
+
+		"enum: Shape {Circle(float); Rect(float, float); Empty;} var s: Shape = Shape.Empty; " +
+			"match: (s): {Circle(r): {println(r);} Rect: {} else: {}}": "enum: Shape {Circle(float);Rect(float, float);Empty;}var s: Shape = Shape.Empty;" +
+			"match: (s): {Circle(r): {println(r);}Rect: {}else: {}}",
+	}
+	helper1(t, []map[string]string{test}, true)
+
+	input := map[string]string{
+		"enum: E {A; B(int);}\nvar e: E = E.C;":                            "2:14: enum `E` has no variant `C`",
+		"enum: E {A; B(int);}\nvar e: E = E.B(true);":                      "2:12: type mismatch for value 1 of variant `E.B`, expected: `int`, got: `bool`",
+		"enum: E {A; B(int);}\nvar e: E = E.A;\nmatch: (e): {A: {}}":       "3:1: `match` statement on enum `E` doesn't handle the variant(s): `B`. add an arm for each of them or an `else` arm",
+		"enum: E {A; B(int);}\nvar e: E = E.A;\nmatch: (e): {B(x, y): {}}": "3:14: variant `B` carries 1 value(s), got: 2 identifier(s)",
+		"enum: E {A; B(int);}\nvar e: E = E.A;\nmatch: (e): {A: {} A: {}}": "3:20: variant `A` is matched twice in `match` statement",
+		"var x: int = 1;\nmatch: (x): {}":                                  "2:9: value for `match` statement must be an enum, got: int",
+		"enum: E {A;}\nvar e: E = E.A;\nmatch: (e): {A: {} else: {}}":      "3:1: `else` arm in `match` statement is unreachable, all variants of enum `E` are already handled",
+	}
+	helperErr(t, input, true)
+}
+
+func Test39(t *testing.T) {
+	test := map[string]string{
+		"var f: fun(int): (int) = fun(x: int): (int) {return: x * 2;}; f(1);": "var f: fun(int): (int) = fun(x: int): (int) {return: (x * 2);};f(1);",
+
+		"fun: twice(f: fun(int): (int), x: int): (int) {return: f(f(x));} var g: fun(int, int): (int)[] = [];": "fun: twice(f: fun(int): (int), x: int): (int) {return: f(f(x));}var g: fun(int, int): (int)[] = [];",
+
+		"fun: adder(x: int): (fun(int): (int)) {return: fun(y: int): (int) {return: x + y;};} var n: int = adder(1)(2);": "fun: adder(x: int): (fun(int): (int)) {return: fun(y: int): (int) {return: (x + y);};}var n: int = adder(1)(2);",
+	}
+	helper1(t, []map[string]string{test}, true)
+
+	input := map[string]string{
+		"fun: f(x: int): (int) {return: x;}\nvar g: fun(string): (int) = f;": "2:1: type mismatch in variable/constant declaration, expected: fun(string): (int), got: fun(int): (int)",
+		"var x: int = 1;\nx(2);": "2:1: only functions can be called, `x` is of type: int",
+		"var f: fun(int) = len;": "1:19: builtin function `len` can't be used as a value, did you mean to call it as `len(...)`?",
+		"var f: fun(int);":       "1:1: function variable `f` must always be initialized while declaring",
+		"var f: fun(): (int) = fun(): (int) {return: 1;};\nvar b: bool = f == f;": "2:15: function can't be used with infix operations",
+	}
+	helperErr(t, input, true)
+}
+
+func Test40(t *testing.T) {
+	test := map[string]string{
+		"fun: first<T>(a: T[]): (T) {return: a[0];} var x: int = first([1, 2]);": "fun: first<T>(a: T[]): (T) {return: a[0];}var x: int = first([1, 2]);",
+
+		"fun: get<K, V>(m: K[V], k: K): (V) {return: m[k];} var s: string = get({1: \"a\"}, 1);": "fun: get<K, V>(m: K[V], k: K): (V) {return: m[k];}var s: string = get({1: \"a\"}, 1);",
+	}
+	helper1(t, []map[string]string{test}, true)
+
+	input := map[string]string{
+		"fun: first<T>(a: T[]): (T) {return: a[0];}\nvar x: int = first([\"a\"]);": "2:1: type mismatch in variable/constant declaration, expected: int, got: string",
+		"fun: first<T>(a: T[]): (T) {return: a[0];}\nvar x: int = first([]);":      "2:14: can't infer type parameter `T` for function call `first`, the arguments for it are empty arrays or hashmaps",
+		"fun: same<T>(a: T, b: T): (bool) {return: true;}\nsame(1, \"a\");":        "2:1: type mismatch for argument at position 2 for function call `same`, expected: `int`, got: `string`",
+		"fun: make<T>(): (T) {}": "1:6: type parameter `T` of function `make` must be used by one of its parameters, it's inferred from the arguments of each call",
+		"fun: f<T, T>(a: T) {}":  "1:11: type parameter `T` is declared twice",
+		"push([1], true);":       "1:1: type mismatch for argument at position 2 for function call `push`, expected: `int`, got: `bool`",
+		"toInt(true);":           "1:1: arguments for `toInt` not supported, got: (bool), want: `toInt(int)`, `toInt(float)`, `toInt(string)` or `toInt(char)`",
+		"pop();":                 "1:1: wrong number of arguments for `pop`, got: 0, want: 1 or 2",
+	}
+	helperErr(t, input, true)
+}
+
+func Test41(t *testing.T) {
+	src := "import: \"testKolFiles/modules/text.kol\";var s: string = text.wrap(\"a\");var p: geometry.Point = geometry.point(1, 2);"
+	tokens, err := lexer.Tokenizer("import: \"testKolFiles/modules/geometry.kol\";" + src)
+	assert.NoError(t, err)
+	p := parser.New(tokens, true)
+	program, err := p.ParseProgram()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "import: \"testKolFiles/modules/geometry.kol\";"+src, program.String())
+	}
+	ktype.ResetTypePool()
+
+	input := map[string]string{
+		"import: \"testKolFiles/modules/text.kol\";\ntext._paren(\"a\");":                                         "2:6: `_paren` is private to module `text`, names starting with an underscore (`_`) can't be used outside of their module",
+		"import: \"testKolFiles/modules/text.kol\";\nvar t: text.Point = text.wrap(\"a\");":                       "2:13: module `text` has no `Point`",
+		"import: \"testKolFiles/modules/text.kol\";\nstruct: text {}":                                             "2:9: can't declare struct `text`, a module with the same name is imported",
+		"import: \"testKolFiles/modules/text\";":                                                                  "1:9: imported file `testKolFiles/modules/text` must have .kol extension",
+		"fun: f() {import: \"testKolFiles/modules/text.kol\";}":                                                   "1:11: can't import a module inside a function",
+		"import: \"testKolFiles/modules/geometry.kol\";\nvar a: int = geometry.area(geometry.Shape.Circle(1.0));": "2:1: type mismatch in variable/constant declaration, expected: int, got: float",
+	}
+	helperErr(t, input, true)
+}
+
+func Test42(t *testing.T) {
+	src := "var a: int[] = [1, 2];a[0] = 3;a[1] += 2;a[0]++;var m: string[int[]] = {};m[\"k\"] = a;m[\"k\"][0] *= 2;"
+	tokens, err := lexer.Tokenizer(src)
+	assert.NoError(t, err)
+	p := parser.New(tokens, true)
+	program, err := p.ParseProgram()
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Replace(src, "a[0]++", "(a[0]++)", 1), program.String())
+	}
+	ktype.ResetTypePool()
+
+	input := map[string]string{
+		"const a: int[] = [1];\na[0] = 2;":              "2:1: variable `a` is a constant, can't re-assign elements of a constant array or hashmap",
+		"var s: string = \"a\";\ns[0] = 'b';":           "2:1: can't assign to a character of a string, strings can't be changed in place",
+		"var a: int[] = [1];\na[0] = 1.5;":              "2:1: type mismatch at the time of assignment, got: `int` on left and `float` on right",
+		"var m: string[int] = {};\nm[0] = 1;":           "2:1: hashmap index type must be of datatype `string`, got: int",
+		"const x: int = 1;\nx++;":                       "2:1: variable `x` is a constant, can't re-assign value to a constant variable",
+		"fun: f(): (int[]) {return: [1];}\nf()[0] = 2;": "2:1: can only assign to elements of an array or a hashmap held by a variable, got: f()",
+		"struct: P {x: int;}\nvar m: P[int] = {};":      "2:8: key in a hashmap can only be of `BaseType`, got: TypeStruct",
+		"var m: int[][int] = {};\nm[[1]] = 2;":          "1:8: key in a hashmap can only be of `BaseType`, got: TypeArray",
+		"var m: int[int][int] = {};":                    "1:8: key in a hashmap can only be of `BaseType`, got: TypeHashMap",
+	}
+	helperErr(t, input, true)
+}
+
+func Test43(t *testing.T) {
+	src := "fun: f(a: int[], m: string[float]) {for: (var n: int in a): {println(n);}" +
+		"for: (var i: int, var ch: char in \"ab\"): {break;}for: (var k: string, var v: float in m): {continue;}}"
+	tokens, err := lexer.Tokenizer(src)
+	assert.NoError(t, err)
+	p := parser.New(tokens, true)
+	program, err := p.ParseProgram()
+	if assert.NoError(t, err) {
+		assert.Equal(t, src, program.String())
+	}
+	ktype.ResetTypePool()
+
+	input := map[string]string{
+		"fun: f() {for: (const n: int in [1]): {}}":            "1:17: expected a `var` statement before `in` in `for-each` loop, got: CONST",
+		"fun: f() {for: (var n: int in [1]): {}\nprintln(n);}": "2:9: variable `n` is undefined/not found",
+		"fun: f() {for: (n in [1]): {}}":                       "1:17: expected a `var` statement before `in` in `for-each` loop, got: IDENTIFIER",
+		"fun: f() {for: (var n: int in [1]) {}}":               "1:36: expected a colon (`:`) after the closing bracket (`)`) in `for loop`, got: OPEN_CURLY_BRACKET",
+	}
+	helperErr(t, input, true)
+}
+
+func Test55(t *testing.T) {
+	// a name whose declaration failed is still declared, its uses don't add errors of their own
+	input := map[string]string{
+		"fun: main() {\n    var m: int = 1 +;\n    println(m);\n    m = 2;\n}":            "2:21: no prefix parse function for: SEMI_COLON",
+		"fun: main() {\n    var m: Foo = 1;\n    println(m + 1);\n    var n: int = m;\n}": "2:12: unknown type `Foo`, expected a datatype or the name of a struct or an enum",
+		"fun: main() {\n    var m: int = \"s\";\n    m += 1;\n}":                          "2:5: type mismatch in variable/constant declaration, expected: int, got: string",
+		"fun: first<T, T>(a: T[]): (T) {\n    return: a[0];\n}\n" +
+			"fun: main() {\n    var x: int = first([1]);\n    println(first([2]));\n}": "1:15: type parameter `T` is declared twice",
+		"var x: int = 1;\nfun: main() {\n    println(x);\n}": "1:1: can't declare a variable outside a function",
+		"fun: f(): (int, int) {\n    return: (1, 2);\n}\nfun: main() {\n    var a: int, var b: string = f();\n    println(a + 1);\n    b = \"s\";\n}": "5:5: type mismatch in variable/constant declaration, expected: string, got: int",
+
+		// the body of an `if` or a `while` whose condition failed is still checked
+		"fun: main() {\n    if: (1 +): {\n        println(1);\n    }\n}": "2:13: no prefix parse function for: CLOSE_BRACKET",
+		"fun: main() {\n    while: (q): {\n        break;\n    }\n}":     "2:13: variable `q` is undefined/not found",
+		"fun: main() {\n    if: (1): {\n        var y: int = \"s\";\n    }\n}": "2:10: condition for `if` statement must always result in a boolean value, got: int\n" +
+			"3:9: type mismatch in variable/constant declaration, expected: int, got: string",
+		"fun: main() {\n    if: (true): {\n    } else if: (1): {\n    } else if: (2 +): {\n        var y: int = \"s\";\n    } else: {\n        var z: int = true;\n    }\n}": "3:17: condition for `else if` statement must always result in a boolean value, got: int\n" +
+			"4:20: no prefix parse function for: CLOSE_BRACKET\n" +
+			"5:9: type mismatch in variable/constant declaration, expected: int, got: string\n" +
+			"7:9: type mismatch in variable/constant declaration, expected: int, got: bool",
+	}
+	helperErr(t, input, false)
+}
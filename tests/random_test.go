@@ -13,16 +13,16 @@ func TestRandom(t *testing.T) {
 	run(t, "./testKolFiles/fiboRec.kol", "55")
 	run(t, "./testKolFiles/fac.kol", "120")
 	run(t, "./testKolFiles/test.kol", "1\nhello!! 1\n2\nhello!! 1.1 hehe!! true\n1\n1.1\ntrue\nc")
-	run(t, "./testKolFiles/test1.kol", "Error parsing program: can't override a built-in function, function `len` already exists")
+	run(t, "./testKolFiles/test1.kol", "Error parsing program: ./testKolFiles/test1.kol:1:54: can't override a built-in function, function `len` already exists")
 	run(t, "./testKolFiles/test2.kol", "true")
 	run(t, "./testKolFiles/test3.kol", "true")
 	run(t, "./testKolFiles/test4.kol", "true\n[\"khush\", \"hehe\"]")
 	run(t, "./testKolFiles/test5.kol", "[1, 2, 3]\n4\n[5, 6, 7]\n[1, 2, 3, 10]\n[1, 2, 3]\n[2, 3]\n[2, 10, 3]\n[2, 3]")
 	run(t, "./testKolFiles/test6.kol", "3")
-	// run(t, "./testKolFiles/test7.kol", "{\"khush\": 1, \"heeh\": 2}\n{\"yo\": 101, \"hello\": 100}\n{\"hello\": 1, \"yo\": 101}\n{\"hello\": 1, \"yo\": 101, \"hehe\": 1}\n{\"yo\": 101, \"hehe\": 1}")
+	run(t, "./testKolFiles/test7.kol", "{\"khush\": 1, \"heeh\": 2}\n{\"hello\": 100, \"yo\": 101}\n{\"hello\": 1, \"yo\": 101}\n{\"hello\": 1, \"yo\": 101, \"hehe\": 1}\n{\"yo\": 101, \"hehe\": 1}")
 	run(t, "./testKolFiles/test8.kol", "[\"khush\", \"heeh\"]\n[\"hello\", \"yo\"]")
 	run(t, "./testKolFiles/test9.kol", "[\"khush\", \"heeh\"]\n[\"hello\", \"yo\"]\n10\ntrue")
-	// run(t, "./testKolFiles/test10.kol", "{\"heeh\": 2, \"khush\": 1}\n1")
+	run(t, "./testKolFiles/test10.kol", "{\"khush\": 1, \"heeh\": 2}\n1")
 	run(t, "./testKolFiles/test11.kol", "[0, 1, 2, 3, 4, 6, 7, 8, 9, 0, 1, 2, 3, 4, 6, 7, 8, 9, 0, 1, 2, 3, 4, 6, 7, 8, 9]")
 	run(t, "./testKolFiles/test12.kol", "310")
 	run(t, "./testKolFiles/test13.kol", "310")
@@ -33,13 +33,15 @@ func TestRandom(t *testing.T) {
 	run(t, "./testKolFiles/test18.kol", "-1")
 	run(t, "./testKolFiles/test19.kol", "0\n-2")
 	run(t, "./testKolFiles/test20.kol", "110")
-	run(t, "./testKolFiles/test21.kol", "Error parsing program: variable `a` is undefined/not found")
-	run(t, "./testKolFiles/test22.kol", "Error parsing program: function `callMe` must have a `return` statement at the end of all branches")
-	run(t, "./testKolFiles/test23.kol", "Error parsing program: everything must be inside a function")
-	run(t, "./testKolFiles/test24.kol", "Error parsing program: variable `b` is a constant, can't re-declare const variables")
+	run(t, "./testKolFiles/test21.kol", "Error parsing program: ./testKolFiles/test21.kol:3:13: variable `a` is undefined/not found\n"+
+		"Error parsing program: ./testKolFiles/test21.kol:4:13: variable `b` is undefined/not found")
+	run(t, "./testKolFiles/test22.kol", "Error parsing program: ./testKolFiles/test22.kol:1:1: function `callMe` must have a `return` statement at the end of all branches")
+	run(t, "./testKolFiles/test23.kol", "Error parsing program: ./testKolFiles/test23.kol:1:1: everything must be inside a function\n"+
+		"Error parsing program: ./testKolFiles/test23.kol:5:5: no prefix parse function for: ELSE")
+	run(t, "./testKolFiles/test24.kol", "Error parsing program: ./testKolFiles/test24.kol:3:5: variable `b` is a constant, can't re-declare const variables")
 	run(t, "./testKolFiles/test25.kol", "int\nfloat\nstring\nchar\nbool\nint[]\nstring[int]")
 	run(t, "./testKolFiles/test26.kol", "[2, 3, 4]\n[2, 4]\nhus\nhs")
-	run(t, "./testKolFiles/test27.kol", "Error parsing program: variable (`var`) and constant (`const`) declarations must be assigned a single value, got: 0. in case of call expression, it must return a single value")
+	run(t, "./testKolFiles/test27.kol", "Error parsing program: ./testKolFiles/test27.kol:8:5: variable (`var`) and constant (`const`) declarations must be assigned a single value, got: 0. in case of call expression, it must return a single value")
 	run(t, "./testKolFiles/test28.kol", "102\nhello\nhello\n123\n10")
 	run(t, "./testKolFiles/test29.kol", "{}\n{\"khush\": 1}")
 	run(t, "./testKolFiles/test30.kol", "hello!!\n10\ntrue\nHello\nw\n1.1\nhello!!")
@@ -50,8 +52,9 @@ func TestRandom(t *testing.T) {
 	run(t, "./testKolFiles/test35.kol", "0\n2\n4\n6\n8\n10\n12")
 	run(t, "./testKolFiles/test36.kol", "0\n1\n2\n3\n4\n5\n100")
 	run(t, "./testKolFiles/test37.kol", "10.0\n10.1111\nfloat")
-	run(t, "./testKolFiles/test38.kol", "11\n10\n11\nint\n1\n10\n11\nint\n65\n99\nint\nError evaluating program: Error converting string to int, can't convert: 10.1")
-	run(t, "./testKolFiles/test41.kol", "Error parsing program: `main` function must not take in any parameters and must not return anything, since it is the starting point of the program")
+	run(t, "./testKolFiles/test38.kol", "11\n10\n11\nint\n1\n10\n11\nint\n65\n99\nint\nError evaluating program: ./testKolFiles/test38.kol:1:322: Error converting string to int, can't convert: 10.1\n"+
+		"Traceback (most recent call last):\n    ./testKolFiles/test38.kol:1:322 in `main`")
+	run(t, "./testKolFiles/test41.kol", "Error parsing program: ./testKolFiles/test41.kol:1:1: `main` function must not take in any parameters and must not return anything, since it is the starting point of the program")
 	run(t, "./testKolFiles/test42.kol", "")
 	run(t, "./testKolFiles/test43.kol", "int[]\nint[]\nint[]\nint[int[string][]]")
 	run(t, "./testKolFiles/test44.kol", "[1, 2, 3]\n[1, 2, 3]\n[1, 2, 3, 4]\n[1, 2, 3, 4]\ntrue\ntrue\ntrue\nfalse\ntrue")
@@ -65,23 +68,113 @@ func TestRandom(t *testing.T) {
 	run(t, "./testKolFiles/test52.kol", "truetruefalsetrue101020truefalse24.1helloa1010trueHellow1.1")
 	run(t, "./testKolFiles/test53.kol", "4.0\n4.0\n3.0\n3.0\n3.0\n3.0\n-1.0\n-2.0")
 	run(t, "./testKolFiles/test54.kol", "1.0\n2.0\n2.0\n1.1116\n1.11155579001\n1.1")
+	run(t, "./testKolFiles/test55.kol", "Error parsing program: ./testKolFiles/test55.kol:2:5: type mismatch in variable/constant declaration, expected: int, got: string\n"+
+		"Error parsing program: ./testKolFiles/test55.kol:5:5: expected a semicolon (`;`) at the end of the statement, got: CLOSE_CURLY_BRACKET\n"+
+		"Error parsing program: ./testKolFiles/test55.kol:6:9: type mismatch in variable/constant declaration, expected: string, got: int\n"+
+		"Error parsing program: ./testKolFiles/test55.kol:10:14: expected an identifier or a close bracket (`)`) after the open bracket (`(`) for function parameters, got: OPEN_CURLY_BRACKET\n"+
+		"Error parsing program: ./testKolFiles/test55.kol:15:5: invalid `infix` operation with variable types on left and right, got: `int` and `bool`\n"+
+		"Error parsing program: ./testKolFiles/test55.kol:16:13: variable `undefinedThing` is undefined/not found\n"+
+		"Error parsing program: ./testKolFiles/test55.kol:17:11: type mismatch in variable/constant declaration, expected: int, got: string")
+	run(t, "./testKolFiles/test56.kol", "Error parsing program: ./testKolFiles/test56.kol:3:15: unrecognized token `#`")
+	run(t, "./testKolFiles/test57.kol", "tab:\there\nquote: \"kolon\" and backslash: \\\nline one\nline two\n"+
+		"snowman: \u2603, smile: \U0001F600\n'\nété\n3\nraw \\n \"string\"\nspanning lines\ntrue")
+	run(t, "./testKolFiles/test58.kol", "[\"a, b\", \"c\"]\n2\n5\né\nél\n233\n['x', '\\'']\n{\"k\\\"q\": 'z'}\n121.5truec\ntrue\nstring[char]")
+	run(t, "./testKolFiles/test59.kol", "user kolon has 3 items\n3.0 true c [1, 2, 3] 1\nnested: inner kolon!\n"+
+		"not interpolated: ${name}, $name, }\n5 then 5")
+	run(t, "./testKolFiles/test60.kol", "Error parsing program: ./testKolFiles/test60.kol:5:27: expression `nothing()` in the "+
+		"string interpolation must result in a single printable value, got: 0 values")
+	run(t, "./testKolFiles/test61.kol", "Point{x: 1, y: 2}\n3\nLine{from: Point{x: 1, y: 2}, to: Point{x: 0, y: 5}, name: \"\", tags: [\"a\"]}\n"+
+		"9 Point{x: 1, y: 3}\nPoint{x: 1, y: 3} Point{x: 100, y: 3} false true\n101\nLine Point{x: 1, y: 3}\n4\n1\nPoint{x: 1, y: 3}")
+	run(t, "./testKolFiles/test62.kol", "Error parsing program: ./testKolFiles/test62.kol:11:32: struct `Point` has no field `z`\n"+
+		"Error parsing program: ./testKolFiles/test62.kol:13:5: variable `q` is a constant, can't re-assign fields of a constant struct\n"+
+		"Error parsing program: ./testKolFiles/test62.kol:14:20: missing field `points` in `Shape` literal, only `int`, `float`, `bool`, `string` and `char` fields can be left out\n"+
+		"Error parsing program: ./testKolFiles/test62.kol:15:15: struct `Point` has no field `w`\n"+
+		"Error parsing program: ./testKolFiles/test62.kol:16:13: struct can't be used with infix operations, use `equals` to compare structs")
+	run(t, "./testKolFiles/test63.kol", "Shape.Circle(2.000000) has area 12.0\nShape.Rect(2.000000, 3.500000) has area 7.0\nShape.Empty has area 0.0\n"+
+		"idle\nbusy or done true\nJob{name: \"build\", state: State.Running}\nState\ntrue\nfalse\n1\n3")
+	run(t, "./testKolFiles/test64.kol", "Error parsing program: ./testKolFiles/test64.kol:8:5: `match` statement on enum `Light` doesn't handle the variant(s): `Yellow`. add an arm for each of them or an `else` arm\n"+
+		"Error parsing program: ./testKolFiles/test64.kol:18:1: function `name` must have a `return` statement at the end of all branches\n"+
+		"Error parsing program: ./testKolFiles/test64.kol:30:26: enum `Light` has no variant `Blue`\n"+
+		"Error parsing program: ./testKolFiles/test64.kol:31:20: type mismatch for value 1 of variant `Light.Green`, expected: `int`, got: `string`\n"+
+		"Error parsing program: ./testKolFiles/test64.kol:36:9: variant `Red` is matched twice in `match` statement")
+	run(t, "./testKolFiles/test65.kol", "[2, 4, 6]\n[11, 12, 13]\n3\n1\n3\n15\n14\n12\nclicked by me\n"+
+		"fun(int): (int)\nfun(int): (int)\n[\"start\", \"a\", \"b\"]")
+	run(t, "./testKolFiles/test66.kol", "Error parsing program: ./testKolFiles/test66.kol:7:5: type mismatch in variable/constant declaration, expected: fun(string): (int), got: fun(int): (int)\n"+
+		"Error parsing program: ./testKolFiles/test66.kol:8:5: type mismatch for argument at position 1 for function call `f`, expected: `int`, got: `string`\n"+
+		"Error parsing program: ./testKolFiles/test66.kol:9:5: function variable `h` must always be initialized while declaring\n"+
+		"Error parsing program: ./testKolFiles/test66.kol:10:23: builtin function `println` can't be used as a value, did you mean to call it as `println(...)`?\n"+
+		"Error parsing program: ./testKolFiles/test66.kol:12:5: only functions can be called, `x` is of type: int\n"+
+		"Error parsing program: ./testKolFiles/test66.kol:13:29: function literal must have a `return` statement at the end of all branches\n"+
+		"Error parsing program: ./testKolFiles/test66.kol:16:13: function can't be used with infix operations")
+	run(t, "./testKolFiles/test67.kol", "3\na\n2.5\nkol\n[3, 5]\nx1\n1\n-1\n['k', 'k', 'k']\n[[1], [1]]")
+	run(t, "./testKolFiles/test68.kol", "Error parsing program: ./testKolFiles/test68.kol:6:13: values of a generic type can't be used with infix operations, got: `T` and `T`\n"+
+		"Error parsing program: ./testKolFiles/test68.kol:9:6: type parameter `T` of function `make` must be used by one of its parameters, it's inferred from the arguments of each call\n"+
+		"Error parsing program: ./testKolFiles/test68.kol:14:5: type mismatch in variable/constant declaration, expected: int, got: string\n"+
+		"Error parsing program: ./testKolFiles/test68.kol:15:18: can't infer type parameter `T` for function call `first`, the arguments for it are empty arrays or hashmaps\n"+
+		"Error parsing program: ./testKolFiles/test68.kol:16:5: type mismatch for argument at position 2 for function call `push`, expected: `int`, got: `string`\n"+
+		"Error parsing program: ./testKolFiles/test68.kol:17:32: generic function `first` can't be used as a value, did you mean to call it as `first(...)`?\n"+
+		"Error parsing program: ./testKolFiles/test68.kol:18:5: arguments for `len` not supported, got: (bool), want: `len(T[])`, `len(K[V])` or `len(string)`")
+	t.Setenv("KOLON_PATH", "./testKolFiles/lib")
+	run(t, "./testKolFiles/test69.kol", "geometry.Point{x: 1, y: 2}\n5\n(1, 2)\n12.0\n9.0\na\n(3, 4)\ngeometry.Point\na-b-c\nhi!")
+	run(t, "./testKolFiles/test70.kol", "Error parsing program: ./testKolFiles/test70.kol:4:9: module `modules/missing.kol` not found, looked in: `testKolFiles/modules` or `testKolFiles/lib/modules`\n"+
+		"Error parsing program: ./testKolFiles/test70.kol:5:9: module `text` is imported twice\n"+
+		"Error parsing program: ./testKolFiles/test70.kol:8:26: `_paren` is private to module `text`, names starting with an underscore (`_`) can't be used outside of their module\n"+
+		"Error parsing program: ./testKolFiles/test70.kol:9:26: module `text` has no `shout`\n"+
+		"Error parsing program: ./testKolFiles/test70.kol:10:18: module `text` can't be used as a value, use one of its names as `text.name`\n"+
+		"Error parsing program: ./testKolFiles/test70.kol:12:5: type mismatch in variable/constant declaration, expected: int, got: string\n"+
+		"Error parsing program: ./testKolFiles/test70.kol:15:1: `import` statements must come before everything else in the file\n"+
+		"Error parsing program: testKolFiles/modules/broken.kol:2:5: type mismatch in variable/constant declaration, expected: string, got: int\n"+
+		"Error parsing program: testKolFiles/modules/cycleB.kol:1:9: import cycle: testKolFiles/modules/cycleA.kol -> testKolFiles/modules/cycleB.kol -> testKolFiles/modules/cycleA.kol")
+	run(t, "./testKolFiles/test71.kol", "[10, 7, 4]\n11\n6\n2\n[[1, 0], [30, 4]]\nyz\nPoint{x: 5, y: 3}\n[\"a\", \"c\"]\nnext\n[10, 107, 4]\n2.5\n"+
+		"Error evaluating program: ./testKolFiles/test71.kol:59:5: index out of range, index: 5, max index: 2, min index: 0\n"+
+		"Traceback (most recent call last):\n    ./testKolFiles/test71.kol:59:5 in `main`")
+	run(t, "./testKolFiles/test72.kol", "Error parsing program: ./testKolFiles/test72.kol:3:5: variable `a` is a constant, can't re-assign elements of a constant array or hashmap\n"+
+		"Error parsing program: ./testKolFiles/test72.kol:5:5: variable `m` is a constant, can't re-assign elements of a constant array or hashmap\n"+
+		"Error parsing program: ./testKolFiles/test72.kol:7:5: can't assign to a character of a string, strings can't be changed in place\n"+
+		"Error parsing program: ./testKolFiles/test72.kol:9:5: type mismatch at the time of assignment, got: `int` on left and `string` on right\n"+
+		"Error parsing program: ./testKolFiles/test72.kol:11:5: type mismatch at the time of assignment, got: `string` on left and `int` on right\n"+
+		"Error parsing program: ./testKolFiles/test72.kol:12:5: can only use `+`, `==`, `!=` infix operators with 2 `string`, got: -")
+	run(t, "./testKolFiles/test73.kol", "10\n2\n0 h\n1 é\n4 o\nkhush\nkhush is 22\n140\n[100, 2, 3, 10, 20, 30]\n"+
+		"[Point{x: -1, y: 2}, Point{x: -3, y: 4}]\n123\n123")
+	run(t, "./testKolFiles/test74.kol", "Error parsing program: ./testKolFiles/test74.kol:2:28: type mismatch in `for-each` loop variable `n`, expected: int, got: string\n"+
+		"Error parsing program: ./testKolFiles/test74.kol:3:41: type mismatch in `for-each` loop variable `ch`, expected: char, got: string\n"+
+		"Error parsing program: ./testKolFiles/test74.kol:4:37: type mismatch in `for-each` loop variable `k`, expected: string, got: int\n"+
+		"Error parsing program: ./testKolFiles/test74.kol:5:25: can only loop over an array, a hashmap or a string in `for-each` loop, got: int\n"+
+		"Error parsing program: ./testKolFiles/test74.kol:6:33: `for-each` loop can declare at most 2 variables, the index (or key) and the element (or value)\n"+
+		"Error parsing program: ./testKolFiles/test74.kol:7:22: expected a semicolon (`;`) at the end of the statement after variable declaration, got: IDENTIFIER\n"+
+		"Error parsing program: ./testKolFiles/test74.kol:9:9: type mismatch at the time of assignment, got: `int` on left and `string` on right")
+	run(t, "./testKolFiles/test75.kol", "{\"a\": 10, \"b\": 2, \"d\": 4, \"c\": 30}\n[\"a\", \"b\", \"d\", \"c\"]\n[10, 2, 4, 30]\n"+
+		"a=10 b=2 d=4 c=30 \n{98: 98, 99: 99, 0: 0}\n3\ntrue\nfalse\n{\"a\": 10, \"b\": 2, \"d\": 4, \"c\": 30}")
+	run(t, "./testKolFiles/test76.kol", "{1.100000: \"a\", 1.900000: \"b\", 2.500000: \"c\", -0.000000: \"still zero\"}\nb\n4\nfalse")
+	run(t, "./testKolFiles/test77.kol", "b\n"+
+		"Error evaluating program: testKolFiles/modules/pick.kol:2:13: index out of range, index: 1, max index: 0, min index: 0\n"+
+		"Traceback (most recent call last):\n"+
+		"    ./testKolFiles/test77.kol:9:13 in `main`\n"+
+		"    ./testKolFiles/test77.kol:4:13 in `second`\n"+
+		"    testKolFiles/modules/pick.kol:9:13 in `at`\n"+
+		"    testKolFiles/modules/pick.kol:7:17 in `<function literal>`\n"+
+		"    testKolFiles/modules/pick.kol:2:13 in `_get`")
 }
 
 func run(t *testing.T, filePath string, expectedOutput string) {
-	cmd := exec.Command("../kolon", "run:", filePath)
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
+	// the vm has to give the same output as the evaluator, errors included. its flag can go on either side of the file
+	for _, args := range [][]string{{"run:", filePath}, {"run:", filePath, "--vm"}, {"run:", "--vm", filePath}} {
+		cmd := exec.Command("../kolon", args...)
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
 
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("Error running command: %v\n", err)
-		return
-	}
+		// errors in the kolon program make it exit with a non-zero code, their output still has to be checked
+		err := cmd.Run()
+		if _, ok := err.(*exec.ExitError); err != nil && !ok {
+			fmt.Printf("Error running command: %v\n", err)
+			return
+		}
 
-	output := stdout.String()
-	output = strings.TrimSpace(output)
-	fmt.Println(output)
-	if output != expectedOutput {
-		t.Errorf("unexpected output for %s: got %q, want %q", filePath, output, expectedOutput)
+		output := stdout.String()
+		output = strings.TrimSpace(output)
+		fmt.Println(output)
+		if output != expectedOutput {
+			t.Errorf("unexpected output for %s: got %q, want %q", strings.Join(args, " "), output, expectedOutput)
+		}
 	}
 }
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/repl"
+)
+
+func Test30(t *testing.T) {
+	input := `var x: int = 10;
+x + 5
+fun: add(a: int, b: int): (int) {
+    return: a + b;
+}
+add(x, 2);
+fun: pair(): (int, string) { return: (1, "a"); }
+pair()
+y
+x = x * 2;
+x
+var n: int = 0;
+fun: main() { n = n + 1; }
+n
+main();
+n
+`
+	expected := []string{
+		"15 (int)",
+		"12 (int)",
+		"(1, \"a\") (int, string)",
+		"Error parsing input: 1:1: variable `y` is undefined/not found",
+		"20 (int)",
+		"0 (int)",
+		"1 (int)",
+	}
+
+	var out bytes.Buffer
+	repl.Start(strings.NewReader(input), &out)
+	ktype.ResetTypePool()
+
+	var got []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		line = strings.TrimLeft(line, repl.PROMPT+repl.CONTINUE_PROMPT)
+		if line != "" {
+			got = append(got, line)
+		}
+	}
+	assert.Equal(t, expected, got)
+}
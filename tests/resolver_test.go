@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/compiler"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/evaluator"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/object"
+	"github.com/KhushPatibandha/Kolon/src/parser"
+)
+
+func Test46(t *testing.T) {
+	src := "var g: int = 1;" +
+		"fun: f(a: int, b: int): (int) {var c: int = a; if: (true): {var d: int = b; c = d + g;} return: c;}"
+	tokens, err := lexer.Tokenizer(src)
+	assert.NoError(t, err)
+	program, err := parser.New(tokens, true).ParseProgram()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, ast.Global, program.Statements[0].(*ast.VarAndConst).Name.Depth)
+
+	fn := program.Statements[1].(*ast.Function)
+	assert.Equal(t, 1, fn.Parameters[1].ParameterName.Index)
+	c := fn.Body.Statements[0].(*ast.VarAndConst)
+	assert.Equal(t, []int{0, 2}, []int{c.Name.Depth, c.Name.Index})
+	assert.Equal(t, []int{0, 0}, []int{c.Value.(*ast.Identifier).Depth, c.Value.(*ast.Identifier).Index})
+
+	body := fn.Body.Statements[1].(*ast.If).Body.Statements
+	d := body[0].(*ast.VarAndConst)
+	assert.Equal(t, []int{0, 0}, []int{d.Name.Depth, d.Name.Index})
+	assert.Equal(t, []int{1, 1}, []int{d.Value.(*ast.Identifier).Depth, d.Value.(*ast.Identifier).Index})
+
+	assign := body[1].(*ast.ExpressionStatement).Expression.(*ast.Assignment)
+	assert.Equal(t, []int{1, 2}, []int{assign.Left.(*ast.Identifier).Depth, assign.Left.(*ast.Identifier).Index})
+	sum := assign.Right.(*ast.Infix)
+	assert.Equal(t, 0, sum.Left.(*ast.Identifier).Depth)
+	assert.Equal(t, ast.Global, sum.Right.(*ast.Identifier).Depth)
+	ktype.ResetTypePool()
+}
+
+func Test56(t *testing.T) {
+	src := "fun: f(a: int, b: int): (int) {var c: int = b; c = a; return: c;}"
+	tokens, err := lexer.Tokenizer(src)
+	assert.NoError(t, err)
+	program, err := parser.New(tokens, true).ParseProgram()
+	ktype.ResetTypePool()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// an identifier the resolver missed is an error, not a read of slot 0 (which holds `a`)
+	fn := program.Statements[0].(*ast.Function)
+	b := fn.Body.Statements[0].(*ast.VarAndConst).Value.(*ast.Identifier)
+	b.Depth, b.Index = 0, ast.Unresolved
+
+	e := evaluator.New(true)
+	_, err = e.Evaluate(program)
+	assert.NoError(t, err)
+	_, err = e.Call("f", []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "1:45: internal error: identifier `b` was never resolved")
+	}
+
+	_, err = compiler.New().Compile(program)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "internal error: identifier `b` was never resolved")
+	}
+
+	assign := fn.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.Assignment)
+	assign.Left.(*ast.Identifier).Index = ast.Unresolved
+	b.Index = 1
+	_, err = e.Call("f", []object.Object{&object.Integer{Value: 1}, &object.Integer{Value: 2}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "internal error: identifier `c` was never resolved")
+	}
+}
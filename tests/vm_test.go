@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KhushPatibandha/Kolon/src/interpreter/code"
+	"github.com/KhushPatibandha/Kolon/src/interpreter/compiler"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/lexer"
+	"github.com/KhushPatibandha/Kolon/src/parser"
+)
+
+func Test45(t *testing.T) {
+	ins := code.Make(code.OpClosure, 65534, 3, 255)
+	def, err := code.Lookup(code.Opcode(ins[0]))
+	if assert.NoError(t, err) {
+		operands, read := code.ReadOperands(def, ins[1:])
+		assert.Equal(t, []int{65534, 3, 255}, operands)
+		assert.Equal(t, 5, read)
+	}
+
+	src := "fun: add(a: int, b: int): (int) {return: a + b;}fun: main() {var x: int = add(1, 2);}"
+	tokens, err := lexer.Tokenizer(src)
+	assert.NoError(t, err)
+	program, err := parser.New(tokens, true).ParseProgram()
+	assert.NoError(t, err)
+	bytecode, err := compiler.New().Compile(program)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, bytecode.Main)
+		assert.Equal(t, "0000 OpGetLocal 0\n0003 OpGetLocal 1\n0006 OpAdd\n0007 OpReturnValue\n0008 OpReturn\n",
+			bytecode.Functions[0].Instructions.String())
+		assert.Equal(t, "0000 OpConstant 0\n0003 OpConstant 1\n0006 OpCallFunction 0 2\n0010 OpSetLocal 0\n0013 OpReturn\n",
+			bytecode.Functions[1].Instructions.String())
+	}
+	ktype.ResetTypePool()
+
+	tokens, err = lexer.Tokenizer("var x: int = 1;")
+	assert.NoError(t, err)
+	program, err = parser.New(tokens, true).ParseProgram()
+	assert.NoError(t, err)
+	_, err = compiler.New().Compile(program)
+	if assert.Error(t, err) {
+		assert.Equal(t, "1:1: the vm can only run declarations outside of a function", err.Error())
+	}
+	ktype.ResetTypePool()
+}
+
+func Test58(t *testing.T) {
+	// operands too large for their instruction are errors, not values cut to fit
+	nested := []string{}
+	for i := 0; i < 70; i++ {
+		row := []string{}
+		for j := 0; j < 1000; j++ {
+			row = append(row, strconv.Itoa(i*1000+j))
+		}
+		nested = append(nested, "["+strings.Join(row, ", ")+"]")
+	}
+	wide := make([]string, 70000)
+	for i := range wide {
+		wide[i] = "1"
+	}
+	input := map[string]string{
+		"var a: int[][] = [" + strings.Join(nested, ", ") + "];":   "1:447805: operand 65536 of OpConstant is too large for the vm, at most 65535 fits",
+		"var s: string = \"" + strings.Repeat("${1}", 300) + "\";": "1:30: operand 300 of OpInterpolate is too large for the vm, at most 255 fits",
+		"var a: int[] = [" + strings.Join(wide, ", ") + "];":       "1:29: operand 70000 of OpArray is too large for the vm, at most 65535 fits",
+	}
+	for src, expected := range input {
+		tokens, err := lexer.Tokenizer("fun: main() {" + src + "}")
+		assert.NoError(t, err)
+		program, err := parser.New(tokens, true).ParseProgram()
+		ktype.ResetTypePool()
+		if !assert.NoError(t, err) {
+			continue
+		}
+		_, err = compiler.New().Compile(program)
+		if assert.Error(t, err) {
+			assert.Equal(t, expected, err.Error())
+		}
+	}
+}
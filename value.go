@@ -0,0 +1,218 @@
+package kolon
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/KhushPatibandha/Kolon/src/ast"
+	ktype "github.com/KhushPatibandha/Kolon/src/kType"
+	"github.com/KhushPatibandha/Kolon/src/object"
+)
+
+// Variant is a value of an enum given back to Go, eg: `Shape.Circle(1.5)` is {"Shape", "Circle", []any{1.5}}
+type Variant struct {
+	Enum   string
+	Name   string
+	Values []any
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Go to Kolon: the arguments for the entry function, each one has to fit the type of its parameter
+// ------------------------------------------------------------------------------------------------------------------
+func toArgs(fn *ast.Function, args []any) ([]object.Object, error) {
+	if len(args) != len(fn.Parameters) {
+		return nil, fmt.Errorf("function `%s` takes %d argument(s), got: %d", fn.Name.Value, len(fn.Parameters), len(args))
+	}
+	objs := make([]object.Object, len(args))
+	for i, arg := range args {
+		obj, err := toObject(reflect.ValueOf(arg), fn.Parameters[i].ParameterType)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d of `%s`: %w", i+1, fn.Name.Value, err)
+		}
+		objs[i] = obj
+	}
+	return objs, nil
+}
+
+func toObject(v reflect.Value, t *ktype.Type) (object.Object, error) {
+	mismatch := fmt.Errorf("can't use a Go value of type `%s` as `%s`", typeName(v), t.String())
+	if !v.IsValid() {
+		return nil, mismatch
+	}
+	if v.Kind() == reflect.Interface {
+		return toObject(v.Elem(), t)
+	}
+
+	switch t.Kind {
+	case ktype.TypeBase:
+		switch t.Name {
+		case "int":
+			if v.CanInt() {
+				return &object.Integer{Value: v.Int()}, nil
+			}
+			if v.CanUint() {
+				return &object.Integer{Value: int64(v.Uint())}, nil
+			}
+		case "float":
+			if v.CanFloat() {
+				return &object.Float{Value: v.Float()}, nil
+			}
+		case "bool":
+			if v.Kind() == reflect.Bool {
+				return object.NativeBool(v.Bool()), nil
+			}
+		case "string":
+			if v.Kind() == reflect.String {
+				return &object.String{Value: v.String()}, nil
+			}
+		case "char":
+			if v.Kind() == reflect.Int32 {
+				return &object.Char{Value: string(rune(v.Int()))}, nil
+			}
+			if v.Kind() == reflect.String && utf8.RuneCountInString(v.String()) == 1 {
+				return &object.Char{Value: v.String()}, nil
+			}
+		}
+	case ktype.TypeArray:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			elements := make([]object.Object, v.Len())
+			for i := range elements {
+				ele, err := toObject(v.Index(i), t.ElementType)
+				if err != nil {
+					return nil, err
+				}
+				elements[i] = ele
+			}
+			return &object.Array{Elements: elements}, nil
+		}
+	case ktype.TypeHashMap:
+		if v.Kind() == reflect.Map {
+			return toHashMap(v, t)
+		}
+	}
+	return nil, mismatch
+}
+
+// toHashMap adds the keys of the Go map v in sorted order, Go doesn't keep the order they were added in
+func toHashMap(v reflect.Value, t *ktype.Type) (object.Object, error) {
+	h := object.NewHashMap()
+	keys := make([]object.Object, 0, v.Len())
+	values := map[object.Object]reflect.Value{}
+	for _, k := range v.MapKeys() {
+		key, err := toObject(k, t.KeyType)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values[key] = v.MapIndex(k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	for _, key := range keys {
+		value, err := toObject(values[key], t.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		h.Set(key.(object.Hashable), value)
+	}
+	return h, nil
+}
+
+func less(a, b object.Object) bool {
+	switch a := a.(type) {
+	case *object.Integer:
+		return a.Value < b.(*object.Integer).Value
+	case *object.Float:
+		return a.Value < b.(*object.Float).Value
+	case *object.Bool:
+		return !a.Value && b.(*object.Bool).Value
+	}
+	return a.Inspect() < b.Inspect()
+}
+
+func typeName(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
+
+// ------------------------------------------------------------------------------------------------------------------
+// Kolon to Go: what the entry function returned, n values are returned as an array when n is more than one
+// ------------------------------------------------------------------------------------------------------------------
+func results(r object.Object, n int) ([]any, error) {
+	var objs []object.Object
+	switch n {
+	case 0:
+		return nil, nil
+	case 1:
+		objs = []object.Object{r}
+	default:
+		objs = r.(*object.Array).Elements
+	}
+	return toGoList(objs)
+}
+
+func toGo(obj object.Object) (any, error) {
+	switch obj := obj.(type) {
+	case nil:
+		return nil, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.Bool:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Char:
+		r, _ := utf8.DecodeRuneInString(obj.Value)
+		return r, nil
+	case *object.Array:
+		return toGoList(obj.Elements)
+	case *object.HashMap:
+		m := make(map[any]any, obj.Len())
+		for _, pair := range obj.Pairs() {
+			k, err := toGo(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+			v, err := toGo(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case *object.Struct:
+		m := make(map[string]any, len(obj.Fields))
+		for i, field := range obj.Fields {
+			v, err := toGo(obj.Values[i])
+			if err != nil {
+				return nil, err
+			}
+			m[field] = v
+		}
+		return m, nil
+	case *object.EnumValue:
+		values, err := toGoList(obj.Values)
+		if err != nil {
+			return nil, err
+		}
+		return Variant{Enum: obj.Enum, Name: obj.Variant, Values: values}, nil
+	}
+	return nil, fmt.Errorf("a value of type `%s` can't be given back to Go", obj.Inspect())
+}
+
+func toGoList(objs []object.Object) ([]any, error) {
+	out := make([]any, len(objs))
+	for i, obj := range objs {
+		v, err := toGo(obj)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}